@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
@@ -20,3 +21,33 @@ func handleSendError(err error, client plugin.Plugin_WriteClient, msgType string
 	}
 	return fmt.Errorf("failed to send write request (%v): %w", msgType, err)
 }
+
+// ExitCodeSourcesFailed is the exit code of a sync run with --continue-on-error in which some of the sources failed
+const ExitCodeSourcesFailed = 2
+
+// ExitCode returns the exit code the CLI should exit with for the given command error
+func ExitCode(err error) int {
+	var e interface{ ExitCode() int }
+	if errors.As(err, &e) {
+		return e.ExitCode()
+	}
+	return 1
+}
+
+type sourcesFailedError struct {
+	failed int
+	total  int
+	err    error
+}
+
+func (e *sourcesFailedError) Error() string {
+	return fmt.Sprintf("%d of %d sources failed to sync: %v", e.failed, e.total, e.err)
+}
+
+func (e *sourcesFailedError) Unwrap() error {
+	return e.err
+}
+
+func (*sourcesFailedError) ExitCode() int {
+	return ExitCodeSourcesFailed
+}
@@ -150,6 +150,9 @@ func migrate(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf("failed to migrate v3 source %s: %w", cl.Name(), err)
 			}
 		case 2:
+			if err := validateNoTransformations(source.Name, destinationForSourceSpec); err != nil {
+				return err
+			}
 			destinationsVersions := make([][]int, 0, len(destinationClientsForSource))
 			for _, destination := range destinationClientsForSource {
 				versions, err := destination.Versions(ctx)
@@ -165,6 +168,9 @@ func migrate(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf("failed to migrate source %v@%v: %w", source.Name, source.Version, err)
 			}
 		case 1:
+			if err := validateNoTransformations(source.Name, destinationForSourceSpec); err != nil {
+				return err
+			}
 			if err := migrateConnectionV1(ctx, cl, destinationClientsForSource, *source, destinationForSourceSpec); err != nil {
 				return fmt.Errorf("failed to migrate source %v@%v: %w", source.Name, source.Version, err)
 			}
@@ -43,6 +43,9 @@ func migrateConnectionV3(ctx context.Context, sourceClient *managedplugin.Client
 			opts = append(opts, transformer.WithRemovePKs())
 			opts = append(opts, transformer.WithCQIDPrimaryKey())
 		}
+		if len(destinationSpecs[i].Transformations) > 0 {
+			opts = append(opts, transformer.WithTransformations(destinationSpecs[i].Transformations...))
+		}
 		destinationTransformers[i] = transformer.NewRecordTransformer(opts...)
 	}
 
@@ -58,17 +61,30 @@ func migrateConnectionV3(ctx context.Context, sourceClient *managedplugin.Client
 		return fmt.Errorf("failed to init source %v: %w", sourceSpec.Name, err)
 	}
 
-	writeClients := make([]plugin.Plugin_WriteClient, len(destinationsPbClients))
+	log.Info().Str("source", sourceSpec.VersionString()).Strs("destinations", destinationStrings).Msg("Start fetching resources")
+	fmt.Printf("Starting migration for: %s -> %s\n", sourceSpec.VersionString(), destinationStrings)
+
+	if err := migrateTablesV3(ctx, sourcePbClient, sourceSpec, destinationsPbClients, destinationTransformers, destinationSpecs); err != nil {
+		return err
+	}
 	for i := range destinationsPbClients {
-		writeClients[i], err = destinationsPbClients[i].Write(ctx)
-		if err != nil {
+		if _, err := destinationsPbClients[i].Close(ctx, &plugin.Close_Request{}); err != nil {
 			return err
 		}
 	}
 
-	log.Info().Str("source", sourceSpec.VersionString()).Strs("destinations", destinationStrings).Msg("Start fetching resources")
-	fmt.Printf("Starting migration for: %s -> %s\n", sourceSpec.VersionString(), destinationStrings)
+	migrateTimeTook := time.Since(migrateStart)
+	fmt.Println("Migration completed successfully.")
+	log.Info().Str("source", sourceSpec.Name).
+		Strs("destinations", sourceSpec.Destinations).
+		Float64("time_took", migrateTimeTook.Seconds()).
+		Msg("Migration completed successfully")
+	return nil
+}
 
+// migrateTablesV3 migrates the tables of the source and the sync summary table in every destination,
+// returning once the destinations are done migrating.
+func migrateTablesV3(ctx context.Context, sourcePbClient plugin.PluginClient, sourceSpec specs.Source, destinationsPbClients []plugin.PluginClient, destinationTransformers []*transformer.RecordTransformer, destinationSpecs []specs.Destination) error {
 	getTablesRes, err := sourcePbClient.GetTables(ctx, &plugin.GetTables_Request{
 		Tables:              sourceSpec.Tables,
 		SkipTables:          sourceSpec.SkipTables,
@@ -83,9 +99,16 @@ func migrateConnectionV3(ctx context.Context, sourceClient *managedplugin.Client
 		return err
 	}
 
-	for i := range destinationsClients {
+	for i := range destinationsPbClients {
+		writeClient, err := destinationsPbClients[i].Write(ctx)
+		if err != nil {
+			return err
+		}
 		for _, sc := range schemas {
-			transformedSchema := destinationTransformers[i].TransformSchema(sc)
+			transformedSchema, err := destinationTransformers[i].TransformSchema(sc)
+			if err != nil {
+				return fmt.Errorf("destination %s: %w", destinationSpecs[i].Name, err)
+			}
 			transformedSchemaBytes, err := plugin.SchemaToBytes(transformedSchema)
 			if err != nil {
 				return err
@@ -97,27 +120,17 @@ func migrateConnectionV3(ctx context.Context, sourceClient *managedplugin.Client
 					Table:        transformedSchemaBytes,
 				},
 			}
-			if err := writeClients[i].Send(wr); err != nil {
-				return handleSendError(err, writeClients[i], "migrate")
+			if err := writeClient.Send(wr); err != nil {
+				return handleSendError(err, writeClient, "migrate")
 			}
 		}
 
-		if err := migrateSummaryTable(writeClients[i], destinationTransformers[i], destinationSpecs[i]); err != nil {
+		if err := migrateSummaryTable(writeClient, destinationTransformers[i], destinationSpecs[i]); err != nil {
 			return fmt.Errorf("failed to migrate sync summary table: %w", err)
 		}
-		if _, err := writeClients[i].CloseAndRecv(); err != nil {
-			return err
-		}
-		if _, err := destinationsPbClients[i].Close(ctx, &plugin.Close_Request{}); err != nil {
+		if _, err := writeClient.CloseAndRecv(); err != nil {
 			return err
 		}
 	}
-
-	migrateTimeTook := time.Since(migrateStart)
-	fmt.Println("Migration completed successfully.")
-	log.Info().Str("source", sourceSpec.Name).
-		Strs("destinations", sourceSpec.Destinations).
-		Float64("time_took", migrateTimeTook.Seconds()).
-		Msg("Migration completed successfully")
 	return nil
 }
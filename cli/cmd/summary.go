@@ -17,6 +17,8 @@ import (
 	"github.com/thoas/go-funk"
 )
 
+const syncSummaryTableName = "cloudquery_sync_summaries"
+
 type syncSummary struct {
 	CLIVersion          string    `json:"cli_version"`
 	DestinationErrors   uint64    `json:"destination_errors"`
@@ -73,9 +75,8 @@ func checkFilePath(filename string) error {
 }
 
 func generateSummaryTable() (*schema.Table, error) {
-	tableName := "cloudquery_sync_summaries"
 	t := schema.Tables{{
-		Name: tableName,
+		Name: syncSummaryTableName,
 		Transform: transformers.TransformWithStruct(
 			&syncSummary{},
 			transformers.WithSkipFields("SyncTime"),
@@ -97,7 +98,10 @@ func migrateSummaryTable(writeClient plugin.Plugin_WriteClient, destTransformer
 		return err
 	}
 	summaryTableSchema := summaryTable.ToArrowSchema()
-	transformedSchema := destTransformer.TransformSchema(summaryTableSchema)
+	transformedSchema, err := destTransformer.TransformSchema(summaryTableSchema)
+	if err != nil {
+		return err
+	}
 	transformedSchemaBytes, err := plugin.SchemaToBytes(transformedSchema)
 	if err != nil {
 		return err
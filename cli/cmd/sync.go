@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"slices"
 	"strings"
+	gosync "sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
 
 	apiAuth "github.com/cloudquery/cloudquery-api-go/auth"
 	"github.com/cloudquery/cloudquery/cli/internal/auth"
@@ -13,6 +20,7 @@ import (
 	"github.com/cloudquery/plugin-pb-go/managedplugin"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -21,6 +29,12 @@ const (
 cloudquery sync ./directory
 # Sync resources from directories and files
 cloudquery sync ./directory ./aws.yml ./pg.yml
+# Sync up to 4 sources at the same time
+cloudquery sync ./directory --max-parallel-sources 4
+# Report the migrations and rows a sync would write, without writing anything
+cloudquery sync ./directory --dry-run
+# Sync all sources even if some of them fail
+cloudquery sync ./directory --continue-on-error
 `
 )
 
@@ -36,6 +50,9 @@ func NewCmdSync() *cobra.Command {
 	cmd.Flags().Bool("no-migrate", false, "Disable auto-migration before sync. By default, sync runs a migration before syncing resources.")
 	cmd.Flags().String("license", "", "set offline license file")
 	cmd.Flags().String("summary-location", "", "Sync summary file location. This feature is in Preview. Please provide feedback to help us improve it.")
+	cmd.Flags().Bool("dry-run", false, "Run the source and report, per destination, the migrations that would be applied and the number of rows that would be written, without migrating or writing anything to the destinations or the state backend. Destinations are still initialized, so that they can report their existing tables.")
+	cmd.Flags().Bool("continue-on-error", false, fmt.Sprintf("Keep syncing the remaining sources when a source fails, print a summary of all sources at the end and exit with code %d if any of them failed.", ExitCodeSourcesFailed))
+	cmd.Flags().Int("max-parallel-sources", 1, "Maximum number of sources to sync concurrently. Sources synced concurrently each start their own instances of their destination plugins, and migrate the tables of a shared destination one source at a time.")
 
 	return cmd
 }
@@ -93,6 +110,29 @@ func sync(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	summaryLocation, err := cmd.Flags().GetString("summary-location")
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	continueOnError, err := cmd.Flags().GetBool("continue-on-error")
+	if err != nil {
+		return err
+	}
+
+	maxParallelSources, err := cmd.Flags().GetInt("max-parallel-sources")
+	if err != nil {
+		return err
+	}
+	if maxParallelSources < 1 {
+		return fmt.Errorf("max-parallel-sources must be at least 1, got %d", maxParallelSources)
+	}
+
 	// in the cloud sync environment, we pass only the relevant environment variables to the plugin
 	isolatePluginEnvironment := apiAuth.NewTokenClient().GetTokenType() == apiAuth.SyncRunAPIKey
 
@@ -106,6 +146,7 @@ func sync(cmd *cobra.Command, args []string) error {
 
 	sources := specReader.Sources
 	destinations := specReader.Destinations
+	parallel := maxParallelSources > 1 && len(sources) > 1
 	sourcePluginClients := make(managedplugin.Clients, 0)
 	defer func() {
 		if err := sourcePluginClients.Terminate(); err != nil {
@@ -164,7 +205,7 @@ func sync(cmd *cobra.Command, args []string) error {
 			fmt.Println(err)
 		}
 	}()
-	for _, destination := range destinations {
+	newDestinationClient := func(destination *specs.Destination) (*managedplugin.Client, error) {
 		opts := []managedplugin.Option{
 			managedplugin.WithLogger(log.Logger),
 			managedplugin.WithAuthToken(authToken.Value),
@@ -190,12 +231,46 @@ func sync(cmd *cobra.Command, args []string) error {
 		}
 		destPluginClient, err := managedplugin.NewClient(ctx, managedplugin.PluginDestination, cfg, opts...)
 		if err != nil {
-			return enrichClientError(managedplugin.Clients{}, []bool{destination.RegistryInferred()}, err)
+			return nil, enrichClientError(managedplugin.Clients{}, []bool{destination.RegistryInferred()}, err)
 		}
-		destinationPluginClients = append(destinationPluginClients, destPluginClient)
+		return destPluginClient, nil
 	}
 
-	for _, source := range sources {
+	// A destination plugin holds a single initialized spec and write stream at a time,
+	// so sources synced in parallel each get their own destination plugin processes.
+	destinationPluginClientsBySource := make(map[string]managedplugin.Clients, len(sources))
+	var locks migrationLocks
+	if parallel {
+		locks = newMigrationLocks(destinations)
+		for _, source := range sources {
+			clients := make(managedplugin.Clients, 0)
+			for _, destination := range destinations {
+				if !slices.Contains(source.Destinations, destination.Name) && (dryRun || !isSourceBackend(source, destination)) {
+					continue
+				}
+				destPluginClient, err := newDestinationClient(destination)
+				if err != nil {
+					return err
+				}
+				clients = append(clients, destPluginClient)
+				destinationPluginClients = append(destinationPluginClients, destPluginClient)
+			}
+			destinationPluginClientsBySource[source.Name] = clients
+		}
+	} else {
+		for _, destination := range destinations {
+			destPluginClient, err := newDestinationClient(destination)
+			if err != nil {
+				return err
+			}
+			destinationPluginClients = append(destinationPluginClients, destPluginClient)
+		}
+		for _, source := range sources {
+			destinationPluginClientsBySource[source.Name] = destinationPluginClients
+		}
+	}
+
+	syncSource := func(source *specs.Source) error {
 		cl := sourcePluginClients.ClientByName(source.Name)
 		versions, err := cl.Versions(ctx)
 		if err != nil {
@@ -203,20 +278,21 @@ func sync(cmd *cobra.Command, args []string) error {
 		}
 		maxVersion := findMaxCommonVersion(versions, []int{0, 1, 2, 3})
 
+		sourceDestinationClients := destinationPluginClientsBySource[source.Name]
 		var destinationClientsForSource []*managedplugin.Client
 		var destinationForSourceSpec []specs.Destination
 		var backendClientForSource *managedplugin.Client
 		var destinationForSourceBackendSpec *specs.Destination
 		for _, destination := range destinations {
 			if slices.Contains(source.Destinations, destination.Name) {
-				destinationClientsForSource = append(destinationClientsForSource, destinationPluginClients.ClientByName(destination.Name))
+				destinationClientsForSource = append(destinationClientsForSource, sourceDestinationClients.ClientByName(destination.Name))
 				destinationForSourceSpec = append(destinationForSourceSpec, *destination)
 				continue
 			}
 
 			// if the destination is specified as a backend, but not used as a destination, then we initialize it separately
-			if source.BackendOptions != nil && strings.Contains(source.BackendOptions.Connection, "@@plugins."+destination.Name+".") {
-				backendClientForSource = destinationPluginClients.ClientByName(destination.Name)
+			if isSourceBackend(source, destination) {
+				backendClientForSource = sourceDestinationClients.ClientByName(destination.Name)
 				destinationForSourceBackendSpec = destination
 			}
 		}
@@ -252,24 +328,29 @@ func sync(cmd *cobra.Command, args []string) error {
 					spec:   destinationForSourceSpec[i],
 				})
 			}
+			// a dry run doesn't use the state backend, so that it neither reads nor updates the state of incremental tables
 			var backend *v3destination
-			if backendClientForSource != nil && destinationForSourceBackendSpec != nil {
+			if backendClientForSource != nil && destinationForSourceBackendSpec != nil && !dryRun {
 				backend = &v3destination{
 					client: backendClientForSource,
 					spec:   *destinationForSourceBackendSpec,
 				}
 			}
 
-			summaryLocation, err := cmd.Flags().GetString("summary-location")
-			if err != nil {
-				return err
-			}
-
-			if err := syncConnectionV3(ctx, src, dests, backend, invocationUUID.String(), noMigrate, summaryLocation); err != nil {
+			if err := syncConnectionV3(ctx, src, dests, backend, invocationUUID.String(), noMigrate, summaryLocation, parallel, dryRun, locks); err != nil {
 				return fmt.Errorf("failed to sync v3 source %s: %w", cl.Name(), err)
 			}
 
 		case 2:
+			if dryRun {
+				return fmt.Errorf("dry run is not supported for source %s, as it uses CloudQuery protocol version 2. Please upgrade to a newer version of the source plugin", source.Name)
+			}
+			if err := validateNoTransformations(source.Name, destinationForSourceSpec); err != nil {
+				return err
+			}
+			if err := validateNoFilters(source.Name, destinationForSourceSpec); err != nil {
+				return err
+			}
 			destinationsVersions := make([][]int, 0, len(destinationClientsForSource))
 			for _, destination := range destinationClientsForSource {
 				versions, err := destination.Versions(ctx)
@@ -281,11 +362,20 @@ func sync(cmd *cobra.Command, args []string) error {
 				}
 				destinationsVersions = append(destinationsVersions, versions)
 			}
-			if err := syncConnectionV2(ctx, cl, destinationClientsForSource, *source, destinationForSourceSpec, invocationUUID.String(), noMigrate, destinationsVersions); err != nil {
+			if err := syncConnectionV2(ctx, cl, destinationClientsForSource, *source, destinationForSourceSpec, invocationUUID.String(), noMigrate, destinationsVersions, locks); err != nil {
 				return fmt.Errorf("failed to sync v2 source %s: %w", cl.Name(), err)
 			}
 		case 1:
-			if err := syncConnectionV1(ctx, cl, destinationClientsForSource, *source, destinationForSourceSpec, invocationUUID.String(), noMigrate); err != nil {
+			if dryRun {
+				return fmt.Errorf("dry run is not supported for source %s, as it uses CloudQuery protocol version 1. Please upgrade to a newer version of the source plugin", source.Name)
+			}
+			if err := validateNoTransformations(source.Name, destinationForSourceSpec); err != nil {
+				return err
+			}
+			if err := validateNoFilters(source.Name, destinationForSourceSpec); err != nil {
+				return err
+			}
+			if err := syncConnectionV1(ctx, cl, destinationClientsForSource, *source, destinationForSourceSpec, invocationUUID.String(), noMigrate, locks); err != nil {
 				return fmt.Errorf("failed to sync v1 source %s: %w", cl.Name(), err)
 			}
 		case 0:
@@ -297,11 +387,170 @@ func sync(cmd *cobra.Command, args []string) error {
 		default:
 			return fmt.Errorf("unknown source version %d", maxVersion)
 		}
+		return nil
+	}
+
+	results := syncSources(ctx, cmd.OutOrStdout(), sources, maxParallelSources, continueOnError, syncSource)
+	if continueOnError {
+		printSourceSyncResults(cmd.OutOrStdout(), results)
+	}
+	var errs []error
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, result.err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	if continueOnError {
+		return &sourcesFailedError{failed: len(errs), total: len(results), err: errors.Join(errs...)}
+	}
+	return errors.Join(errs...)
+}
+
+type sourceSyncStatus string
+
+const (
+	sourceSyncSucceeded sourceSyncStatus = "succeeded"
+	sourceSyncFailed    sourceSyncStatus = "failed"
+	sourceSyncSkipped   sourceSyncStatus = "skipped"
+)
+
+type sourceSyncResult struct {
+	source   string
+	status   sourceSyncStatus
+	duration time.Duration
+	err      error
+}
+
+// syncSources runs syncFn for every source, with at most maxParallel sources syncing at the same time.
+// Once a source fails, sources that haven't started yet are skipped, unless continueOnError is set.
+// Sources that are already running are never interrupted, so all of their errors are gathered in the results.
+// When sources are synced in parallel, a line is printed to w as each of them completes.
+func syncSources(ctx context.Context, w io.Writer, sources []*specs.Source, maxParallel int, continueOnError bool, syncFn func(*specs.Source) error) []sourceSyncResult {
+	results := make([]sourceSyncResult, len(sources))
+	parallel := maxParallel > 1 && len(sources) > 1
+	var failed atomic.Bool
+	var (
+		outputMu  gosync.Mutex
+		completed int
+	)
+	var eg errgroup.Group
+	eg.SetLimit(maxParallel)
+	for i, source := range sources {
+		i, source := i, source
+		results[i] = sourceSyncResult{source: source.Name, status: sourceSyncSkipped}
+		eg.Go(func() error {
+			if ctx.Err() != nil || (failed.Load() && !continueOnError) {
+				return nil
+			}
+			start := time.Now()
+			err := syncFn(source)
+			results[i].duration = time.Since(start)
+			results[i].status = sourceSyncSucceeded
+			if err != nil {
+				log.Error().Err(err).Str("source", source.Name).Msg("Source sync failed")
+				failed.Store(true)
+				results[i].status, results[i].err = sourceSyncFailed, err
+			}
+			if parallel {
+				outputMu.Lock()
+				completed++
+				printSourceSyncCompletion(w, results[i], completed, len(sources))
+				outputMu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	return results
+}
+
+func printSourceSyncCompletion(w io.Writer, result sourceSyncResult, completed, total int) {
+	msg := fmt.Sprintf("[%d/%d] Source %s %s in %s", completed, total, result.source, result.status, result.duration.Truncate(time.Second))
+	if result.err != nil {
+		msg += ": " + strings.ReplaceAll(result.err.Error(), "\n", " ")
+	}
+	fmt.Fprintln(w, msg)
+}
+
+func printSourceSyncResults(w io.Writer, results []sourceSyncResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tSTATUS\tDURATION\tERROR")
+	for _, result := range results {
+		errMsg := ""
+		if result.err != nil {
+			errMsg = strings.ReplaceAll(result.err.Error(), "\n", " ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.source, result.status, result.duration.Truncate(time.Second), errMsg)
 	}
+	_ = tw.Flush()
+}
+
+// migrationLocks serializes the migrations of each destination when sources are synced in parallel,
+// as the destination plugin processes of different sources could otherwise migrate the same tables concurrently.
+// A nil migrationLocks doesn't lock anything.
+type migrationLocks map[string]*gosync.Mutex
+
+func newMigrationLocks(destinations []*specs.Destination) migrationLocks {
+	locks := make(migrationLocks, len(destinations))
+	for _, destination := range destinations {
+		locks[destination.Name] = new(gosync.Mutex)
+	}
+	return locks
+}
+
+// lock locks the named destinations in the order of their names, so that sources sharing several destinations don't deadlock,
+// and returns the function unlocking them.
+func (l migrationLocks) lock(destinations []specs.Destination) (unlock func()) {
+	names := make([]string, len(destinations))
+	for i := range destinations {
+		names[i] = destinations[i].Name
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+	locked := make([]*gosync.Mutex, 0, len(names))
+	for _, name := range names {
+		if mu := l[name]; mu != nil {
+			mu.Lock()
+			locked = append(locked, mu)
+		}
+	}
+	return func() {
+		for _, mu := range locked {
+			mu.Unlock()
+		}
+	}
+}
+
+// validateNoTransformations returns an error if any of the destinations has transformations,
+// as these are only applied for sources using CloudQuery protocol version 3
+func validateNoTransformations(sourceName string, destinations []specs.Destination) error {
+	for _, destination := range destinations {
+		if len(destination.Transformations) > 0 {
+			return fmt.Errorf("destination %s has transformations, which are not supported for source %s. Please upgrade to a newer version of the source plugin", destination.Name, sourceName)
+		}
+	}
+	return nil
+}
 
+// validateNoFilters returns an error if any of the destinations has row filters,
+// as these are only applied for sources using CloudQuery protocol version 3
+func validateNoFilters(sourceName string, destinations []specs.Destination) error {
+	for _, destination := range destinations {
+		if len(destination.Filter) > 0 {
+			return fmt.Errorf("destination %s has filters, which are not supported for source %s. Please upgrade to a newer version of the source plugin", destination.Name, sourceName)
+		}
+	}
 	return nil
 }
 
+// isSourceBackend reports whether the destination is referenced in the state backend connection of the source
+func isSourceBackend(source *specs.Source, destination *specs.Destination) bool {
+	return source.BackendOptions != nil && strings.Contains(source.BackendOptions.Connection, "@@plugins."+destination.Name+".")
+}
+
 func filterPluginEnv(environ []string, pluginName, kind string) []string {
 	env := make([]string, 0)
 	cleanName := strings.ReplaceAll(pluginName, "-", "_")
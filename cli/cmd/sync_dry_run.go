@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/cloudquery/cli/internal/specs/v0"
+	"github.com/cloudquery/plugin-pb-go/pb/plugin/v3"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog/log"
+)
+
+type migrationAction string
+
+const (
+	migrationActionNone       migrationAction = "none"
+	migrationActionCreate     migrationAction = "create"
+	migrationActionAddColumns migrationAction = "add columns"
+	migrationActionForced     migrationAction = "needs migrate_mode: forced"
+	migrationActionRecreate   migrationAction = "recreate (migrate_mode: forced)"
+	migrationActionUnknown    migrationAction = "unknown"
+	migrationActionDisabled   migrationAction = "disabled (--no-migrate)"
+)
+
+// dryRunDestination collects everything a sync would have written to a single destination
+type dryRunDestination struct {
+	tables  []string
+	schemas map[string]*arrow.Schema
+	rows    map[string]int64
+}
+
+type dryRunTablePlan struct {
+	table   string
+	rows    int64
+	action  migrationAction
+	details []string
+}
+
+func newDryRunDestination() *dryRunDestination {
+	return &dryRunDestination{
+		schemas: make(map[string]*arrow.Schema),
+		rows:    make(map[string]int64),
+	}
+}
+
+func (d *dryRunDestination) addTable(tableName string) {
+	if _, ok := d.rows[tableName]; !ok {
+		d.tables = append(d.tables, tableName)
+		d.rows[tableName] = 0
+	}
+}
+
+func (d *dryRunDestination) migrateTable(sc *arrow.Schema) {
+	tableName := tableNameFromSchema(sc)
+	d.addTable(tableName)
+	d.schemas[tableName] = sc
+}
+
+func (d *dryRunDestination) insert(record arrow.Record) {
+	tableName := tableNameFromSchema(record.Schema())
+	d.addTable(tableName)
+	d.rows[tableName] += record.NumRows()
+}
+
+// plan compares the tables the sync would migrate with the tables that currently exist in the destination.
+// existing is nil when the destination can't report its current tables, in which case the migrations are unknown.
+func (d *dryRunDestination) plan(existing schema.Tables, spec specs.Destination, noMigrate bool) ([]dryRunTablePlan, error) {
+	plans := make([]dryRunTablePlan, 0, len(d.tables))
+	for _, tableName := range d.tables {
+		p := dryRunTablePlan{table: tableName, rows: d.rows[tableName], action: migrationActionNone}
+		sc, ok := d.schemas[tableName]
+		switch {
+		case noMigrate:
+			p.action = migrationActionDisabled
+		case !ok:
+			// the source sent rows without migrating the table first, so there is nothing to plan
+		case existing == nil:
+			p.action = migrationActionUnknown
+		default:
+			wanted, err := schema.NewTableFromArrowSchema(sc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert schema of table %s: %w", tableName, err)
+			}
+			p.action, p.details = planTableMigration(wanted, existing.Get(tableName))
+			if p.action == migrationActionForced && spec.MigrateMode == specs.MigrateModeForced {
+				p.action = migrationActionRecreate
+			}
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+// planTableMigration works out what a destination has to do to change the existing table into the wanted one.
+// It follows the rules destinations use for automatic migrations: nullable columns can be added in place and
+// dropped columns or unique constraints are left alone, but any other change requires `migrate_mode: forced`.
+func planTableMigration(wanted, existing *schema.Table) (migrationAction, []string) {
+	if existing == nil {
+		return migrationActionCreate, nil
+	}
+	changes := wanted.GetChanges(existing)
+	if len(changes) == 0 {
+		return migrationActionNone, nil
+	}
+	action := migrationActionNone
+	details := make([]string, 0, len(changes))
+	for _, change := range changes {
+		switch change.Type {
+		case schema.TableColumnChangeTypeAdd:
+			details = append(details, fmt.Sprintf("add column %s (%s)", change.ColumnName, change.Current.Type))
+			if change.Current.NotNull || change.Current.PrimaryKey {
+				action = migrationActionForced
+			} else if action == migrationActionNone {
+				action = migrationActionAddColumns
+			}
+		case schema.TableColumnChangeTypeRemove:
+			details = append(details, fmt.Sprintf("remove column %s", change.ColumnName))
+			if change.Previous.NotNull || change.Previous.PrimaryKey {
+				action = migrationActionForced
+			}
+		case schema.TableColumnChangeTypeRemoveUniqueConstraint:
+			details = append(details, fmt.Sprintf("remove unique constraint from column %s", change.ColumnName))
+		default:
+			details = append(details, change.String())
+			action = migrationActionForced
+		}
+	}
+	return action, details
+}
+
+// getDestinationTables asks a destination for the current schema of the given tables.
+// Most destinations don't implement GetTables, in which case nil is returned.
+func getDestinationTables(ctx context.Context, client plugin.PluginClient, destinationName string, tableNames []string) schema.Tables {
+	res, err := client.GetTables(ctx, &plugin.GetTables_Request{Tables: tableNames})
+	if err != nil {
+		log.Warn().Err(err).Str("destination", destinationName).Msg("Destination can't report its current tables, skipping migration planning")
+		return nil
+	}
+	schemas, err := plugin.NewSchemasFromBytes(res.Tables)
+	if err != nil {
+		log.Warn().Err(err).Str("destination", destinationName).Msg("Failed to decode destination tables, skipping migration planning")
+		return nil
+	}
+	tables, err := schema.NewTablesFromArrowSchemas(schemas)
+	if err != nil {
+		log.Warn().Err(err).Str("destination", destinationName).Msg("Failed to decode destination tables, skipping migration planning")
+		return nil
+	}
+	if tables == nil {
+		// the destination answered, it just has none of the tables yet
+		tables = schema.Tables{}
+	}
+	return tables
+}
+
+func printDryRunPlan(w io.Writer, sourceName string, destinationName string, plans []dryRunTablePlan) {
+	fmt.Fprintf(w, "Dry run for %s -> %s:\n", sourceName, destinationName)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tROWS\tMIGRATION\tDETAILS")
+	for _, p := range plans {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", p.table, p.rows, p.action, strings.Join(p.details, "; "))
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/cloudquery/cli/internal/specs/v0"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanTableMigration(t *testing.T) {
+	existing := &schema.Table{
+		Name: "test_table",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true, NotNull: true},
+			{Name: "name", Type: arrow.BinaryTypes.String},
+		},
+	}
+	cases := []struct {
+		name        string
+		wanted      *schema.Table
+		existing    *schema.Table
+		wantAction  migrationAction
+		wantDetails []string
+	}{
+		{
+			name:       "create",
+			wanted:     existing,
+			wantAction: migrationActionCreate,
+		},
+		{
+			name:       "no_changes",
+			wanted:     existing,
+			existing:   existing,
+			wantAction: migrationActionNone,
+		},
+		{
+			name: "add_nullable_column",
+			wanted: &schema.Table{
+				Name: "test_table",
+				Columns: schema.ColumnList{
+					{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true, NotNull: true},
+					{Name: "name", Type: arrow.BinaryTypes.String},
+					{Name: "email", Type: arrow.BinaryTypes.String},
+				},
+			},
+			existing:    existing,
+			wantAction:  migrationActionAddColumns,
+			wantDetails: []string{"add column email (utf8)"},
+		},
+		{
+			name: "add_not_null_column",
+			wanted: &schema.Table{
+				Name: "test_table",
+				Columns: schema.ColumnList{
+					{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true, NotNull: true},
+					{Name: "name", Type: arrow.BinaryTypes.String},
+					{Name: "email", Type: arrow.BinaryTypes.String, NotNull: true},
+				},
+			},
+			existing:    existing,
+			wantAction:  migrationActionForced,
+			wantDetails: []string{"add column email (utf8)"},
+		},
+		{
+			name: "change_column_type",
+			wanted: &schema.Table{
+				Name: "test_table",
+				Columns: schema.ColumnList{
+					{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true, NotNull: true},
+					{Name: "name", Type: arrow.PrimitiveTypes.Int64},
+				},
+			},
+			existing:   existing,
+			wantAction: migrationActionForced,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			action, details := planTableMigration(tc.wanted, tc.existing)
+			assert.Equal(t, tc.wantAction, action)
+			if tc.wantDetails != nil {
+				assert.Equal(t, tc.wantDetails, details)
+			}
+		})
+	}
+}
+
+func TestDryRunDestinationPlan(t *testing.T) {
+	table := &schema.Table{
+		Name: "test_table",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true, NotNull: true},
+		},
+	}
+	sc := table.ToArrowSchema()
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	record := bldr.NewRecord()
+
+	d := newDryRunDestination()
+	d.migrateTable(sc)
+	d.insert(record)
+	d.insert(record)
+
+	plans, err := d.plan(schema.Tables{}, specs.Destination{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []dryRunTablePlan{{table: "test_table", rows: 6, action: migrationActionCreate}}, plans)
+
+	plans, err = d.plan(nil, specs.Destination{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []dryRunTablePlan{{table: "test_table", rows: 6, action: migrationActionUnknown}}, plans)
+
+	plans, err = d.plan(schema.Tables{}, specs.Destination{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []dryRunTablePlan{{table: "test_table", rows: 6, action: migrationActionDisabled}}, plans)
+
+	existing := schema.Tables{{
+		Name: "test_table",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true, NotNull: true},
+			{Name: "name", Type: arrow.BinaryTypes.String},
+		},
+	}}
+	wanted := &schema.Table{
+		Name: "other_table",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		},
+	}
+	d.migrateTable(wanted.ToArrowSchema())
+	plans, err = d.plan(existing, specs.Destination{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []dryRunTablePlan{
+		{table: "test_table", rows: 6, action: migrationActionNone, details: []string{"remove column name"}},
+		{table: "other_table", action: migrationActionCreate},
+	}, plans)
+
+	var buf bytes.Buffer
+	printDryRunPlan(&buf, "test-source", "test-destination", plans)
+	assert.Equal(t, "Dry run for test-source -> test-destination:\n"+
+		"TABLE        ROWS  MIGRATION  DETAILS\n"+
+		"test_table   6     none       remove column name\n"+
+		"other_table  0     create     \n", buf.String())
+}
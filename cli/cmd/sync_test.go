@@ -2,26 +2,37 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path"
 	"runtime"
 	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/cloudquery/cloudquery/cli/internal/specs/v0"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
 )
 
 func TestSync(t *testing.T) {
 	configs := []struct {
-		name    string
-		config  string
-		err     string
-		summary []syncSummary
+		name     string
+		config   string
+		args     []string
+		err      string
+		exitCode int
+		output   []string
+		summary  []syncSummary
 	}{
 		{
 			name:   "sync_success_sourcev1_destv0",
@@ -51,10 +62,47 @@ func TestSync(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "multiple_sources_parallel",
+			config: "multiple-sources.yml",
+			args:   []string{"--max-parallel-sources", "2"},
+		},
+		{
+			name:     "multiple_sources_continue_on_error",
+			config:   "multiple-sources-one-failing.yml",
+			args:     []string{"--continue-on-error", "--max-parallel-sources", "2"},
+			err:      "1 of 2 sources failed to sync",
+			exitCode: ExitCodeSourcesFailed,
+			output: []string{
+				`(?m)^SOURCE\s+STATUS\s+DURATION\s+ERROR$`,
+				`(?m)^test\s+succeeded\s+\S+\s*$`,
+				`(?m)^test-failing\s+failed\s+\S+\s+failed to sync v3 source test-failing: `,
+			},
+			summary: []syncSummary{
+				{
+					CLIVersion:      "development",
+					DestinationName: "test",
+					DestinationPath: "cloudquery/test",
+					Resources:       12,
+					SourceName:      "test",
+					SourcePath:      "cloudquery/test",
+				},
+			},
+		},
+		{
+			name:   "multiple_sources_destinations_parallel",
+			config: "multiple-sources-destinations.yml",
+			args:   []string{"--max-parallel-sources", "2"},
+		},
 		{
 			name:   "multiple_destinations",
 			config: "multiple-destinations.yml",
 		},
+		{
+			name:   "multiple_sources_destinations_dry_run",
+			config: "multiple-sources-destinations.yml",
+			args:   []string{"--dry-run"},
+		},
 		{
 			name:   "multiple_sources_destinations",
 			config: "multiple-sources-destinations.yml",
@@ -107,6 +155,7 @@ func TestSync(t *testing.T) {
 
 			baseArgs := testCommandArgs(t)
 			argList := append([]string{"sync", testConfig}, baseArgs...)
+			argList = append(argList, tc.args...)
 			summaryPath := ""
 			if len(tc.summary) > 0 {
 				tmp := t.TempDir()
@@ -114,6 +163,8 @@ func TestSync(t *testing.T) {
 				argList = append(argList, "--summary-location", summaryPath)
 			}
 
+			var out bytes.Buffer
+			cmd.SetOut(&out)
 			cmd.SetArgs(argList)
 			err := cmd.Execute()
 			if tc.err != "" {
@@ -121,6 +172,12 @@ func TestSync(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 			}
+			if tc.exitCode != 0 {
+				assert.Equal(t, tc.exitCode, ExitCode(err))
+			}
+			for _, pattern := range tc.output {
+				assert.Regexp(t, pattern, out.String())
+			}
 
 			if len(tc.summary) > 0 {
 				summaries := readSummaries(t, summaryPath)
@@ -147,13 +204,17 @@ func TestSync(t *testing.T) {
 			testConfig := path.Join(currentDir, "testdata", tc.config)
 
 			cmd := NewCmdRoot()
-			cmd.SetArgs(append([]string{"sync", testConfig, "--no-migrate"}, testCommandArgs(t)...))
+			argList := append([]string{"sync", testConfig, "--no-migrate"}, testCommandArgs(t)...)
+			cmd.SetArgs(append(argList, tc.args...))
 			err := cmd.Execute()
 			if tc.err != "" {
 				require.Contains(t, err.Error(), tc.err)
 			} else {
 				require.NoError(t, err)
 			}
+			if tc.exitCode != 0 {
+				require.Equal(t, tc.exitCode, ExitCode(err))
+			}
 		})
 	}
 }
@@ -240,6 +301,29 @@ func TestSyncWithSummaryTable(t *testing.T) {
 	}
 }
 
+func TestSyncDryRunWithBackend(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	currentDir := path.Dir(filename)
+	testConfig := path.Join(currentDir, "testdata", "dry-run-with-backend.yml")
+
+	destinationDir := t.TempDir()
+	backendDir := t.TempDir()
+	// this is the only way to inject the dynamic output paths
+	t.Setenv("CQ_DRY_RUN_DESTINATION", path.Join(destinationDir, "{{TABLE}}/{{UUID}}.{{FORMAT}}"))
+	t.Setenv("CQ_DRY_RUN_BACKEND", path.Join(backendDir, "{{TABLE}}/{{UUID}}.{{FORMAT}}"))
+
+	cmd := NewCmdRoot()
+	cmd.SetArgs(append([]string{"sync", testConfig, "--dry-run"}, testCommandArgs(t)...))
+	require.NoError(t, cmd.Execute())
+
+	// neither the destination nor the state backend are written to
+	for _, dir := range []string{destinationDir, backendDir} {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "unexpected files written to %s", dir)
+	}
+}
+
 func TestSyncCqDir(t *testing.T) {
 	_, filename, _, _ := runtime.Caller(0)
 	currentDir := path.Dir(filename)
@@ -281,6 +365,144 @@ func TestFindMaxCommonVersion(t *testing.T) {
 	}
 }
 
+func TestSyncSources(t *testing.T) {
+	sources := []*specs.Source{
+		{Metadata: specs.Metadata{Name: "source-1"}},
+		{Metadata: specs.Metadata{Name: "source-2"}},
+		{Metadata: specs.Metadata{Name: "source-3"}},
+		{Metadata: specs.Metadata{Name: "source-4"}},
+	}
+	cases := []struct {
+		name            string
+		maxParallel     int
+		continueOnError bool
+		want            []sourceSyncStatus
+	}{
+		{
+			name:        "sequential",
+			maxParallel: 1,
+			want:        []sourceSyncStatus{sourceSyncSucceeded, sourceSyncFailed, sourceSyncSkipped, sourceSyncSkipped},
+		},
+		{
+			name:            "sequential_continue_on_error",
+			maxParallel:     1,
+			continueOnError: true,
+			want:            []sourceSyncStatus{sourceSyncSucceeded, sourceSyncFailed, sourceSyncSucceeded, sourceSyncFailed},
+		},
+		{
+			name:            "parallel_continue_on_error",
+			maxParallel:     2,
+			continueOnError: true,
+			want:            []sourceSyncStatus{sourceSyncSucceeded, sourceSyncFailed, sourceSyncSucceeded, sourceSyncFailed},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var running, maxRunning int64
+			var out bytes.Buffer
+			results := syncSources(context.Background(), &out, sources, tc.maxParallel, tc.continueOnError, func(source *specs.Source) error {
+				n := atomic.AddInt64(&running, 1)
+				defer atomic.AddInt64(&running, -1)
+				for {
+					m := atomic.LoadInt64(&maxRunning)
+					if n <= m || atomic.CompareAndSwapInt64(&maxRunning, m, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				if source.Name == "source-2" || source.Name == "source-4" {
+					return fmt.Errorf("failed to sync %s", source.Name)
+				}
+				return nil
+			})
+
+			assert.LessOrEqual(t, atomic.LoadInt64(&maxRunning), int64(tc.maxParallel))
+			require.Len(t, results, len(sources))
+			for i, result := range results {
+				assert.Equal(t, sources[i].Name, result.source)
+				assert.Equal(t, tc.want[i], result.status, "unexpected status for %s", result.source)
+				assert.Equal(t, result.status == sourceSyncFailed, result.err != nil)
+			}
+
+			// sources synced in parallel print a line as each of them completes
+			if tc.maxParallel == 1 {
+				assert.Empty(t, out.String())
+				return
+			}
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			require.Len(t, lines, len(sources))
+			for i, line := range lines {
+				assert.Regexp(t, fmt.Sprintf(`^\[%d/4\] Source source-\d (succeeded in 0s|failed in 0s: failed to sync source-\d)$`, i+1), line)
+			}
+		})
+	}
+}
+
+func TestMigrationLocks(t *testing.T) {
+	locks := newMigrationLocks([]*specs.Destination{
+		{Metadata: specs.Metadata{Name: "postgresql"}},
+		{Metadata: specs.Metadata{Name: "bigquery"}},
+	})
+	destinations := [][]specs.Destination{
+		{{Metadata: specs.Metadata{Name: "postgresql"}}, {Metadata: specs.Metadata{Name: "bigquery"}}},
+		{{Metadata: specs.Metadata{Name: "bigquery"}}, {Metadata: specs.Metadata{Name: "postgresql"}}},
+		{{Metadata: specs.Metadata{Name: "bigquery"}}},
+	}
+
+	var migrating, maxMigrating int64
+	var eg errgroup.Group
+	for i := 0; i < 30; i++ {
+		destinations := destinations[i%len(destinations)]
+		eg.Go(func() error {
+			unlock := locks.lock(destinations)
+			defer unlock()
+			n := atomic.AddInt64(&migrating, 1)
+			defer atomic.AddInt64(&migrating, -1)
+			for {
+				m := atomic.LoadInt64(&maxMigrating)
+				if n <= m || atomic.CompareAndSwapInt64(&maxMigrating, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+	}
+	require.NoError(t, eg.Wait())
+	// every source shares bigquery, so they migrate one at a time
+	assert.EqualValues(t, 1, atomic.LoadInt64(&maxMigrating))
+
+	// without locks nothing is locked
+	var noLocks migrationLocks
+	noLocks.lock(destinations[0])()
+}
+
+func TestPrintSourceSyncResults(t *testing.T) {
+	var buf bytes.Buffer
+	printSourceSyncResults(&buf, []sourceSyncResult{
+		{source: "aws", status: sourceSyncSucceeded, duration: 90 * time.Second},
+		{source: "gcp", status: sourceSyncFailed, duration: time.Second, err: errors.New("bad credentials")},
+		{source: "azure", status: sourceSyncSkipped},
+	})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	assert.Equal(t, []string{
+		"SOURCE  STATUS     DURATION  ERROR",
+		"aws     succeeded  1m30s",
+		"gcp     failed     1s        bad credentials",
+		"azure   skipped    0s",
+	}, lines)
+}
+
+func TestSourcesFailedErrorExitCode(t *testing.T) {
+	err := fmt.Errorf("received interrupt signal from OS: %w", &sourcesFailedError{failed: 1, total: 2, err: errors.New("bad credentials")})
+	assert.Equal(t, ExitCodeSourcesFailed, ExitCode(err))
+	assert.Equal(t, "received interrupt signal from OS: 1 of 2 sources failed to sync: bad credentials", err.Error())
+	assert.Equal(t, 1, ExitCode(errors.New("failed to load spec(s)")))
+}
+
 func TestSync_IsolatedPluginEnvironmentsInCloud(t *testing.T) {
 	configs := []struct {
 		name   string
@@ -22,7 +22,7 @@ import (
 type ExitReason string
 
 // nolint:dupl
-func syncConnectionV1(ctx context.Context, sourceClient *managedplugin.Client, destinationsClients managedplugin.Clients, sourceSpec specs.Source, destinationSpecs []specs.Destination, uid string, noMigrate bool) error {
+func syncConnectionV1(ctx context.Context, sourceClient *managedplugin.Client, destinationsClients managedplugin.Clients, sourceSpec specs.Source, destinationSpecs []specs.Destination, uid string, noMigrate bool, locks migrationLocks) error {
 	var mt metrics.Metrics
 	var exitReason = ExitReasonStopped
 	defer func() {
@@ -76,13 +76,16 @@ func syncConnectionV1(ctx context.Context, sourceClient *managedplugin.Client, d
 	if !noMigrate {
 		migrateStart := time.Now().UTC()
 		fmt.Printf("Starting migration for: %s -> %s\n", sourceSpec.VersionString(), destinationStrings)
+		unlock := locks.lock(destinationSpecs)
 		for i := range destinationsClients {
 			if _, err := destinationsPbClients[i].Migrate(ctx, &destination.Migrate_Request{
 				Tables: tablesRes.Tables,
 			}); err != nil {
+				unlock()
 				return err
 			}
 		}
+		unlock()
 		migrateTimeTook := time.Since(migrateStart)
 		fmt.Printf("Migration completed successfully.\n")
 		log.Info().
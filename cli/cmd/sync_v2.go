@@ -55,7 +55,10 @@ func transformSourceV2DestV3Schemas(originalSchemas [][]byte, recordTransformer
 		if err != nil {
 			return nil, err
 		}
-		transformedSchema := recordTransformer.TransformSchema(schema)
+		transformedSchema, err := recordTransformer.TransformSchema(schema)
+		if err != nil {
+			return nil, err
+		}
 		transformedSchemaBytes, err := pluginv3.SchemaToBytes(transformedSchema)
 		if err != nil {
 			return nil, err
@@ -82,7 +85,7 @@ func transformSourceV2DestV3Resource(originalResourceBytes []byte, recordTransfo
 }
 
 // nolint:dupl
-func syncConnectionV2(ctx context.Context, sourceClient *managedplugin.Client, destinationsClients managedplugin.Clients, sourceSpec specs.Source, destinationSpecs []specs.Destination, uid string, noMigrate bool, destinationsVersions [][]int) error {
+func syncConnectionV2(ctx context.Context, sourceClient *managedplugin.Client, destinationsClients managedplugin.Clients, sourceSpec specs.Source, destinationSpecs []specs.Destination, uid string, noMigrate bool, destinationsVersions [][]int, locks migrationLocks) error {
 	var mt metrics.Metrics
 	var exitReason = ExitReasonStopped
 	defer func() {
@@ -146,13 +149,16 @@ func syncConnectionV2(ctx context.Context, sourceClient *managedplugin.Client, d
 	if !noMigrate {
 		migrateStart := time.Now().UTC()
 		fmt.Printf("Starting migration for: %s -> %s\n", sourceSpec.VersionString(), destinationStrings)
+		unlock := locks.lock(destinationSpecs)
 		for i := range destinationsClients {
 			if _, err := destinationsPbClients[i].Migrate(ctx, &destination.Migrate_Request{
 				Tables: transformedSchemasBytes[i],
 			}); err != nil {
+				unlock()
 				return err
 			}
 		}
+		unlock()
 		migrateTimeTook := time.Since(migrateStart)
 		fmt.Printf("Migration completed successfully.\n")
 		log.Info().
@@ -14,11 +14,13 @@ import (
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/cloudquery/cloudquery-api-go/auth"
 	"github.com/cloudquery/cloudquery/cli/internal/api"
+	"github.com/cloudquery/cloudquery/cli/internal/filter"
 	"github.com/cloudquery/cloudquery/cli/internal/specs/v0"
 	"github.com/cloudquery/cloudquery/cli/internal/transformer"
 	"github.com/cloudquery/plugin-pb-go/managedplugin"
 	"github.com/cloudquery/plugin-pb-go/metrics"
 	"github.com/cloudquery/plugin-pb-go/pb/plugin/v3"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
 	"github.com/rs/zerolog/log"
 	"github.com/schollz/progressbar/v3"
 	"github.com/vnteamopen/godebouncer"
@@ -28,6 +30,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// sourceProgressInterval is how often each source prints its progress when sources are synced in parallel
+const sourceProgressInterval = 10 * time.Second
+
 type v3source struct {
 	client *managedplugin.Client
 	spec   specs.Source
@@ -52,13 +57,18 @@ func getProgressAPIClient() (*cloudquery_api.ClientWithResponses, error) {
 }
 
 // nolint:dupl
-func syncConnectionV3(ctx context.Context, source v3source, destinations []v3destination, backend *v3destination, uid string, noMigrate bool, summaryLocation string) error {
+func syncConnectionV3(ctx context.Context, source v3source, destinations []v3destination, backend *v3destination, uid string, noMigrate bool, summaryLocation string, parallel bool, dryRun bool, locks migrationLocks) error {
 	var mt metrics.Metrics
 	var exitReason = ExitReasonStopped
 	tablesForDeleteStale := make(map[string]bool, 0)
 
 	sourceSpec := source.spec
 	sourceClient := source.client
+	if dryRun {
+		// the state backend isn't used in a dry run, so the next sync still fetches everything the dry run fetched
+		sourceSpec.BackendOptions = nil
+		backend = nil
+	}
 	destinationSpecs := make([]specs.Destination, len(destinations))
 	destinationsClients := make([]*managedplugin.Client, len(destinations))
 	for i := range destinations {
@@ -66,9 +76,13 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 		destinationsClients[i] = destinations[i].client
 	}
 
-	progressAPIClient, err := getProgressAPIClient()
-	if err != nil {
-		return fmt.Errorf("failed to get API client: %w", err)
+	var progressAPIClient *cloudquery_api.ClientWithResponses
+	if !dryRun {
+		var err error
+		progressAPIClient, err = getProgressAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to get API client: %w", err)
+		}
 	}
 
 	defer func() {
@@ -95,6 +109,7 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 	sourcePbClient := plugin.NewPluginClient(sourceClient.Conn)
 	destinationsPbClients := make([]plugin.PluginClient, len(destinationsClients))
 	destinationTransformers := make([]*transformer.RecordTransformer, len(destinationsClients))
+	destinationFilters := make([]*filter.TableFilters, len(destinationsClients))
 	backendPbClient := plugin.PluginClient(nil)
 	for i := range destinationsClients {
 		destinationsPbClients[i] = plugin.NewPluginClient(destinationsClients[i].Conn)
@@ -114,7 +129,15 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 			opts = append(opts, transformer.WithRemovePKs())
 			opts = append(opts, transformer.WithCQIDPrimaryKey())
 		}
+		if len(destinationSpecs[i].Transformations) > 0 {
+			opts = append(opts, transformer.WithTransformations(destinationSpecs[i].Transformations...), transformer.WithInternalTables(syncSummaryTableName))
+		}
 		destinationTransformers[i] = transformer.NewRecordTransformer(opts...)
+		tableFilters, err := filter.NewTableFilters(destinationSpecs[i].Filter)
+		if err != nil {
+			return fmt.Errorf("destination %s: %w", destinationSpecs[i].Name, err)
+		}
+		destinationFilters[i] = tableFilters
 		connection := destinationsClients[i].ConnectionString()
 		variables.Plugins[destinationSpecs[i].Name] = specs.PluginVariables{
 			Connection: connection,
@@ -128,7 +151,8 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 		}
 	}
 
-	// initialize destinations first, so that their connections may be used as backends by the source
+	// initialize destinations first, so that their connections may be used as backends by the source.
+	// Destinations are initialized in a dry run too, as they report their existing tables for the migration plan.
 	for i, destinationSpec := range destinationSpecs {
 		if err := initPlugin(ctx, destinationsPbClients[i], destinationSpec.Spec, false, uid); err != nil {
 			return fmt.Errorf("failed to init destination %v: %w", destinationSpec.Name, err)
@@ -158,8 +182,27 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 		return fmt.Errorf("failed to init source %v: %w", sourceSpec.Name, err)
 	}
 
+	// Sources synced in parallel migrate the tables of a shared destination one at a time, so the tables are
+	// migrated in a stream of their own before the sync starts, releasing the lock once the destination is done.
+	// The migrations are still sent in the sync stream, as destinations keep the state of the migrated tables per stream,
+	// but by then they leave the tables as they are.
+	if locks != nil && !noMigrate && !dryRun {
+		unlock := locks.lock(destinationSpecs)
+		err := migrateTablesV3(ctx, sourcePbClient, sourceSpec, destinationsPbClients, destinationTransformers, destinationSpecs)
+		unlock()
+		if err != nil {
+			return fmt.Errorf("failed to migrate tables: %w", err)
+		}
+	}
+
+	// in a dry run nothing is written: destinations only collect the tables and rows they would have received
 	writeClients := make([]plugin.Plugin_WriteClient, len(destinationsPbClients))
+	dryRunDestinations := make([]*dryRunDestination, len(destinationsPbClients))
 	for i := range destinationsPbClients {
+		if dryRun {
+			dryRunDestinations[i] = newDryRunDestination()
+			continue
+		}
 		writeClients[i], err = destinationsPbClients[i].Write(ctx)
 		if err != nil {
 			return err
@@ -167,7 +210,11 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 	}
 
 	log.Info().Str("source", sourceSpec.VersionString()).Strs("destinations", destinationStrings).Msg("Start fetching resources")
-	fmt.Printf("Starting sync for: %s -> %s\n", sourceSpec.VersionString(), destinationStrings)
+	if dryRun {
+		fmt.Printf("Starting dry run sync for: %s -> %s\n", sourceSpec.VersionString(), destinationStrings)
+	} else {
+		fmt.Printf("Starting sync for: %s -> %s\n", sourceSpec.VersionString(), destinationStrings)
+	}
 
 	syncReq := &plugin.Sync_Request{
 		Tables:              sourceSpec.Tables,
@@ -186,6 +233,12 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 		return err
 	}
 
+	// Read from the sync stream and write to all destinations.
+	totalResources := int64(0)
+	isComplete := int64(0)
+
+	// Several progress bars can't share the terminal, so when sources are synced in parallel
+	// the bar is hidden and each source periodically prints its own progress line instead.
 	bar := progressbar.NewOptions(-1,
 		progressbar.OptionSetDescription("Syncing resources..."),
 		progressbar.OptionSetItsString("resources"),
@@ -193,6 +246,7 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 		progressbar.OptionSetElapsedTime(true),
 		progressbar.OptionShowCount(),
 		progressbar.OptionClearOnFinish(),
+		progressbar.OptionSetVisibility(!parallel),
 	)
 
 	// Add a ticker to update the progress bar every 100ms
@@ -212,13 +266,14 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 			}
 		}
 	}()
+	if parallel {
+		progressDone := make(chan struct{})
+		defer close(progressDone)
+		go reportSourceProgress(ctx, progressDone, sourceName, &totalResources, syncTime)
+	}
 
 	isStateBackendEnabled := sourceSpec.BackendOptions != nil && sourceSpec.BackendOptions.TableName != ""
 
-	// Read from the sync stream and write to all destinations.
-	totalResources := int64(0)
-	isComplete := int64(0)
-
 	var remoteProgressReporter *godebouncer.Debouncer
 	if progressAPIClient != nil {
 		teamName, syncName, syncRunId := os.Getenv("_CQ_TEAM_NAME"), os.Getenv("_CQ_SYNC_NAME"), os.Getenv("_CQ_SYNC_RUN_ID")
@@ -281,7 +336,20 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 				remoteProgressReporter.SendSignal()
 			}
 			for i := range destinationsPbClients {
-				transformedRecord := destinationTransformers[i].Transform(record)
+				// filters are evaluated against the record as the source sent it, before any transformation
+				filteredRecord, err := destinationFilters[i].Filter(record)
+				if err != nil {
+					return fmt.Errorf("failed to filter record for destination %s: %w", destinationSpecs[i].Name, err)
+				}
+				if filteredRecord == nil {
+					continue
+				}
+				transformedRecord := destinationTransformers[i].Transform(filteredRecord)
+				filteredRecord.Release()
+				if dryRun {
+					dryRunDestinations[i].insert(transformedRecord)
+					continue
+				}
 				transformedRecordBytes, err := plugin.RecordToBytes(transformedRecord)
 				if err != nil {
 					return fmt.Errorf("failed to transform record bytes: %w", err)
@@ -297,13 +365,23 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 				}
 			}
 		case *plugin.Sync_Response_DeleteRecord:
+			if dryRun {
+				continue
+			}
 			for i := range destinationsPbClients {
 				wr := &plugin.Write_Request{}
-				// Transformations aren't required here because DeleteRecord is only in V3
+				// Only table renames need to be applied here, as the CloudQuery columns aren't part of DeleteRecord
+				tableRelations := make([]*plugin.TableRelation, len(m.DeleteRecord.TableRelations))
+				for j, relation := range m.DeleteRecord.TableRelations {
+					tableRelations[j] = &plugin.TableRelation{
+						TableName:   destinationTransformers[i].TransformTableName(relation.TableName),
+						ParentTable: destinationTransformers[i].TransformTableName(relation.ParentTable),
+					}
+				}
 				wr.Message = &plugin.Write_Request_DeleteRecord{
 					DeleteRecord: &plugin.Write_MessageDeleteRecord{
-						TableName:      m.DeleteRecord.TableName,
-						TableRelations: m.DeleteRecord.TableRelations,
+						TableName:      destinationTransformers[i].TransformTableName(m.DeleteRecord.TableName),
+						TableRelations: tableRelations,
 						WhereClause:    m.DeleteRecord.WhereClause,
 					},
 				}
@@ -321,11 +399,18 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 			if !isStateBackendEnabled || !tableIsIncremental(sc) {
 				tablesForDeleteStale[tableName] = true
 			}
-			if noMigrate {
+			if noMigrate && !dryRun {
 				continue
 			}
 			for i := range destinationsPbClients {
-				transformedSchema := destinationTransformers[i].TransformSchema(sc)
+				transformedSchema, err := destinationTransformers[i].TransformSchema(sc)
+				if err != nil {
+					return fmt.Errorf("destination %s: %w", destinationSpecs[i].Name, err)
+				}
+				if dryRun {
+					dryRunDestinations[i].migrateTable(transformedSchema)
+					continue
+				}
 				transformedSchemaBytes, err := plugin.SchemaToBytes(transformedSchema)
 				if err != nil {
 					return err
@@ -350,6 +435,28 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 	if err != nil {
 		return err
 	}
+	if dryRun {
+		if err := bar.Finish(); err != nil {
+			log.Warn().Err(err).Msg("Failed to finish progress bar")
+		}
+		for i := range destinationsPbClients {
+			var existingTables schema.Tables
+			if !noMigrate {
+				existingTables = getDestinationTables(ctx, destinationsPbClients[i], destinationSpecs[i].Name, dryRunDestinations[i].tables)
+			}
+			plans, err := dryRunDestinations[i].plan(existingTables, destinationSpecs[i], noMigrate)
+			if err != nil {
+				return err
+			}
+			printDryRunPlan(os.Stdout, sourceName, destinationSpecs[i].Name, plans)
+			if _, err := destinationsPbClients[i].Close(ctx, &plugin.Close_Request{}); err != nil {
+				return err
+			}
+		}
+		exitReason = ExitReasonCompleted
+		fmt.Printf("Dry run completed. Resources: %d, Time: %s\n", totalResources, time.Since(syncTime).Truncate(time.Second).String())
+		return nil
+	}
 	totals := sourceClient.Metrics()
 	sourceWarnings := totals.Warnings
 	sourceErrors := totals.Errors
@@ -392,7 +499,7 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 
 	for i := range destinationsClients {
 		if destinationSpecs[i].WriteMode == specs.WriteModeOverwriteDeleteStale {
-			if err := deleteStale(writeClients[i], tablesForDeleteStale, sourceName, syncTime); err != nil {
+			if err := deleteStale(writeClients[i], destinationTransformers[i], tablesForDeleteStale, sourceName, syncTime); err != nil {
 				return err
 			}
 		}
@@ -416,6 +523,9 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 	if totals.Errors > 0 {
 		msg = "Sync completed with errors, see logs for details"
 	}
+	if parallel {
+		msg = fmt.Sprintf("[%s] %s", sourceName, msg)
+	}
 	fmt.Printf("%s. Resources: %d, Errors: %d, Warnings: %d, Time: %s\n", msg, totalResources, totals.Errors, totals.Warnings, syncTimeTook.Truncate(time.Second).String())
 	log.Info().
 		Int64("resources", totalResources).
@@ -432,6 +542,22 @@ func syncConnectionV3(ctx context.Context, source v3source, destinations []v3des
 	return nil
 }
 
+// reportSourceProgress prints the number of resources synced so far by a single source every few seconds, until done is closed
+func reportSourceProgress(ctx context.Context, done <-chan struct{}, sourceName string, totalResources *int64, syncTime time.Time) {
+	t := time.NewTicker(sourceProgressInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-t.C:
+			fmt.Printf("[%s] Syncing resources... %d resources, elapsed %s\n", sourceName, atomic.LoadInt64(totalResources), time.Since(syncTime).Truncate(time.Second).String())
+		}
+	}
+}
+
 func tableNameFromSchema(sc *arrow.Schema) string {
 	tableName, _ := sc.Metadata().GetValue("cq:table_name")
 	return tableName
@@ -442,14 +568,14 @@ func tableIsIncremental(sc *arrow.Schema) bool {
 	return inc == "true"
 }
 
-func deleteStale(client plugin.Plugin_WriteClient, tables map[string]bool, sourceName string, syncTime time.Time) error {
+func deleteStale(client plugin.Plugin_WriteClient, destinationTransformer *transformer.RecordTransformer, tables map[string]bool, sourceName string, syncTime time.Time) error {
 	for tableName := range tables {
 		if err := client.Send(&plugin.Write_Request{
 			Message: &plugin.Write_Request_Delete{
 				Delete: &plugin.Write_MessageDeleteStale{
 					SourceName: sourceName,
 					SyncTime:   timestamppb.New(syncTime),
-					TableName:  tableName,
+					TableName:  destinationTransformer.TransformTableName(tableName),
 				},
 			},
 		}); err != nil {
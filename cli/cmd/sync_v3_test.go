@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/cloudquery/cli/internal/specs/v0"
+	"github.com/cloudquery/plugin-pb-go/managedplugin"
+	"github.com/cloudquery/plugin-pb-go/pb/plugin/v3"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testSyncTable = &schema.Table{
+	Name: "test_table",
+	Columns: schema.ColumnList{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+	},
+}
+
+// testSourceServer is a source plugin sending the migration of a single table, followed by a single row
+type testSourceServer struct {
+	plugin.UnimplementedPluginServer
+}
+
+func (*testSourceServer) Init(context.Context, *plugin.Init_Request) (*plugin.Init_Response, error) {
+	return &plugin.Init_Response{}, nil
+}
+
+func (*testSourceServer) GetTables(context.Context, *plugin.GetTables_Request) (*plugin.GetTables_Response, error) {
+	table, err := plugin.SchemaToBytes(testSyncTable.ToArrowSchema())
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.GetTables_Response{Tables: [][]byte{table}}, nil
+}
+
+func (*testSourceServer) Sync(_ *plugin.Sync_Request, stream plugin.Plugin_SyncServer) error {
+	sc := testSyncTable.ToArrowSchema()
+	table, err := plugin.SchemaToBytes(sc)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&plugin.Sync_Response{Message: &plugin.Sync_Response_MigrateTable{
+		MigrateTable: &plugin.Sync_MessageMigrateTable{Table: table},
+	}}); err != nil {
+		return err
+	}
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).Append(1)
+	record := bldr.NewRecord()
+	defer record.Release()
+	recordBytes, err := plugin.RecordToBytes(record)
+	if err != nil {
+		return err
+	}
+	return stream.Send(&plugin.Sync_Response{Message: &plugin.Sync_Response_Insert{
+		Insert: &plugin.Sync_MessageInsert{Record: recordBytes},
+	}})
+}
+
+func (*testSourceServer) Close(context.Context, *plugin.Close_Request) (*plugin.Close_Response, error) {
+	return &plugin.Close_Response{}, nil
+}
+
+// testDestinationServer is a destination plugin that, like the destinations keeping per-stream state,
+// refuses rows for tables that weren't migrated earlier in the same write stream
+type testDestinationServer struct {
+	plugin.UnimplementedPluginServer
+	inserts *int64
+}
+
+func (*testDestinationServer) Init(context.Context, *plugin.Init_Request) (*plugin.Init_Response, error) {
+	return &plugin.Init_Response{}, nil
+}
+
+func (d *testDestinationServer) Write(stream plugin.Plugin_WriteServer) error {
+	migrated := make(map[string]bool)
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&plugin.Write_Response{})
+		}
+		if err != nil {
+			return err
+		}
+		switch m := req.Message.(type) {
+		case *plugin.Write_Request_MigrateTable:
+			sc, err := plugin.NewSchemaFromBytes(m.MigrateTable.Table)
+			if err != nil {
+				return err
+			}
+			migrated[tableNameFromSchema(sc)] = true
+		case *plugin.Write_Request_Insert:
+			record, err := plugin.NewRecordFromBytes(m.Insert.Record)
+			if err != nil {
+				return err
+			}
+			if tableName := tableNameFromSchema(record.Schema()); !migrated[tableName] {
+				return status.Errorf(codes.FailedPrecondition, "insert into table %s that wasn't migrated in this stream", tableName)
+			}
+			atomic.AddInt64(d.inserts, record.NumRows())
+		}
+	}
+}
+
+func (*testDestinationServer) Close(context.Context, *plugin.Close_Request) (*plugin.Close_Response, error) {
+	return &plugin.Close_Response{}, nil
+}
+
+func serveTestPlugin(t *testing.T, typ managedplugin.PluginType, server plugin.PluginServer) *managedplugin.Client {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	plugin.RegisterPluginServer(s, server)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	client, err := managedplugin.NewClient(context.Background(), typ, managedplugin.Config{
+		Name:     "test",
+		Registry: managedplugin.RegistryGrpc,
+		Path:     lis.Addr().String(),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Terminate()
+	})
+	return client
+}
+
+func TestSyncConnectionV3ParallelMigrateTables(t *testing.T) {
+	destinationSpec := specs.Destination{
+		Metadata:  specs.Metadata{Name: "test-destination"},
+		WriteMode: specs.WriteModeOverwrite,
+	}
+	sources := []*specs.Source{
+		{Metadata: specs.Metadata{Name: "source-1"}, Destinations: []string{destinationSpec.Name}},
+		{Metadata: specs.Metadata{Name: "source-2"}, Destinations: []string{destinationSpec.Name}},
+	}
+	locks := newMigrationLocks([]*specs.Destination{&destinationSpec})
+
+	var inserts int64
+	var out bytes.Buffer
+	results := syncSources(context.Background(), &out, sources, 2, false, func(source *specs.Source) error {
+		src := v3source{client: serveTestPlugin(t, managedplugin.PluginSource, &testSourceServer{}), spec: *source}
+		dests := []v3destination{{
+			client: serveTestPlugin(t, managedplugin.PluginDestination, &testDestinationServer{inserts: &inserts}),
+			spec:   destinationSpec,
+		}}
+		return syncConnectionV3(context.Background(), src, dests, nil, "test-sync", false, "", true, false, locks)
+	})
+	for _, result := range results {
+		require.NoError(t, result.err, result.source)
+		require.Equal(t, sourceSyncSucceeded, result.status, result.source)
+	}
+	require.EqualValues(t, len(sources), atomic.LoadInt64(&inserts))
+}
@@ -0,0 +1,319 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+)
+
+// truth is the result of a predicate, following the three-valued logic of SQL:
+// comparisons with null values are unknown, and so is the negation of an unknown result.
+type truth int8
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+type predicate func(row int) truth
+
+type node interface {
+	bind(record arrow.Record) (predicate, error)
+}
+
+type literalKind int
+
+const (
+	literalString literalKind = iota
+	literalNumber
+	literalBool
+)
+
+type literal struct {
+	kind literalKind
+	str  string
+	num  float64
+	b    bool
+	// integer numbers are also kept as int64 and uint64 (when they fit), as float64 can't represent all 64-bit integers
+	isInt  bool
+	i      int64
+	isUint bool
+	u      uint64
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n *andNode) bind(record arrow.Record) (predicate, error) {
+	left, err := n.left.bind(record)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.bind(record)
+	if err != nil {
+		return nil, err
+	}
+	return func(row int) truth {
+		l, r := left(row), right(row)
+		switch {
+		case l == truthFalse || r == truthFalse:
+			return truthFalse
+		case l == truthTrue && r == truthTrue:
+			return truthTrue
+		}
+		return truthUnknown
+	}, nil
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n *orNode) bind(record arrow.Record) (predicate, error) {
+	left, err := n.left.bind(record)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.bind(record)
+	if err != nil {
+		return nil, err
+	}
+	return func(row int) truth {
+		l, r := left(row), right(row)
+		switch {
+		case l == truthTrue || r == truthTrue:
+			return truthTrue
+		case l == truthFalse && r == truthFalse:
+			return truthFalse
+		}
+		return truthUnknown
+	}, nil
+}
+
+type notNode struct {
+	node node
+}
+
+func (n *notNode) bind(record arrow.Record) (predicate, error) {
+	p, err := n.node.bind(record)
+	if err != nil {
+		return nil, err
+	}
+	return func(row int) truth {
+		switch t := p(row); t {
+		case truthTrue:
+			return truthFalse
+		case truthFalse:
+			return truthTrue
+		default:
+			return t
+		}
+	}, nil
+}
+
+type isNullNode struct {
+	column string
+	negate bool
+}
+
+func (n *isNullNode) bind(record arrow.Record) (predicate, error) {
+	arr := column(record, n.column)
+	if arr == nil {
+		// missing columns are null
+		return func(int) truth { return truthOf(!n.negate) }, nil
+	}
+	return func(row int) truth { return truthOf(arr.IsNull(row) != n.negate) }, nil
+}
+
+type compareNode struct {
+	column string
+	op     string
+	value  literal
+}
+
+func (n *compareNode) bind(record arrow.Record) (predicate, error) {
+	arr := column(record, n.column)
+	if arr == nil {
+		return unknown, nil
+	}
+	cmp, err := comparer(arr, n.column, n.value)
+	if err != nil {
+		return nil, err
+	}
+	return func(row int) truth {
+		if arr.IsNull(row) {
+			return truthUnknown
+		}
+		c := cmp(row)
+		switch n.op {
+		case "==":
+			return truthOf(c == 0)
+		case "!=":
+			return truthOf(c != 0)
+		case "<":
+			return truthOf(c < 0)
+		case "<=":
+			return truthOf(c <= 0)
+		case ">":
+			return truthOf(c > 0)
+		case ">=":
+			return truthOf(c >= 0)
+		}
+		return truthFalse
+	}, nil
+}
+
+type inNode struct {
+	column string
+	values []literal
+	negate bool
+}
+
+func (n *inNode) bind(record arrow.Record) (predicate, error) {
+	arr := column(record, n.column)
+	if arr == nil {
+		return unknown, nil
+	}
+	cmps := make([]func(row int) int, len(n.values))
+	for i, value := range n.values {
+		var err error
+		if cmps[i], err = comparer(arr, n.column, value); err != nil {
+			return nil, err
+		}
+	}
+	return func(row int) truth {
+		if arr.IsNull(row) {
+			return truthUnknown
+		}
+		for _, cmp := range cmps {
+			if cmp(row) == 0 {
+				return truthOf(!n.negate)
+			}
+		}
+		return truthOf(n.negate)
+	}, nil
+}
+
+func unknown(int) truth {
+	return truthUnknown
+}
+
+// column returns the column of the record, or nil if the record doesn't have it.
+// A filter on a glob pattern may reference columns only some of the matching tables have,
+// so the missing columns are evaluated as null instead of failing the sync.
+func column(record arrow.Record, name string) arrow.Array {
+	indices := record.Schema().FieldIndices(name)
+	if len(indices) == 0 {
+		return nil
+	}
+	return record.Column(indices[0])
+}
+
+// comparer returns a function comparing the value of the row with the literal, returning -1, 0 or 1
+func comparer(arr arrow.Array, columnName string, value literal) (func(row int) int, error) {
+	switch value.kind {
+	case literalString:
+		switch a := arr.(type) {
+		case *array.String:
+			return func(row int) int { return strings.Compare(a.Value(row), value.str) }, nil
+		case *array.LargeString:
+			return func(row int) int { return strings.Compare(a.Value(row), value.str) }, nil
+		default:
+			return func(row int) int { return strings.Compare(a.ValueStr(row), value.str) }, nil
+		}
+	case literalNumber:
+		cmp, ok := numberComparer(arr, value)
+		if !ok {
+			return nil, fmt.Errorf("column %s of type %s can't be compared with a number", columnName, arr.DataType())
+		}
+		return cmp, nil
+	case literalBool:
+		a, ok := arr.(*array.Boolean)
+		if !ok {
+			return nil, fmt.Errorf("column %s of type %s can't be compared with a boolean", columnName, arr.DataType())
+		}
+		return func(row int) int {
+			if a.Value(row) == value.b {
+				return 0
+			}
+			return 1
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported value for column %s", columnName)
+}
+
+// numberComparer returns a function comparing the value of the row with the number.
+// Integer columns are compared with integer literals exactly, and as float64 only with the other literals.
+func numberComparer(arr arrow.Array, value literal) (func(row int) int, bool) {
+	switch a := arr.(type) {
+	case *array.Int8:
+		return intComparer(func(row int) int64 { return int64(a.Value(row)) }, value), true
+	case *array.Int16:
+		return intComparer(func(row int) int64 { return int64(a.Value(row)) }, value), true
+	case *array.Int32:
+		return intComparer(func(row int) int64 { return int64(a.Value(row)) }, value), true
+	case *array.Int64:
+		return intComparer(a.Value, value), true
+	case *array.Uint8:
+		return uintComparer(func(row int) uint64 { return uint64(a.Value(row)) }, value), true
+	case *array.Uint16:
+		return uintComparer(func(row int) uint64 { return uint64(a.Value(row)) }, value), true
+	case *array.Uint32:
+		return uintComparer(func(row int) uint64 { return uint64(a.Value(row)) }, value), true
+	case *array.Uint64:
+		return uintComparer(a.Value, value), true
+	case *array.Float32:
+		return floatComparer(func(row int) float64 { return float64(a.Value(row)) }, value), true
+	case *array.Float64:
+		return floatComparer(a.Value, value), true
+	}
+	return nil, false
+}
+
+func intComparer(v func(row int) int64, value literal) func(row int) int {
+	switch {
+	case value.isInt:
+		return func(row int) int { return compareOrdered(v(row), value.i) }
+	case value.isUint:
+		// the literal is larger than any int64
+		return func(int) int { return -1 }
+	}
+	return floatComparer(func(row int) float64 { return float64(v(row)) }, value)
+}
+
+func uintComparer(v func(row int) uint64, value literal) func(row int) int {
+	switch {
+	case value.isUint:
+		return func(row int) int { return compareOrdered(v(row), value.u) }
+	case value.isInt:
+		// the literal is negative
+		return func(int) int { return 1 }
+	}
+	return floatComparer(func(row int) float64 { return float64(v(row)) }, value)
+}
+
+func floatComparer(v func(row int) float64, value literal) func(row int) int {
+	return func(row int) int { return compareOrdered(v(row), value.num) }
+}
+
+// compareOrdered returns -1, 0 or 1. Unlike cmp.Compare, NaN values are neither less nor greater than any number.
+func compareOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
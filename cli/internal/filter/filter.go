@@ -0,0 +1,187 @@
+// Package filter implements the row filter expressions of the destination spec.
+//
+// An expression compares columns with constant values, e.g. `region == 'us-east-1' AND deleted_at IS NULL`.
+// Supported operators are `==` (or `=`), `!=` (or `<>`), `<`, `<=`, `>`, `>=`, `IN`, `NOT IN`, `IS NULL` and `IS NOT NULL`,
+// combined with `AND`, `OR`, `NOT` and parentheses.
+//
+// As in SQL, comparisons with null values are neither true nor false, and neither is their negation,
+// so neither `x == 1` nor `NOT (x == 1)` match the rows where `x` is null. Only the rows the expression is true for match.
+// Columns missing from a table are null, so that an expression can be shared by tables with different columns.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/glob"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// Expression is a parsed filter expression
+type Expression struct {
+	expr string
+	root node
+}
+
+// Parse parses a filter expression
+func Parse(expr string) (*Expression, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, errors.New("empty expression")
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, unexpected(t, "AND, OR or end of expression")
+	}
+	return &Expression{expr: expr, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.expr
+}
+
+// Match returns the rows of the record that match the expression
+func (e *Expression) Match(record arrow.Record) ([]bool, error) {
+	predicate, err := e.root.bind(record)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]bool, record.NumRows())
+	for i := range matches {
+		matches[i] = predicate(i) == truthTrue
+	}
+	return matches, nil
+}
+
+// Filter returns a record with only the rows that match all the expressions.
+// The record is returned as is if all rows match, and nil is returned if none do.
+// The returned record is retained, so the caller has to release it.
+func Filter(record arrow.Record, expressions ...*Expression) (arrow.Record, error) {
+	if len(expressions) == 0 {
+		record.Retain()
+		return record, nil
+	}
+	matches := make([]bool, record.NumRows())
+	for i := range matches {
+		matches[i] = true
+	}
+	for _, e := range expressions {
+		m, err := e.Match(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate filter %q: %w", e, err)
+		}
+		for i := range matches {
+			matches[i] = matches[i] && m[i]
+		}
+	}
+	return selectRows(record, matches)
+}
+
+// selectRows builds a record from the runs of matching rows
+func selectRows(record arrow.Record, matches []bool) (arrow.Record, error) {
+	var slices []arrow.Record
+	defer func() {
+		for _, s := range slices {
+			s.Release()
+		}
+	}()
+	for i := 0; i < len(matches); {
+		if !matches[i] {
+			i++
+			continue
+		}
+		start := i
+		for i < len(matches) && matches[i] {
+			i++
+		}
+		if start == 0 && i == len(matches) {
+			record.Retain()
+			return record, nil
+		}
+		slices = append(slices, record.NewSlice(int64(start), int64(i)))
+	}
+	switch len(slices) {
+	case 0:
+		return nil, nil
+	case 1:
+		slices[0].Retain()
+		return slices[0], nil
+	}
+
+	columns := make([]arrow.Array, record.NumCols())
+	for c := range columns {
+		arrs := make([]arrow.Array, len(slices))
+		for s := range slices {
+			arrs[s] = slices[s].Column(c)
+		}
+		col, err := array.Concatenate(arrs, memory.DefaultAllocator)
+		if err != nil {
+			return nil, err
+		}
+		defer col.Release()
+		columns[c] = col
+	}
+	return array.NewRecord(record.Schema(), columns, int64(columns[0].Len())), nil
+}
+
+// TableFilters holds the filter expressions of a destination, keyed by table name or glob pattern
+type TableFilters struct {
+	patterns    []string
+	expressions map[string]*Expression
+	byTable     map[string][]*Expression
+}
+
+// NewTableFilters parses the filter expressions for each table name or glob pattern
+func NewTableFilters(filters map[string]string) (*TableFilters, error) {
+	t := &TableFilters{
+		expressions: make(map[string]*Expression, len(filters)),
+		byTable:     make(map[string][]*Expression),
+	}
+	for pattern, expr := range filters {
+		e, err := Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter for %s: %w", pattern, err)
+		}
+		t.patterns = append(t.patterns, pattern)
+		t.expressions[pattern] = e
+	}
+	sort.Strings(t.patterns)
+	return t, nil
+}
+
+// ForTable returns the expressions of all the patterns matching the table name
+func (t *TableFilters) ForTable(tableName string) []*Expression {
+	if expressions, ok := t.byTable[tableName]; ok {
+		return expressions
+	}
+	var expressions []*Expression
+	for _, pattern := range t.patterns {
+		if glob.Glob(pattern, tableName) {
+			expressions = append(expressions, t.expressions[pattern])
+		}
+	}
+	t.byTable[tableName] = expressions
+	return expressions
+}
+
+// Filter returns a record with only the rows matching the filters of its table, or nil if no rows match.
+// The returned record is retained, so the caller has to release it.
+func (t *TableFilters) Filter(record arrow.Record) (arrow.Record, error) {
+	tableName, _ := record.Schema().Metadata().GetValue(schema.MetadataTableName)
+	filtered, err := Filter(record, t.ForTable(tableName)...)
+	if err != nil {
+		return nil, fmt.Errorf("table %s: %w", tableName, err)
+	}
+	return filtered, nil
+}
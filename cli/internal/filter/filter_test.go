@@ -0,0 +1,194 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecord(t *testing.T, tableName string) arrow.Record {
+	t.Helper()
+	md := arrow.NewMetadata([]string{schema.MetadataTableName}, []string{tableName})
+	sc := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "region", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "deleted_at", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true},
+		{Name: "enabled", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+	}, &md)
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3, 4}, nil)
+	bldr.Field(1).(*array.StringBuilder).AppendValues([]string{"us-east-1", "eu-west-1", "us-east-1", ""}, []bool{true, true, true, false})
+	bldr.Field(2).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{0, 1, 0, 0}, []bool{false, true, false, false})
+	bldr.Field(3).(*array.BooleanBuilder).AppendValues([]bool{true, false, false, true}, nil)
+	return bldr.NewRecord()
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		expr string
+		err  string
+	}{
+		{expr: "region == 'us-east-1'"},
+		{expr: "region = 'it''s'"},
+		{expr: "deleted_at IS NULL AND (id > 2 OR NOT enabled == true)"},
+		{expr: "region NOT IN ('us-east-1', 'eu-west-1')"},
+		{expr: "id >= -1.5e3"},
+		{expr: "", err: "empty expression"},
+		{expr: "region ==", err: "expected value at end of expression"},
+		{expr: "region == 'us-east-1", err: "unterminated string starting at position 10"},
+		{expr: "region IS 'a'", err: `expected NULL at position 10, got "a"`},
+		{expr: "enabled > true", err: "operator > can't be used with boolean value at position 8"},
+		{expr: "id == 1 id == 2", err: `expected AND, OR or end of expression at position 8, got "id"`},
+		{expr: "(id == 1", err: "expected ')' at end of expression"},
+		{expr: "id ! 1", err: "unexpected character '!' at position 3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := Parse(tc.expr)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestExpressionMatch(t *testing.T) {
+	record := testRecord(t, "test_table")
+	cases := []struct {
+		expr string
+		want []bool
+		err  string
+	}{
+		{expr: "region == 'us-east-1'", want: []bool{true, false, true, false}},
+		{expr: "region != 'us-east-1'", want: []bool{false, true, false, false}},
+		{expr: "region IS NULL", want: []bool{false, false, false, true}},
+		{expr: "deleted_at IS NULL", want: []bool{true, false, true, true}},
+		{expr: "deleted_at is not null", want: []bool{false, true, false, false}},
+		{expr: "id > 2", want: []bool{false, false, true, true}},
+		{expr: "id <= 2 AND region == 'us-east-1'", want: []bool{true, false, false, false}},
+		{expr: "id == 1 OR enabled == true", want: []bool{true, false, false, true}},
+		{expr: "NOT (id IN (1, 2))", want: []bool{false, false, true, true}},
+		{expr: "region NOT IN ('us-east-1')", want: []bool{false, true, false, false}},
+		{expr: "NOT (region == 'us-east-1')", want: []bool{false, true, false, false}},
+		{expr: "NOT (region == 'us-east-1' OR id == 4)", want: []bool{false, true, false, false}},
+		{expr: "region == 'us-east-1' OR id == 4", want: []bool{true, false, true, true}},
+		{expr: "missing == 1", want: []bool{false, false, false, false}},
+		{expr: "NOT (missing IN (1, 2))", want: []bool{false, false, false, false}},
+		{expr: "missing IS NULL", want: []bool{true, true, true, true}},
+		{expr: "missing IS NOT NULL OR id == 1", want: []bool{true, false, false, false}},
+		{expr: "region > 1", err: "column region of type utf8 can't be compared with a number"},
+		{expr: "id == true", err: "column id of type int64 can't be compared with a boolean"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := Parse(tc.expr)
+			require.NoError(t, err)
+			got, err := e.Match(record)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestExpressionMatchIntegers(t *testing.T) {
+	sc := arrow.NewSchema([]arrow.Field{
+		{Name: "signed", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "unsigned", Type: arrow.PrimitiveTypes.Uint64},
+		{Name: "float", Type: arrow.PrimitiveTypes.Float64},
+	}, nil)
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+	defer bldr.Release()
+	// 2^53 + 1 isn't representable as a float64, and is rounded to 2^53
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{9007199254740993, 9007199254740992, -1}, nil)
+	bldr.Field(1).(*array.Uint64Builder).AppendValues([]uint64{18446744073709551615, 18446744073709551614, 0}, nil)
+	bldr.Field(2).(*array.Float64Builder).AppendValues([]float64{0.5, 1, 1.5}, nil)
+	record := bldr.NewRecord()
+	defer record.Release()
+
+	cases := []struct {
+		expr string
+		want []bool
+	}{
+		{expr: "signed == 9007199254740993", want: []bool{true, false, false}},
+		{expr: "signed > 9007199254740992", want: []bool{true, false, false}},
+		{expr: "signed < 9223372036854775808", want: []bool{true, true, true}},
+		{expr: "signed >= -1.5", want: []bool{true, true, true}},
+		{expr: "unsigned == 18446744073709551615", want: []bool{true, false, false}},
+		{expr: "unsigned < 18446744073709551615", want: []bool{false, true, true}},
+		{expr: "unsigned > -1", want: []bool{true, true, true}},
+		{expr: "float > 1", want: []bool{false, false, true}},
+		{expr: "float IN (0.5, 1)", want: []bool{true, true, false}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := Parse(tc.expr)
+			require.NoError(t, err)
+			got, err := e.Match(record)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTableFilters(t *testing.T) {
+	filters, err := NewTableFilters(map[string]string{
+		"test_*":     "deleted_at IS NULL",
+		"test_table": "id != 3",
+	})
+	require.NoError(t, err)
+
+	record := testRecord(t, "test_table")
+	filtered, err := filters.Filter(record)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, filtered.NumRows())
+	require.Equal(t, []int64{1, 4}, filtered.Column(0).(*array.Int64).Int64Values())
+	require.True(t, filtered.Schema().Equal(record.Schema()))
+
+	// all rows match
+	other := testRecord(t, "other_table")
+	filtered, err = filters.Filter(other)
+	require.NoError(t, err)
+	require.Equal(t, other, filtered)
+
+	// no rows match
+	filters, err = NewTableFilters(map[string]string{"*": "id > 10"})
+	require.NoError(t, err)
+	filtered, err = filters.Filter(record)
+	require.NoError(t, err)
+	require.Nil(t, filtered)
+
+	_, err = NewTableFilters(map[string]string{"test_table": "id =="})
+	require.EqualError(t, err, "invalid filter for test_table: expected value at end of expression")
+}
+
+func TestFilterRelease(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	md := arrow.NewMetadata([]string{schema.MetadataTableName}, []string{"test_table"})
+	sc := arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.PrimitiveTypes.Int64}}, &md)
+	bldr := array.NewRecordBuilder(mem, sc)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3, 4}, nil)
+	record := bldr.NewRecord()
+
+	for _, expr := range []string{"id > 0", "id != 3", "id > 2"} {
+		e, err := Parse(expr)
+		require.NoError(t, err)
+		filtered, err := Filter(record, e)
+		require.NoError(t, err)
+		filtered.Release()
+	}
+	record.Release()
+}
@@ -0,0 +1,303 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, value: ",", pos: i})
+			i++
+		case c == '\'':
+			var sb strings.Builder
+			start := i
+			i++
+			for {
+				if i >= len(s) {
+					return nil, fmt.Errorf("unterminated string starting at position %d", start)
+				}
+				if s[i] == '\'' {
+					// '' is an escaped quote
+					if i+1 < len(s) && s[i+1] == '\'' {
+						sb.WriteByte('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(s[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, value: sb.String(), pos: start})
+		case c == '-' || c == '.' || unicode.IsDigit(c):
+			start := i
+			i++
+			for i < len(s) && (unicode.IsDigit(rune(s[i])) || s[i] == '.' || s[i] == 'e' || s[i] == 'E' ||
+				((s[i] == '-' || s[i] == '+') && (s[i-1] == 'e' || s[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: s[start:i], pos: start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(s) && (s[i] == '_' || unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: s[start:i], pos: start})
+		case strings.ContainsRune("=!<>", c):
+			start := i
+			i++
+			if i < len(s) && (s[i] == '=' || (c == '<' && s[i] == '>')) {
+				i++
+			}
+			op := s[start:i]
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, start)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: op, pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(s)}), nil
+}
+
+// parser is a recursive descent parser for the following grammar:
+//
+//	expr       = and { OR and }
+//	and        = not { AND not }
+//	not        = NOT not | primary
+//	primary    = "(" expr ")" | column IS [NOT] NULL | column [NOT] IN "(" literal { "," literal } ")" | column op literal
+//	op         = "==" | "=" | "!=" | "<>" | "<" | "<=" | ">" | ">="
+//	literal    = 'string' | number | TRUE | FALSE
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.value, keyword)
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.isKeyword(keyword) {
+		return unexpected(p.peek(), keyword)
+	}
+	p.next()
+	return nil
+}
+
+func unexpected(t token, expected string) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("expected %s at end of expression", expected)
+	}
+	return fmt.Errorf("expected %s at position %d, got %q", expected, t.pos, t.value)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node: n}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	if t.kind == tokenLParen {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, unexpected(t, "')'")
+		}
+		return n, nil
+	}
+	if t.kind != tokenIdent || isReserved(t.value) {
+		return nil, unexpected(t, "column name")
+	}
+	column := t.value
+
+	switch {
+	case p.isKeyword("IS"):
+		p.next()
+		negate := false
+		if p.isKeyword("NOT") {
+			p.next()
+			negate = true
+		}
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullNode{column: column, negate: negate}, nil
+	case p.isKeyword("NOT"), p.isKeyword("IN"):
+		negate := false
+		if p.isKeyword("NOT") {
+			p.next()
+			negate = true
+		}
+		if err := p.expectKeyword("IN"); err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenLParen {
+			return nil, unexpected(t, "'('")
+		}
+		var values []literal
+		for {
+			value, err := p.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			t := p.next()
+			if t.kind == tokenRParen {
+				break
+			}
+			if t.kind != tokenComma {
+				return nil, unexpected(t, "',' or ')'")
+			}
+		}
+		return &inNode{column: column, values: values, negate: negate}, nil
+	}
+
+	opToken := p.next()
+	if opToken.kind != tokenOperator {
+		return nil, unexpected(opToken, "operator")
+	}
+	op := opToken.value
+	switch op {
+	case "=":
+		op = "=="
+	case "<>":
+		op = "!="
+	}
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if value.kind == literalBool && op != "==" && op != "!=" {
+		return nil, fmt.Errorf("operator %s can't be used with boolean value at position %d", opToken.value, opToken.pos)
+	}
+	return &compareNode{column: column, op: op, value: value}, nil
+}
+
+func (p *parser) parseLiteral() (literal, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return literal{kind: literalString, str: t.value}, nil
+	case tokenNumber:
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return literal{}, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+		l := literal{kind: literalNumber, num: f}
+		if i, err := strconv.ParseInt(t.value, 10, 64); err == nil {
+			l.isInt, l.i = true, i
+			l.isUint, l.u = i >= 0, uint64(i)
+		} else if u, err := strconv.ParseUint(t.value, 10, 64); err == nil {
+			l.isUint, l.u = true, u
+		}
+		return l, nil
+	case tokenIdent:
+		switch {
+		case strings.EqualFold(t.value, "TRUE"):
+			return literal{kind: literalBool, b: true}, nil
+		case strings.EqualFold(t.value, "FALSE"):
+			return literal{kind: literalBool, b: false}, nil
+		}
+	}
+	return literal{}, unexpected(t, "value")
+}
+
+func isReserved(s string) bool {
+	switch strings.ToUpper(s) {
+	case "AND", "OR", "NOT", "IS", "NULL", "IN", "TRUE", "FALSE":
+		return true
+	}
+	return false
+}
@@ -3,8 +3,11 @@ package specs
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
+
+	"github.com/cloudquery/cloudquery/cli/internal/filter"
 )
 
 const (
@@ -32,6 +35,13 @@ type Destination struct {
 
 	SyncSummary bool `json:"send_sync_summary,omitempty"`
 
+	// Transformations applied to the tables and records before they are sent to the destination
+	Transformations []Transformation `json:"transformations,omitempty"`
+
+	// Row filters, keyed by table name (glob patterns are supported).
+	// Only the rows matching the expression, e.g. `region == 'us-east-1' AND deleted_at IS NULL`, are sent to the destination.
+	Filter map[string]string `json:"filter,omitempty"`
+
 	// Destination plugin own (nested) spec
 	Spec map[string]any `json:"spec,omitempty"`
 }
@@ -60,7 +70,18 @@ func (d *Destination) UnmarshalSpec(out any) error {
 }
 
 func (d *Destination) Validate() error {
-	return d.Metadata.Validate()
+	if err := d.Metadata.Validate(); err != nil {
+		return err
+	}
+	for i := range d.Transformations {
+		if err := d.Transformations[i].Validate(); err != nil {
+			return fmt.Errorf("invalid transformation %d: %w", i, err)
+		}
+	}
+	if _, err := filter.NewTableFilters(d.Filter); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (d *Destination) RenderedSyncGroupId(t time.Time) string {
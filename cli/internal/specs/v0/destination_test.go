@@ -193,6 +193,42 @@ spec:
 			Spec: map[string]any{},
 		},
 	},
+	{
+		"invalid_filter",
+		`kind: destination
+spec:
+  name: test
+  path: cloudquery/test
+  version: v1.1.0
+  filter:
+    aws_ec2_instances: "region == "
+`,
+		"invalid filter for aws_ec2_instances: expected value at end of expression",
+		nil,
+	},
+	{
+		"success filter",
+		`kind: destination
+spec:
+  name: test
+  path: cloudquery/test
+  version: v1.1.0
+  filter:
+    aws_ec2_instances: "region == 'us-east-1'"
+`,
+		"",
+		&Destination{
+			Metadata: Metadata{
+				Name:             "test",
+				Registry:         RegistryCloudQuery,
+				Path:             "cloudquery/test",
+				Version:          "v1.1.0",
+				registryInferred: true,
+			},
+			Filter: map[string]string{"aws_ec2_instances": "region == 'us-east-1'"},
+			Spec:   map[string]any{},
+		},
+	},
 }
 
 func TestDestinationUnmarshalSpecValidate(t *testing.T) {
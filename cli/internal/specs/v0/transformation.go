@@ -0,0 +1,177 @@
+package specs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudquery/plugin-sdk/v4/glob"
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	// DefaultMaskValue is the value used by `mask_columns` transformations when no `value` is set
+	DefaultMaskValue = "*****"
+
+	varTable = "{{TABLE}}"
+)
+
+type TransformationKind int
+
+const (
+	TransformationKindDropColumns TransformationKind = iota
+	TransformationKindRenameColumn
+	TransformationKindRenameTable
+	TransformationKindHashColumns
+	TransformationKindMaskColumns
+	TransformationKindAddColumn
+)
+
+var (
+	AllTransformationKinds = [...]string{
+		TransformationKindDropColumns:  "drop_columns",
+		TransformationKindRenameColumn: "rename_column",
+		TransformationKindRenameTable:  "rename_table",
+		TransformationKindHashColumns:  "hash_columns",
+		TransformationKindMaskColumns:  "mask_columns",
+		TransformationKindAddColumn:    "add_column",
+	}
+)
+
+func (k TransformationKind) String() string {
+	return AllTransformationKinds[k]
+}
+
+func (k TransformationKind) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(k.String())
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+func (k *TransformationKind) UnmarshalJSON(data []byte) (err error) {
+	var kind string
+	if err := json.Unmarshal(data, &kind); err != nil {
+		return err
+	}
+	if *k, err = TransformationKindFromString(kind); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (TransformationKind) JSONSchemaExtend(sc *jsonschema.Schema) {
+	sc.Type = "string"
+	sc.Enum = make([]any, len(AllTransformationKinds))
+	for i, k := range AllTransformationKinds {
+		sc.Enum[i] = k
+	}
+}
+
+func TransformationKindFromString(s string) (TransformationKind, error) {
+	for k, str := range AllTransformationKinds {
+		if s == str {
+			return TransformationKind(k), nil
+		}
+	}
+	return TransformationKindDropColumns, fmt.Errorf("invalid transformation kind: %s", s)
+}
+
+// Transformation is applied by the CLI to the tables and records before they are sent to the destination.
+// Transformations are applied in the order they are defined in.
+type Transformation struct {
+	// Kind of the transformation.
+	// `drop_columns` removes `columns`,
+	// `rename_column` renames `column` to `new_name`,
+	// `rename_table` renames the table to `new_name` (`{{TABLE}}` is replaced with the current table name),
+	// `hash_columns` replaces the values of `columns` with their HMAC-SHA256 hash keyed by `key`,
+	// `mask_columns` replaces the values of `columns` with `value`,
+	// `add_column` adds a `column` with the constant `value`.
+	// Columns changed by `hash_columns` and `mask_columns`, as well as columns added by `add_column`, are strings.
+	Kind TransformationKind `json:"kind" jsonschema:"required"`
+
+	// Tables the transformation applies to. Glob patterns are supported, but don't match the `cloudquery_sync_summaries` table added by the CLI.
+	Tables []string `json:"tables" jsonschema:"required,minItems=1,minLength=1"`
+
+	// Columns to drop, hash or mask
+	Columns []string `json:"columns,omitempty" jsonschema:"minLength=1"`
+
+	// Column to rename or add
+	Column string `json:"column,omitempty"`
+
+	// New name of the renamed column or table
+	NewName string `json:"new_name,omitempty"`
+
+	// Value of the added column, or the value to replace masked values with (defaults to `*****`)
+	Value string `json:"value,omitempty"`
+
+	// Secret key of the HMAC-SHA256 hash of hashed columns. Without a secret key, hashes of guessable values
+	// such as emails or IP addresses can be reversed by hashing candidate values.
+	// Consider setting it from an environment variable, for example `${HASH_KEY}`.
+	Key string `json:"key,omitempty"`
+}
+
+func (t *Transformation) Validate() error {
+	if len(t.Tables) == 0 {
+		return errors.New("tables are required")
+	}
+	switch t.Kind {
+	case TransformationKindDropColumns, TransformationKindMaskColumns:
+		if len(t.Columns) == 0 {
+			return fmt.Errorf("columns are required for %s", t.Kind)
+		}
+	case TransformationKindHashColumns:
+		if len(t.Columns) == 0 {
+			return fmt.Errorf("columns are required for %s", t.Kind)
+		}
+		if t.Key == "" {
+			return fmt.Errorf("key is required for %s", t.Kind)
+		}
+	case TransformationKindRenameColumn:
+		if t.Column == "" || t.NewName == "" {
+			return fmt.Errorf("column and new_name are required for %s", t.Kind)
+		}
+	case TransformationKindRenameTable:
+		if t.NewName == "" {
+			return fmt.Errorf("new_name is required for %s", t.Kind)
+		}
+		if !strings.Contains(t.NewName, varTable) && (len(t.Tables) > 1 || strings.Contains(t.Tables[0], "*")) {
+			return fmt.Errorf("new_name must contain %s for %s of more than one table, so that the tables aren't renamed to the same name", varTable, t.Kind)
+		}
+	case TransformationKindAddColumn:
+		if t.Column == "" {
+			return fmt.Errorf("column is required for %s", t.Kind)
+		}
+	}
+	return nil
+}
+
+// MatchesTable reports whether the transformation applies to the given table
+func (t *Transformation) MatchesTable(tableName string) bool {
+	for _, pattern := range t.Tables {
+		if glob.Glob(pattern, tableName) {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderedTableName returns the new name of a table renamed by a `rename_table` transformation
+func (t *Transformation) RenderedTableName(tableName string) string {
+	return strings.ReplaceAll(t.NewName, varTable, tableName)
+}
+
+// MaskValue returns the value masked columns are replaced with
+func (t *Transformation) MaskValue() string {
+	if t.Value == "" {
+		return DefaultMaskValue
+	}
+	return t.Value
+}
+
+func (Transformation) JSONSchemaExtend(sc *jsonschema.Schema) {
+	tables := sc.Properties.Value("tables")
+	*tables = *tables.OneOf[0] // only value
+}
@@ -0,0 +1,127 @@
+package specs
+
+import (
+	"testing"
+
+	"github.com/cloudquery/codegen/jsonschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformationKindRoundTrip(t *testing.T) {
+	for _, kindStr := range AllTransformationKinds {
+		kind, err := TransformationKindFromString(kindStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kindStr != kind.String() {
+			t.Fatalf("expected:%s got:%s", kindStr, kind.String())
+		}
+	}
+
+	_, err := TransformationKindFromString("Drop_Columns")
+	require.Error(t, err)
+}
+
+func TestTransformation_Validate(t *testing.T) {
+	cases := []struct {
+		name           string
+		transformation Transformation
+		err            string
+	}{
+		{
+			name:           "missing_tables",
+			transformation: Transformation{Kind: TransformationKindDropColumns, Columns: []string{"a"}},
+			err:            "tables are required",
+		},
+		{
+			name:           "drop_columns_missing_columns",
+			transformation: Transformation{Kind: TransformationKindDropColumns, Tables: []string{"*"}},
+			err:            "columns are required for drop_columns",
+		},
+		{
+			name:           "rename_column_missing_new_name",
+			transformation: Transformation{Kind: TransformationKindRenameColumn, Tables: []string{"*"}, Column: "a"},
+			err:            "column and new_name are required for rename_column",
+		},
+		{
+			name:           "rename_table_missing_new_name",
+			transformation: Transformation{Kind: TransformationKindRenameTable, Tables: []string{"*"}},
+			err:            "new_name is required for rename_table",
+		},
+		{
+			name:           "rename_table_of_many_tables_to_a_single_name",
+			transformation: Transformation{Kind: TransformationKindRenameTable, Tables: []string{"aws_*"}, NewName: "aws"},
+			err:            "new_name must contain {{TABLE}} for rename_table of more than one table, so that the tables aren't renamed to the same name",
+		},
+		{
+			name:           "rename_single_table",
+			transformation: Transformation{Kind: TransformationKindRenameTable, Tables: []string{"aws_s3_buckets"}, NewName: "buckets"},
+		},
+		{
+			name:           "hash_columns_missing_key",
+			transformation: Transformation{Kind: TransformationKindHashColumns, Tables: []string{"*"}, Columns: []string{"email"}},
+			err:            "key is required for hash_columns",
+		},
+		{
+			name:           "add_column_missing_column",
+			transformation: Transformation{Kind: TransformationKindAddColumn, Tables: []string{"*"}, Value: "a"},
+			err:            "column is required for add_column",
+		},
+		{
+			name:           "valid_mask_columns",
+			transformation: Transformation{Kind: TransformationKindMaskColumns, Tables: []string{"*"}, Columns: []string{"email"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.transformation.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestTransformation_MatchesTable(t *testing.T) {
+	transformation := Transformation{Tables: []string{"aws_iam_*", "gcp_iam_users"}}
+	require.True(t, transformation.MatchesTable("aws_iam_users"))
+	require.True(t, transformation.MatchesTable("gcp_iam_users"))
+	require.False(t, transformation.MatchesTable("gcp_iam_roles"))
+}
+
+func TestTransformation_JSONSchemaExtend(t *testing.T) {
+	data, err := jsonschema.Generate(new(Transformation))
+	require.NoError(t, err)
+	jsonschema.TestJSONSchema(t, string(data), []jsonschema.TestCase{
+		{
+			Name: "empty",
+			Err:  true,
+			Spec: `{}`,
+		},
+		{
+			Name: "missing tables",
+			Err:  true,
+			Spec: `{"kind":"drop_columns","columns":["a"]}`,
+		},
+		{
+			Name: "null tables",
+			Err:  true,
+			Spec: `{"kind":"drop_columns","tables":null,"columns":["a"]}`,
+		},
+		{
+			Name: "bad kind",
+			Err:  true,
+			Spec: `{"kind":"drop","tables":["*"],"columns":["a"]}`,
+		},
+		{
+			Name: "drop_columns",
+			Spec: `{"kind":"drop_columns","tables":["*"],"columns":["a"]}`,
+		},
+		{
+			Name: "add_column",
+			Spec: `{"kind":"add_column","tables":["*"],"column":"tenant","value":"acme"}`,
+		},
+	})
+}
@@ -0,0 +1,140 @@
+package transformer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/cloudquery/cli/internal/specs/v0"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// applyTransformations applies the user defined transformations to the schema and, unless cols is nil, to the record columns
+func (t *RecordTransformer) applyTransformations(sc *arrow.Schema, cols []arrow.Array, nRows int) (*arrow.Schema, []arrow.Array) {
+	for i := range t.transformations {
+		tr := &t.transformations[i]
+		tableName, _ := sc.Metadata().GetValue(schema.MetadataTableName)
+		if tr.Kind == specs.TransformationKindRenameTable {
+			sc = t.renameParentTable(tr, sc)
+		}
+		if !t.matchesTable(tr, tableName) {
+			continue
+		}
+		sc, cols = applyTransformation(tr, tableName, sc, cols, nRows)
+	}
+	return sc, cols
+}
+
+// matchesTable reports whether the transformation applies to the table.
+// The tables added by the CLI are only matched by their exact name, so that patterns such as `*` don't change them.
+func (t *RecordTransformer) matchesTable(tr *specs.Transformation, tableName string) bool {
+	if slices.Contains(t.internalTables, tableName) {
+		return slices.Contains(tr.Tables, tableName)
+	}
+	return tr.MatchesTable(tableName)
+}
+
+// renameParentTable renames the parent table of child tables, so that they keep referencing it once it's renamed
+func (t *RecordTransformer) renameParentTable(tr *specs.Transformation, sc *arrow.Schema) *arrow.Schema {
+	md := sc.Metadata()
+	parent, ok := md.GetValue(schema.MetadataTableDependsOn)
+	if !ok || parent == "" || !t.matchesTable(tr, parent) {
+		return sc
+	}
+	mdMap := md.ToMap()
+	mdMap[schema.MetadataTableDependsOn] = tr.RenderedTableName(parent)
+	md = arrow.MetadataFrom(mdMap)
+	return arrow.NewSchema(sc.Fields(), &md)
+}
+
+func applyTransformation(tr *specs.Transformation, tableName string, sc *arrow.Schema, cols []arrow.Array, nRows int) (*arrow.Schema, []arrow.Array) {
+	fields := make([]arrow.Field, 0, len(sc.Fields())+1)
+	var newCols []arrow.Array
+	if cols != nil {
+		newCols = make([]arrow.Array, 0, len(cols)+1)
+	}
+	for i, field := range sc.Fields() {
+		var col arrow.Array
+		if cols != nil {
+			col = cols[i]
+		}
+		switch tr.Kind {
+		case specs.TransformationKindDropColumns:
+			if slices.Contains(tr.Columns, field.Name) {
+				continue
+			}
+		case specs.TransformationKindRenameColumn:
+			if field.Name == tr.Column {
+				field.Name = tr.NewName
+			}
+		case specs.TransformationKindHashColumns:
+			if slices.Contains(tr.Columns, field.Name) {
+				field.Type = arrow.BinaryTypes.String
+				if col != nil {
+					col = mapToString(col, hasher(tr.Key))
+				}
+			}
+		case specs.TransformationKindMaskColumns:
+			if slices.Contains(tr.Columns, field.Name) {
+				field.Type = arrow.BinaryTypes.String
+				if col != nil {
+					maskValue := tr.MaskValue()
+					col = mapToString(col, func(string) string { return maskValue })
+				}
+			}
+		}
+		fields = append(fields, field)
+		if cols != nil {
+			newCols = append(newCols, col)
+		}
+	}
+
+	md := sc.Metadata()
+	switch tr.Kind {
+	case specs.TransformationKindAddColumn:
+		if !sc.HasField(tr.Column) {
+			fields = append(fields, arrow.Field{Name: tr.Column, Type: arrow.BinaryTypes.String, Nullable: true})
+			if cols != nil {
+				bldr := array.NewStringBuilder(memory.DefaultAllocator)
+				bldr.Reserve(nRows)
+				for i := 0; i < nRows; i++ {
+					bldr.Append(tr.Value)
+				}
+				newCols = append(newCols, bldr.NewArray())
+			}
+		}
+	case specs.TransformationKindRenameTable:
+		mdMap := md.ToMap()
+		mdMap[schema.MetadataTableName] = tr.RenderedTableName(tableName)
+		md = arrow.MetadataFrom(mdMap)
+	}
+	return arrow.NewSchema(fields, &md), newCols
+}
+
+// mapToString replaces every non-null value in arr with fn applied to its string representation
+func mapToString(arr arrow.Array, fn func(string) string) arrow.Array {
+	bldr := array.NewStringBuilder(memory.DefaultAllocator)
+	bldr.Reserve(arr.Len())
+	for i := 0; i < arr.Len(); i++ {
+		if arr.IsNull(i) {
+			bldr.AppendNull()
+			continue
+		}
+		bldr.Append(fn(arr.ValueStr(i)))
+	}
+	return bldr.NewArray()
+}
+
+// hasher returns a function hashing values with HMAC-SHA256, so that the hashes can't be reversed without the key
+func hasher(key string) func(string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	return func(v string) string {
+		mac.Reset()
+		mac.Write([]byte(v))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
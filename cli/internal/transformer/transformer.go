@@ -1,11 +1,13 @@
 package transformer
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/arrow/array"
 	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/cloudquery/cli/internal/specs/v0"
 	"github.com/cloudquery/plugin-sdk/v4/schema"
 )
 
@@ -27,6 +29,11 @@ type RecordTransformer struct {
 	cqIDPrimaryKey          bool
 	withSyncGroupID         bool
 	syncGroupId             string
+	transformations         []specs.Transformation
+	// internalTables are the tables added by the CLI, which transformations only apply to when listed by their exact name
+	internalTables []string
+	// originalTableNames maps the names of the transformed tables to the names of the tables they were transformed from
+	originalTableNames map[string]string
 }
 
 type RecordTransformerOption func(*RecordTransformer)
@@ -73,6 +80,20 @@ func WithCQIDPrimaryKey() RecordTransformerOption {
 	}
 }
 
+// WithTransformations adds the user defined transformations, which are applied in order after the CloudQuery columns are added
+func WithTransformations(transformations ...specs.Transformation) RecordTransformerOption {
+	return func(transformer *RecordTransformer) {
+		transformer.transformations = append(transformer.transformations, transformations...)
+	}
+}
+
+// WithInternalTables sets the tables added by the CLI (such as the sync summary table), so that the glob patterns of the transformations don't match them
+func WithInternalTables(tableNames ...string) RecordTransformerOption {
+	return func(transformer *RecordTransformer) {
+		transformer.internalTables = append(transformer.internalTables, tableNames...)
+	}
+}
+
 func NewRecordTransformer(opts ...RecordTransformerOption) *RecordTransformer {
 	t := &RecordTransformer{}
 	for _, opt := range opts {
@@ -81,7 +102,39 @@ func NewRecordTransformer(opts ...RecordTransformerOption) *RecordTransformer {
 	return t
 }
 
-func (t *RecordTransformer) TransformSchema(sc *arrow.Schema) *arrow.Schema {
+// TransformSchema returns the schema of the table as it is sent to the destination.
+// It returns an error if the table is renamed to the name of another table, or a column to the name of another column.
+func (t *RecordTransformer) TransformSchema(sc *arrow.Schema) (*arrow.Schema, error) {
+	newSchema, _ := t.applyTransformations(t.transformInternalColumns(sc), nil, 0)
+	tableName, _ := sc.Metadata().GetValue(schema.MetadataTableName)
+	for _, field := range newSchema.Fields() {
+		if len(newSchema.FieldIndices(field.Name)) > 1 {
+			return nil, fmt.Errorf("more than one column of table %s is transformed to column %s", tableName, field.Name)
+		}
+	}
+	newTableName, _ := newSchema.Metadata().GetValue(schema.MetadataTableName)
+	if t.originalTableNames == nil {
+		t.originalTableNames = make(map[string]string)
+	}
+	if original, ok := t.originalTableNames[newTableName]; ok && original != tableName {
+		return nil, fmt.Errorf("tables %s and %s are both transformed to table %s", original, tableName, newTableName)
+	}
+	t.originalTableNames[newTableName] = tableName
+	return newSchema, nil
+}
+
+// TransformTableName returns the name of the table as it is sent to the destination
+func (t *RecordTransformer) TransformTableName(tableName string) string {
+	for i := range t.transformations {
+		tr := &t.transformations[i]
+		if tr.Kind == specs.TransformationKindRenameTable && t.matchesTable(tr, tableName) {
+			tableName = tr.RenderedTableName(tableName)
+		}
+	}
+	return tableName
+}
+
+func (t *RecordTransformer) transformInternalColumns(sc *arrow.Schema) *arrow.Schema {
 	fields := make([]arrow.Field, 0, len(sc.Fields())+t.internalColumns)
 	if t.withSyncTime && !sc.HasField(cqSyncTime) {
 		fields = append(fields, arrow.Field{Name: cqSyncTime, Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true})
@@ -127,7 +180,7 @@ func (t *RecordTransformer) TransformSchema(sc *arrow.Schema) *arrow.Schema {
 
 func (t *RecordTransformer) Transform(record arrow.Record) arrow.Record {
 	sc := record.Schema()
-	newSchema := t.TransformSchema(sc)
+	newSchema := t.transformInternalColumns(sc)
 	nRows := int(record.NumRows())
 
 	cols := make([]arrow.Array, 0, len(sc.Fields())+t.internalColumns)
@@ -158,6 +211,7 @@ func (t *RecordTransformer) Transform(record arrow.Record) arrow.Record {
 	}
 
 	cols = append(cols, record.Columns()...)
+	newSchema, cols = t.applyTransformations(newSchema, cols, nRows)
 
 	return array.NewRecord(newSchema, cols, int64(nRows))
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/arrow/array"
 	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/cloudquery/cli/internal/specs/v0"
 	"github.com/cloudquery/plugin-sdk/v4/schema"
 )
 
@@ -135,6 +136,193 @@ var transformTestCases = []struct {
 		}, nil),
 		expectedJSONRecord: []byte(`{"id": 1, "_cq_id": 2}`),
 	},
+	{
+		name: "drop_and_rename_columns",
+		transformer: func() *RecordTransformer {
+			return NewRecordTransformer(WithTransformations(
+				specs.Transformation{Kind: specs.TransformationKindDropColumns, Tables: []string{"test_*"}, Columns: []string{"secret"}},
+				specs.Transformation{Kind: specs.TransformationKindRenameColumn, Tables: []string{"test_table"}, Column: "name", NewName: "display_name"},
+			))
+		},
+		originalSchema: arrow.NewSchema([]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "name", Type: arrow.BinaryTypes.String},
+			{Name: "secret", Type: arrow.BinaryTypes.String},
+		}, testTableMetadata("test_table")),
+		originalJSONRecord: []byte(`{"id": 1, "name": "alice", "secret": "s3cr3t"}`),
+		expectedSchema: arrow.NewSchema([]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "display_name", Type: arrow.BinaryTypes.String},
+		}, testTableMetadata("test_table")),
+		expectedJSONRecord: []byte(`{"id": 1, "display_name": "alice"}`),
+	},
+	{
+		name: "hash_and_mask_columns",
+		transformer: func() *RecordTransformer {
+			return NewRecordTransformer(WithTransformations(
+				specs.Transformation{Kind: specs.TransformationKindHashColumns, Tables: []string{"*"}, Columns: []string{"email"}, Key: "secret"},
+				specs.Transformation{Kind: specs.TransformationKindMaskColumns, Tables: []string{"*"}, Columns: []string{"ip", "port"}},
+			))
+		},
+		originalSchema: arrow.NewSchema([]arrow.Field{
+			{Name: "email", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "ip", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "port", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		}, testTableMetadata("test_table")),
+		originalJSONRecord: []byte(`{"email": "alice@example.com", "ip": "10.0.0.1", "port": null}`),
+		expectedSchema: arrow.NewSchema([]arrow.Field{
+			{Name: "email", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "ip", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "port", Type: arrow.BinaryTypes.String, Nullable: true},
+		}, testTableMetadata("test_table")),
+		expectedJSONRecord: []byte(`{"email": "a398d49ce1980b3642bc4dbd110121e3c953e1eadb497d50dea23e9611f83ee7", "ip": "*****", "port": null}`),
+	},
+	{
+		name: "rename_table_and_add_column",
+		transformer: func() *RecordTransformer {
+			return NewRecordTransformer(WithSourceNameColumn("test"), WithTransformations(
+				specs.Transformation{Kind: specs.TransformationKindRenameTable, Tables: []string{"test_table"}, NewName: "pii_{{TABLE}}"},
+				specs.Transformation{Kind: specs.TransformationKindAddColumn, Tables: []string{"pii_test_table"}, Column: "tenant", Value: "acme"},
+				specs.Transformation{Kind: specs.TransformationKindDropColumns, Tables: []string{"test_table"}, Columns: []string{"id"}},
+			))
+		},
+		originalSchema: arrow.NewSchema([]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		}, testTableMetadata("test_table")),
+		originalJSONRecord: []byte(`{"id": 1}`),
+		expectedSchema: arrow.NewSchema([]arrow.Field{
+			{Name: "_cq_source_name", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "tenant", Type: arrow.BinaryTypes.String, Nullable: true},
+		}, testTableMetadata("pii_test_table")),
+		expectedJSONRecord: []byte(`{"_cq_source_name": "test", "id": 1, "tenant": "acme"}`),
+	},
+	{
+		name: "transformation_for_other_table",
+		transformer: func() *RecordTransformer {
+			return NewRecordTransformer(WithTransformations(
+				specs.Transformation{Kind: specs.TransformationKindDropColumns, Tables: []string{"other_table"}, Columns: []string{"id"}},
+			))
+		},
+		originalSchema: arrow.NewSchema([]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		}, testTableMetadata("test_table")),
+		originalJSONRecord: []byte(`{"id": 1}`),
+		expectedSchema: arrow.NewSchema([]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		}, testTableMetadata("test_table")),
+		expectedJSONRecord: []byte(`{"id": 1}`),
+	},
+}
+
+func testTableMetadata(tableName string) *arrow.Metadata {
+	md := arrow.NewMetadata([]string{schema.MetadataTableName}, []string{tableName})
+	return &md
+}
+
+func TestTransformTableName(t *testing.T) {
+	transformer := NewRecordTransformer(WithTransformations(
+		specs.Transformation{Kind: specs.TransformationKindRenameTable, Tables: []string{"test_*"}, NewName: "{{TABLE}}_v2"},
+		specs.Transformation{Kind: specs.TransformationKindRenameTable, Tables: []string{"test_table_v2"}, NewName: "renamed"},
+	))
+	if got := transformer.TransformTableName("test_table"); got != "renamed" {
+		t.Fatalf("expected renamed, got %s", got)
+	}
+	if got := transformer.TransformTableName("test_other"); got != "test_other_v2" {
+		t.Fatalf("expected test_other_v2, got %s", got)
+	}
+	if got := transformer.TransformTableName("other"); got != "other" {
+		t.Fatalf("expected other, got %s", got)
+	}
+}
+
+func TestTransformSchema(t *testing.T) {
+	transformer := NewRecordTransformer(WithTransformations(
+		specs.Transformation{Kind: specs.TransformationKindRenameTable, Tables: []string{"test_*"}, NewName: "{{TABLE}}_v2"},
+		specs.Transformation{Kind: specs.TransformationKindRenameTable, Tables: []string{"other"}, NewName: "test_table_v2"},
+	))
+
+	parentMetadata := arrow.NewMetadata([]string{schema.MetadataTableName}, []string{"test_table"})
+	sc, err := transformer.TransformSchema(arrow.NewSchema(nil, &parentMetadata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tableMetadata(sc, schema.MetadataTableName); got != "test_table_v2" {
+		t.Fatalf("expected %s, got %s", "test_table_v2", got)
+	}
+
+	// child tables keep referencing their renamed parent
+	childMetadata := arrow.NewMetadata([]string{schema.MetadataTableName, schema.MetadataTableDependsOn}, []string{"test_child", "test_table"})
+	sc, err = transformer.TransformSchema(arrow.NewSchema(nil, &childMetadata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tableMetadata(sc, schema.MetadataTableName); got != "test_child_v2" {
+		t.Fatalf("expected %s, got %s", "test_child_v2", got)
+	}
+	if got := tableMetadata(sc, schema.MetadataTableDependsOn); got != "test_table_v2" {
+		t.Fatalf("expected %s, got %s", "test_table_v2", got)
+	}
+
+	// the same table can be transformed again
+	_, err = transformer.TransformSchema(arrow.NewSchema(nil, &parentMetadata))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherMetadata := arrow.NewMetadata([]string{schema.MetadataTableName}, []string{"other"})
+	_, err = transformer.TransformSchema(arrow.NewSchema(nil, &otherMetadata))
+	if err == nil || err.Error() != "tables test_table and other are both transformed to table test_table_v2" {
+		t.Fatalf("expected error %q, got %v", "tables test_table and other are both transformed to table test_table_v2", err)
+	}
+}
+
+func TestTransformSchemaColumnCollision(t *testing.T) {
+	transformer := NewRecordTransformer(WithTransformations(
+		specs.Transformation{Kind: specs.TransformationKindRenameColumn, Tables: []string{"*"}, Column: "name", NewName: "id"},
+	))
+	_, err := transformer.TransformSchema(arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String},
+	}, testTableMetadata("test_table")))
+	if err == nil || err.Error() != "more than one column of table test_table is transformed to column id" {
+		t.Fatalf("expected error %q, got %v", "more than one column of table test_table is transformed to column id", err)
+	}
+}
+
+func TestTransformSchemaInternalTables(t *testing.T) {
+	transformer := NewRecordTransformer(
+		WithTransformations(
+			specs.Transformation{Kind: specs.TransformationKindRenameTable, Tables: []string{"*"}, NewName: "prefix_{{TABLE}}"},
+			specs.Transformation{Kind: specs.TransformationKindDropColumns, Tables: []string{"internal_table"}, Columns: []string{"name"}},
+		),
+		WithInternalTables("internal_table"),
+	)
+	sc, err := transformer.TransformSchema(arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String},
+	}, testTableMetadata("internal_table")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// glob patterns don't match internal tables, but their exact name does
+	if got := tableMetadata(sc, schema.MetadataTableName); got != "internal_table" {
+		t.Fatalf("expected %s, got %s", "internal_table", got)
+	}
+	if sc.HasField("name") {
+		t.Fatalf("expected column name to be dropped")
+	}
+	if got := transformer.TransformTableName("internal_table"); got != "internal_table" {
+		t.Fatalf("expected internal_table, got %s", got)
+	}
+	if got := transformer.TransformTableName("test_table"); got != "prefix_test_table" {
+		t.Fatalf("expected prefix_test_table, got %s", got)
+	}
+}
+
+func tableMetadata(sc *arrow.Schema, key string) string {
+	value, _ := sc.Metadata().GetValue(key)
+	return value
 }
 
 func TestRecord(t *testing.T) {
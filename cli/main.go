@@ -64,6 +64,6 @@ func main() {
 	log.Logger = log.Level(zerolog.Disabled)
 	if err := executeRootCmdWithContext(); err != nil {
 		log.Error().Err(err).Msg("exiting with error")
-		exitCode = 1
+		exitCode = cmd.ExitCode(err)
 	}
 }
@@ -5,14 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/bigquery/storage/managedwriter"
 	internalPlugin "github.com/cloudquery/cloudquery/plugins/destination/bigquery/resources/plugin"
 	"github.com/cloudquery/plugin-sdk/v4/plugin"
 	"github.com/cloudquery/plugin-sdk/v4/writers/batchwriter"
 	"github.com/rs/zerolog"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 const cloudQueryGPN = "CloudQuery"
@@ -22,16 +26,26 @@ type Client struct {
 	logger zerolog.Logger
 	spec   Spec
 	client *bigquery.Client
-	writer *batchwriter.BatchWriter
+	// writeClient is only set when `write_api` is `storage_write`
+	writeClient *managedwriter.Client
+	writer      *batchwriter.BatchWriter
+
+	// streams holds the Storage Write API stream writer of each table
+	streams   map[string]*managedStream
+	streamsMu sync.Mutex
+	// staging holds the staging table of each table written in `overwrite` mode, merged into the table by mergeStaging
+	staging   map[string]*stagingTable
+	stagingMu sync.Mutex
 
-	batchwriter.UnimplementedDeleteStale
 	batchwriter.UnimplementedDeleteRecord
 }
 
 func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, opts plugin.NewClientOptions) (plugin.Client, error) {
 	var err error
 	c := &Client{
-		logger: logger.With().Str("module", "bq-dest").Logger(),
+		logger:  logger.With().Str("module", "bq-dest").Logger(),
+		streams: make(map[string]*managedStream),
+		staging: make(map[string]*stagingTable),
 	}
 	if opts.NoConnection {
 		return c, nil
@@ -65,6 +79,14 @@ func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, opts plug
 		return nil, fmt.Errorf("failed to validate credentials: %w", err)
 	}
 
+	if c.spec.WriteAPI == WriteAPIOptionStorageWrite {
+		// same as above, the context is retained for the background connection management
+		c.writeClient, err = writeClient(context.Background(), c.spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Storage Write API client: %w", err)
+		}
+	}
+
 	return c, nil
 }
 
@@ -72,10 +94,19 @@ func (c *Client) Close(ctx context.Context) error {
 	if err := c.writer.Close(ctx); err != nil {
 		return err
 	}
+	c.dropStaging(ctx)
+	if c.writeClient != nil {
+		if err := c.closeManagedStreams(); err != nil {
+			return err
+		}
+		if err := c.writeClient.Close(); err != nil {
+			return err
+		}
+	}
 	return c.client.Close()
 }
 
-func bqClient(ctx context.Context, s Spec) (*bigquery.Client, error) {
+func clientOptions(s Spec) []option.ClientOption {
 	opts := []option.ClientOption{
 		option.WithRequestReason("CloudQuery BigQuery destination"),
 		option.WithUserAgent(fmt.Sprintf("CloudQuery_BigQuery_Destination/%s (GPN:%s)", internalPlugin.Version, cloudQueryGPN)),
@@ -83,6 +114,23 @@ func bqClient(ctx context.Context, s Spec) (*bigquery.Client, error) {
 	if len(s.ServiceAccountKeyJSON) != 0 {
 		opts = append(opts, option.WithCredentialsJSON([]byte(s.ServiceAccountKeyJSON)))
 	}
+	return opts
+}
+
+func writeClient(ctx context.Context, s Spec) (*managedwriter.Client, error) {
+	opts := clientOptions(s)
+	if s.StorageWriteEndpoint != "" {
+		opts = append(opts,
+			option.WithEndpoint(s.StorageWriteEndpoint),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+	}
+	return managedwriter.NewClient(ctx, s.ProjectID, opts...)
+}
+
+func bqClient(ctx context.Context, s Spec) (*bigquery.Client, error) {
+	opts := clientOptions(s)
 	if s.Endpoint != "" {
 		opts = append(opts, option.WithEndpoint(s.Endpoint))
 	}
@@ -15,11 +15,12 @@ func TestPlugin(t *testing.T) {
 
 	p := plugin.NewPlugin("bigquery", "development", New)
 	spec := &Spec{
-		ProjectID:        os.Getenv("BIGQUERY_PROJECT_ID"),
-		DatasetID:        os.Getenv("BIGQUERY_DATASET_ID"),
-		DatasetLocation:  os.Getenv("BIGQUERY_DATASET_LOCATION"),
-		Endpoint:         os.Getenv("BIGQUERY_ENDPOINT"),
-		TimePartitioning: "none",
+		ProjectID:            os.Getenv("BIGQUERY_PROJECT_ID"),
+		DatasetID:            os.Getenv("BIGQUERY_DATASET_ID"),
+		DatasetLocation:      os.Getenv("BIGQUERY_DATASET_LOCATION"),
+		Endpoint:             os.Getenv("BIGQUERY_ENDPOINT"),
+		StorageWriteEndpoint: os.Getenv("BIGQUERY_STORAGE_WRITE_ENDPOINT"),
+		TimePartitioning:     "none",
 	}
 	specBytes, err := json.Marshal(spec)
 	if err != nil {
@@ -32,9 +33,7 @@ func TestPlugin(t *testing.T) {
 	plugin.TestWriterSuiteRunner(t,
 		p,
 		plugin.WriterTestSuiteTests{
-			SkipUpsert:       true,
 			SkipMigrate:      true,
-			SkipDeleteStale:  true,
 			SkipDeleteRecord: true,
 		},
 		plugin.WithTestDataOptions(schema.TestSourceOptions{
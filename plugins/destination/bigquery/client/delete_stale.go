@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+const deleteStaleSQL = "DELETE FROM `%s.%s.%s` WHERE `%s` = @source_name AND `%s` < @sync_time"
+
+func (c *Client) DeleteStale(ctx context.Context, msgs message.WriteDeleteStales) error {
+	tableNames := make([]string, len(msgs))
+	for i, msg := range msgs {
+		tableNames[i] = msg.TableName
+	}
+	// the rows written during the sync have to be merged first, so that they aren't deleted
+	if err := c.mergeStaging(ctx, tableNames...); err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		query := fmt.Sprintf(deleteStaleSQL, c.spec.ProjectID, c.spec.DatasetID, msg.TableName, schema.CqSourceNameColumn.Name, schema.CqSyncTimeColumn.Name)
+		err := c.runQuery(ctx, query,
+			bigquery.QueryParameter{Name: "source_name", Value: msg.SourceName},
+			// _cq_sync_time is stored with microsecond precision
+			bigquery.QueryParameter{Name: "sync_time", Value: msg.SyncTime.UTC().Truncate(time.Microsecond)},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to delete stale rows from table %s: %w", msg.TableName, err)
+		}
+	}
+	return nil
+}
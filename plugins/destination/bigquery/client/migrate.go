@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"cloud.google.com/go/bigquery"
@@ -20,14 +21,19 @@ const (
 )
 
 func (c *Client) MigrateTables(ctx context.Context, msgs message.WriteMigrateTables) error {
-	eg, gctx := errgroup.WithContext(ctx)
-	eg.SetLimit(concurrentMigrations)
-	for _, msg := range msgs {
-		if len(msg.Table.PrimaryKeys()) > 0 {
-			return fmt.Errorf("primary keys are not supported by the BigQuery plugin. Hint: try setting `write_mode: append` in the destination spec")
-		}
+	tableNames := make([]string, len(msgs))
+	for i, msg := range msgs {
+		tableNames[i] = msg.Table.Name
+		// the table may be recreated, so the stream writer is opened again on the next write
+		c.closeManagedStream(msg.Table.Name)
+	}
+	// the staging tables have the schema of the tables before the migration
+	if err := c.mergeStaging(ctx, tableNames...); err != nil {
+		return err
 	}
 
+	eg, gctx := errgroup.WithContext(ctx)
+	eg.SetLimit(concurrentMigrations)
 	for _, msg := range msgs {
 		table := msg.Table
 		eg.Go(func() error {
@@ -143,6 +149,7 @@ func (c *Client) autoMigrateTable(ctx context.Context, client *bigquery.Client,
 		Description: table.Description,
 		Schema:      wantSchema,
 	}
+	c.updateTableOptions(&tm, md, c.spec.MatchTableOptions(table.Name), table.Name)
 	_, err = bqTable.Update(ctx, tm, "")
 	if err != nil {
 		return fmt.Errorf("failed to update schema for table %q with error: %w", table.Name, err)
@@ -150,6 +157,43 @@ func (c *Client) autoMigrateTable(ctx context.Context, client *bigquery.Client,
 	return nil
 }
 
+// updateTableOptions updates the clustering & partition expiration of the existing table if these are set in the `tables` entry.
+// The partitioning itself can't be changed, so only a warning is logged if it doesn't match.
+func (c *Client) updateTableOptions(tm *bigquery.TableMetadataToUpdate, md *bigquery.TableMetadata, matched *TableOptions, table string) {
+	if matched == nil {
+		return
+	}
+	options := c.spec.TableOptions(table)
+
+	if len(options.ClusteringFields) > 0 && (md.Clustering == nil || !slices.Equal(md.Clustering.Fields, options.ClusteringFields)) {
+		tm.Clustering = options.clustering()
+	}
+
+	if !partitioningMatches(md, options) {
+		c.logger.Warn().Str("table", table).Msg("Partitioning of existing tables can't be changed, drop the table to apply the partitioning options")
+		return
+	}
+	if md.TimePartitioning != nil && md.TimePartitioning.Expiration != options.PartitionExpiration.Duration() {
+		// all the mutable fields have to be set, so we start from the current partitioning.
+		// A zero expiration is sent as null, removing the expiration.
+		partitioning := *md.TimePartitioning
+		partitioning.Expiration = options.PartitionExpiration.Duration()
+		tm.TimePartitioning = &partitioning
+	}
+}
+
+func partitioningMatches(md *bigquery.TableMetadata, options *TableOptions) bool {
+	switch want := options.timePartitioning(); {
+	case want != nil:
+		return md.TimePartitioning != nil && md.TimePartitioning.Type == want.Type && md.TimePartitioning.Field == want.Field
+	case options.PartitionType == PartitionTypeOptionIntegerRange:
+		return md.RangePartitioning != nil && md.RangePartitioning.Field == options.PartitionColumn &&
+			md.RangePartitioning.Range != nil && *md.RangePartitioning.Range == *options.rangePartitioning().Range
+	default:
+		return md.TimePartitioning == nil && md.RangePartitioning == nil
+	}
+}
+
 func schemasMatch(haveSchema, wantSchema bigquery.Schema) bool {
 	// Schemas are considered a match if everything in the want schema is in the have schema,
 	// and they have the same types.
@@ -203,33 +247,22 @@ func mergeSchemas(haveSchema, wantSchema bigquery.Schema) (bigquery.Schema, erro
 
 func (c *Client) createTable(ctx context.Context, client *bigquery.Client, table *schema.Table) error {
 	bqSchema := c.bigQuerySchemaForTable(table)
+	options := c.spec.TableOptions(table.Name)
+	if err := options.validateColumns(bqSchema); err != nil {
+		return fmt.Errorf("invalid options for table %s: %w", table.Name, err)
+	}
 	tm := bigquery.TableMetadata{
-		Name:             table.Name,
-		Location:         "",
-		Description:      table.Description,
-		Schema:           bqSchema,
-		TimePartitioning: c.timePartitioning(),
+		Name:              table.Name,
+		Location:          "",
+		Description:       table.Description,
+		Schema:            bqSchema,
+		TimePartitioning:  options.timePartitioning(),
+		RangePartitioning: options.rangePartitioning(),
+		Clustering:        options.clustering(),
 	}
 	return client.Dataset(c.spec.DatasetID).Table(table.Name).Create(ctx, &tm)
 }
 
-func (c *Client) timePartitioning() *bigquery.TimePartitioning {
-	switch c.spec.TimePartitioning {
-	case TimePartitioningOptionHour:
-		return &bigquery.TimePartitioning{
-			Type:  "HOUR",
-			Field: "_cq_sync_time",
-		}
-	case TimePartitioningOptionDay:
-		return &bigquery.TimePartitioning{
-			Type:  "DAY",
-			Field: "_cq_sync_time",
-		}
-	default:
-		return nil
-	}
-}
-
 func (c *Client) bigQuerySchemaForTable(table *schema.Table) bigquery.Schema {
 	s := bigquery.Schema{}
 	for _, col := range table.Columns {
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/google/uuid"
+)
+
+const (
+	// staging tables are removed after they're merged, the expiration is a safety net for failed syncs
+	stagingTableExpiration = 24 * time.Hour
+
+	mergeSQL = "MERGE `%s.%s.%s` AS t USING (%s) AS s ON %s WHEN MATCHED THEN UPDATE SET %s WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)"
+	// BigQuery requires every target row to be matched by at most one source row
+	dedupSQL = "SELECT * FROM `%s.%s.%s` WHERE TRUE QUALIFY ROW_NUMBER() OVER (PARTITION BY %s) = 1"
+)
+
+// stagingTable is the staging table the rows of a table are written to in `overwrite` mode during the sync
+type stagingTable struct {
+	name  string
+	table *schema.Table
+}
+
+// upsertRows writes the rows to the staging table of the table, creating it on the first batch.
+// The staging table is merged into the table on the primary keys by mergeStaging.
+func (c *Client) upsertRows(ctx context.Context, table *schema.Table, msgs message.WriteInserts) error {
+	staging, err := c.stagingTable(ctx, table)
+	if err != nil {
+		return err
+	}
+	return c.appendRows(ctx, staging.name, table, msgs)
+}
+
+func (c *Client) stagingTable(ctx context.Context, table *schema.Table) (*stagingTable, error) {
+	c.stagingMu.Lock()
+	defer c.stagingMu.Unlock()
+	if staging, ok := c.staging[table.Name]; ok {
+		return staging, nil
+	}
+
+	staging := &stagingTable{name: stagingTableName(table.Name), table: table}
+	err := c.client.Dataset(c.spec.DatasetID).Table(staging.name).Create(ctx, &bigquery.TableMetadata{
+		Name:           staging.name,
+		Schema:         c.bigQuerySchemaForTable(table),
+		ExpirationTime: time.Now().Add(stagingTableExpiration),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging table %s: %w", staging.name, err)
+	}
+	c.staging[table.Name] = staging
+	return staging, nil
+}
+
+// takeStaging removes the staging tables of the tables (or all of them, if no tables are given) from the client and returns them.
+func (c *Client) takeStaging(tableNames ...string) []*stagingTable {
+	c.stagingMu.Lock()
+	defer c.stagingMu.Unlock()
+	if len(tableNames) == 0 {
+		for name := range c.staging {
+			tableNames = append(tableNames, name)
+		}
+		slices.Sort(tableNames)
+	}
+	var res []*stagingTable
+	for _, name := range tableNames {
+		if staging, ok := c.staging[name]; ok {
+			res = append(res, staging)
+			delete(c.staging, name)
+		}
+	}
+	return res
+}
+
+// mergeStaging merges the staging tables of the tables (or all of them, if no tables are given) into the tables,
+// and deletes the staging tables.
+// It runs at the end of the sync, as well as before deleting stale rows and migrating the tables.
+func (c *Client) mergeStaging(ctx context.Context, tableNames ...string) error {
+	var errs []error
+	for _, staging := range c.takeStaging(tableNames...) {
+		table := staging.table
+		query := mergeQuery(c.spec.ProjectID, c.spec.DatasetID, table.Name, staging.name, table.Columns.Names(), table.PrimaryKeys())
+		if err := c.runQuery(ctx, query); err != nil {
+			errs = append(errs, fmt.Errorf("failed to merge rows into table %s: %w", table.Name, err))
+		}
+		c.deleteStagingTable(ctx, staging)
+	}
+	return errors.Join(errs...)
+}
+
+// dropStaging deletes the staging tables that weren't merged, as the sync failed.
+func (c *Client) dropStaging(ctx context.Context) {
+	for _, staging := range c.takeStaging() {
+		c.logger.Warn().Str("table", staging.table.Name).Msg("Sync didn't complete, rows written in overwrite mode weren't merged into the table")
+		c.deleteStagingTable(ctx, staging)
+	}
+}
+
+func (c *Client) deleteStagingTable(ctx context.Context, staging *stagingTable) {
+	if err := c.client.Dataset(c.spec.DatasetID).Table(staging.name).Delete(context.WithoutCancel(ctx)); err != nil {
+		c.logger.Warn().Err(err).Str("table", staging.name).Msg("Failed to delete staging table, it will expire on its own")
+	}
+}
+
+func stagingTableName(table string) string {
+	return table + "_cq_staging_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+func mergeQuery(projectID, datasetID, table, stagingTable string, columns, primaryKeys []string) string {
+	on := make([]string, len(primaryKeys))
+	for i, pk := range primaryKeys {
+		on[i] = "t." + quoteIdentifier(pk) + " = s." + quoteIdentifier(pk)
+	}
+	set := make([]string, len(columns))
+	insertColumns := make([]string, len(columns))
+	insertValues := make([]string, len(columns))
+	for i, col := range columns {
+		set[i] = quoteIdentifier(col) + " = s." + quoteIdentifier(col)
+		insertColumns[i] = quoteIdentifier(col)
+		insertValues[i] = "s." + quoteIdentifier(col)
+	}
+
+	source := fmt.Sprintf(dedupSQL, projectID, datasetID, stagingTable, strings.Join(quoteIdentifiers(primaryKeys), ", "))
+	return fmt.Sprintf(mergeSQL, projectID, datasetID, table, source,
+		strings.Join(on, " AND "),
+		strings.Join(set, ", "),
+		strings.Join(insertColumns, ", "),
+		strings.Join(insertValues, ", "),
+	)
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + name + "`"
+}
+
+func quoteIdentifiers(names []string) []string {
+	res := make([]string, len(names))
+	for i, name := range names {
+		res[i] = quoteIdentifier(name)
+	}
+	return res
+}
+
+// runQuery runs the (DML) query and waits for it to complete.
+func (c *Client) runQuery(ctx context.Context, query string, params ...bigquery.QueryParameter) error {
+	q := c.client.Query(query)
+	q.Parameters = params
+	q.Location = c.client.Location
+	job, err := q.Run(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
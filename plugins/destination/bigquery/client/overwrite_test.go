@@ -0,0 +1,52 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeQuery(t *testing.T) {
+	got := mergeQuery("project", "dataset", "table", "table_cq_staging_1", []string{"_cq_id", "id", "region", "name"}, []string{"id", "region"})
+	want := "MERGE `project.dataset.table` AS t " +
+		"USING (SELECT * FROM `project.dataset.table_cq_staging_1` WHERE TRUE QUALIFY ROW_NUMBER() OVER (PARTITION BY `id`, `region`) = 1) AS s " +
+		"ON t.`id` = s.`id` AND t.`region` = s.`region` " +
+		"WHEN MATCHED THEN UPDATE SET `_cq_id` = s.`_cq_id`, `id` = s.`id`, `region` = s.`region`, `name` = s.`name` " +
+		"WHEN NOT MATCHED THEN INSERT (`_cq_id`, `id`, `region`, `name`) VALUES (s.`_cq_id`, s.`id`, s.`region`, s.`name`)"
+	if got != want {
+		t.Errorf("mergeQuery() = %s, want %s", got, want)
+	}
+}
+
+func TestStagingTableName(t *testing.T) {
+	a, b := stagingTableName("table"), stagingTableName("table")
+	if !strings.HasPrefix(a, "table_cq_staging_") {
+		t.Errorf("stagingTableName() = %s, want table_cq_staging_ prefix", a)
+	}
+	if a == b {
+		t.Errorf("stagingTableName() returned the same name twice: %s", a)
+	}
+}
+
+func TestTakeStaging(t *testing.T) {
+	c := &Client{staging: map[string]*stagingTable{
+		"a": {name: "a_cq_staging_1"},
+		"b": {name: "b_cq_staging_1"},
+		"c": {name: "c_cq_staging_1"},
+	}}
+
+	got := c.takeStaging("b", "missing")
+	if len(got) != 1 || got[0].name != "b_cq_staging_1" {
+		t.Fatalf("takeStaging(b, missing) = %v, want the staging table of b", got)
+	}
+	if _, ok := c.staging["b"]; ok {
+		t.Fatalf("staging table of b wasn't removed")
+	}
+
+	got = c.takeStaging()
+	if len(got) != 2 || got[0].name != "a_cq_staging_1" || got[1].name != "c_cq_staging_1" {
+		t.Fatalf("takeStaging() = %v, want the staging tables of a and c", got)
+	}
+	if len(c.staging) != 0 {
+		t.Fatalf("staging tables weren't removed: %v", c.staging)
+	}
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/bigquery/storage/managedwriter/adapt"
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// storageWriteSchema replaces the types we send as strings to the Storage Write API, as the API
+// converts them to the column types on its own.
+// The proto encodings generated by adapt for these types are either packed or not supported at all.
+func storageWriteSchema(s bigquery.Schema) bigquery.Schema {
+	res := make(bigquery.Schema, len(s))
+	for i, f := range s {
+		field := *f
+		switch field.Type {
+		case bigquery.TimeFieldType, bigquery.NumericFieldType, bigquery.BigNumericFieldType, bigquery.JSONFieldType:
+			field.Type = bigquery.StringFieldType
+		case bigquery.RecordFieldType:
+			field.Schema = storageWriteSchema(field.Schema)
+		}
+		res[i] = &field
+	}
+	return res
+}
+
+// protoDescriptor returns the message descriptor used to serialize the rows of the table schema,
+// as well as its normalized form to be sent to the Storage Write API.
+// The message fields are numbered in the order of the schema fields, starting at 1.
+func protoDescriptor(s bigquery.Schema) (protoreflect.MessageDescriptor, *descriptorpb.DescriptorProto, error) {
+	storageSchema, err := adapt.BQSchemaToStorageTableSchema(storageWriteSchema(s))
+	if err != nil {
+		return nil, nil, err
+	}
+	descriptor, err := adapt.StorageSchemaToProto2Descriptor(storageSchema, "root")
+	if err != nil {
+		return nil, nil, err
+	}
+	md, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected descriptor type %T", descriptor)
+	}
+	dp, err := adapt.NormalizeDescriptor(md)
+	if err != nil {
+		return nil, nil, err
+	}
+	return md, dp, nil
+}
+
+// recordToProtoRows serializes the record rows into messages of the descriptor built for the record schema.
+func recordToProtoRows(md protoreflect.MessageDescriptor, rec arrow.Record) ([][]byte, error) {
+	rows := make([][]byte, rec.NumRows())
+	for i := range rows {
+		msg := dynamicpb.NewMessage(md)
+		for n, col := range rec.Columns() {
+			if err := setProtoField(msg, fieldByIndex(md, n), col, i); err != nil {
+				return nil, fmt.Errorf("failed to convert column %s: %w", rec.ColumnName(n), err)
+			}
+		}
+		b, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = b
+	}
+	return rows, nil
+}
+
+func fieldByIndex(md protoreflect.MessageDescriptor, i int) protoreflect.FieldDescriptor {
+	return md.Fields().ByNumber(protowire.Number(i + 1))
+}
+
+func setProtoField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, col arrow.Array, i int) error {
+	if col.IsNull(i) {
+		// save some bandwidth by not sending nil values
+		return nil
+	}
+
+	if fd.IsList() {
+		list, ok := col.(array.ListLike)
+		if !ok {
+			return fmt.Errorf("unexpected array type %T for repeated field", col)
+		}
+		from, to := list.ValueOffsets(i)
+		elems := array.NewSlice(list.ListValues(), from, to)
+		defer elems.Release()
+		values := msg.Mutable(fd).List()
+		for j := 0; j < elems.Len(); j++ {
+			if elems.IsNull(j) {
+				// LIMITATION: BigQuery does not support null values in repeated columns.
+				// Therefore, these get stripped out here.
+				continue
+			}
+			v, err := protoValue(fd, elems, j)
+			if err != nil {
+				return err
+			}
+			values.Append(v)
+		}
+		return nil
+	}
+
+	v, err := protoValue(fd, col, i)
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, v)
+	return nil
+}
+
+// protoValue converts a non-null value to the proto field value, following the types from DataTypeToBigQueryType.
+func protoValue(fd protoreflect.FieldDescriptor, col arrow.Array, i int) (protoreflect.Value, error) {
+	if fd.Kind() == protoreflect.StringKind && arrow.IsNested(col.DataType().ID()) {
+		// maps, as well as structs & lists when `nested_types_as_json` is enabled
+		value, err := nestedJSONValue(col, i)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfString(value), nil
+	}
+
+	switch v := col.(type) {
+	case array.ExtensionArray:
+		// JSON, UUID, Inet & MAC
+		return protoreflect.ValueOfString(v.ValueStr(i)), nil
+	case *array.Boolean:
+		return protoreflect.ValueOfBool(v.Value(i)), nil
+	case *array.Int8:
+		return protoreflect.ValueOfInt64(int64(v.Value(i))), nil
+	case *array.Int16:
+		return protoreflect.ValueOfInt64(int64(v.Value(i))), nil
+	case *array.Int32:
+		return protoreflect.ValueOfInt64(int64(v.Value(i))), nil
+	case *array.Int64:
+		return protoreflect.ValueOfInt64(v.Value(i)), nil
+	case *array.Uint8:
+		return protoreflect.ValueOfInt64(int64(v.Value(i))), nil
+	case *array.Uint16:
+		return protoreflect.ValueOfInt64(int64(v.Value(i))), nil
+	case *array.Uint32:
+		return protoreflect.ValueOfInt64(int64(v.Value(i))), nil
+	case *array.Uint64:
+		// NUMERIC
+		return protoreflect.ValueOfString(strconv.FormatUint(v.Value(i), 10)), nil
+	case *array.Float32:
+		return protoreflect.ValueOfFloat64(float64(v.Value(i))), nil
+	case *array.Float64:
+		return protoreflect.ValueOfFloat64(v.Value(i)), nil
+	case *array.String:
+		return protoreflect.ValueOfString(v.Value(i)), nil
+	case *array.LargeString:
+		return protoreflect.ValueOfString(v.Value(i)), nil
+	case *array.Binary:
+		return protoreflect.ValueOfBytes(v.Value(i)), nil
+	case *array.LargeBinary:
+		return protoreflect.ValueOfBytes(v.Value(i)), nil
+	case *array.Date32:
+		// days since epoch
+		return protoreflect.ValueOfInt32(int32(v.Value(i))), nil
+	case *array.Date64:
+		const millisecondsInDay = 24 * 60 * 60 * 1000
+		return protoreflect.ValueOfInt32(int32(int64(v.Value(i)) / millisecondsInDay)), nil
+	case *array.Timestamp:
+		unit := v.DataType().(*arrow.TimestampType).Unit
+		t := v.Value(i).ToTime(unit)
+		if unit != arrow.Nanosecond {
+			return protoreflect.ValueOfInt64(t.UnixMicro()), nil
+		}
+		// TimestampNanoseconds
+		return messageValue(fd, t.UnixMicro(), int64(t.Nanosecond()%1000)), nil
+	case *array.Time32:
+		unit := v.DataType().(*arrow.Time32Type).Unit
+		return protoreflect.ValueOfString(v.Value(i).ToTime(unit).Format("15:04:05.999999")), nil
+	case *array.Time64:
+		unit := v.DataType().(*arrow.Time64Type).Unit
+		return protoreflect.ValueOfString(v.Value(i).ToTime(unit).Format("15:04:05.999999")), nil
+	case *array.Duration:
+		return protoreflect.ValueOfInt64(int64(v.Value(i))), nil
+	case *array.MonthInterval:
+		return messageValue(fd, int64(v.Value(i))), nil
+	case *array.DayTimeInterval:
+		value := v.Value(i)
+		return messageValue(fd, int64(value.Days), int64(value.Milliseconds)), nil
+	case *array.MonthDayNanoInterval:
+		value := v.Value(i)
+		return messageValue(fd, int64(value.Months), int64(value.Days), value.Nanoseconds), nil
+	case *array.Decimal128:
+		// BIGNUMERIC
+		return protoreflect.ValueOfString(v.ValueStr(i)), nil
+	case *array.Decimal256:
+		// BIGNUMERIC
+		return protoreflect.ValueOfString(v.ValueStr(i)), nil
+	case *array.Struct:
+		msg := dynamicpb.NewMessage(fd.Message())
+		for f := 0; f < v.NumField(); f++ {
+			if err := setProtoField(msg, fieldByIndex(fd.Message(), f), v.Field(f), i); err != nil {
+				return protoreflect.Value{}, fmt.Errorf("failed to convert field %s: %w", v.DataType().(*arrow.StructType).Field(f).Name, err)
+			}
+		}
+		return protoreflect.ValueOfMessage(msg), nil
+	}
+
+	if fd.Kind() == protoreflect.StringKind {
+		return protoreflect.ValueOfString(col.ValueStr(i)), nil
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported array type %T", col)
+}
+
+// messageValue builds a message for the record types with integer fields only (timestamps with nanoseconds & intervals).
+func messageValue(fd protoreflect.FieldDescriptor, values ...int64) protoreflect.Value {
+	msg := dynamicpb.NewMessage(fd.Message())
+	for i, v := range values {
+		msg.Set(fieldByIndex(fd.Message(), i), protoreflect.ValueOfInt64(v))
+	}
+	return protoreflect.ValueOfMessage(msg)
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestRecordToProtoRows(t *testing.T) {
+	table := &schema.Table{
+		Name: "test",
+		Columns: schema.ColumnList{
+			{Name: "int", Type: arrow.PrimitiveTypes.Int32},
+			{Name: "uint64", Type: arrow.PrimitiveTypes.Uint64},
+			{Name: "string", Type: arrow.BinaryTypes.String},
+			{Name: "uuid", Type: types.ExtensionTypes.UUID},
+			{Name: "json", Type: types.ExtensionTypes.JSON},
+			{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_us},
+			{Name: "timestamp_ns", Type: arrow.FixedWidthTypes.Timestamp_ns},
+			{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+			{Name: "time", Type: arrow.FixedWidthTypes.Time64us},
+			{Name: "strings", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+			{Name: "struct", Type: arrow.StructOf(arrow.Field{Name: "foo", Type: arrow.BinaryTypes.String, Nullable: true})},
+			{Name: "interval", Type: arrow.FixedWidthTypes.MonthDayNanoInterval},
+		},
+	}
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	id := uuid.MustParse("2c6a3c2b-6d38-4e2a-9f3c-5c7f5a7b2d11")
+
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int32Builder).Append(-7)
+	bldr.Field(1).(*array.Uint64Builder).Append(18446744073709551615)
+	bldr.Field(2).(*array.StringBuilder).Append("foo")
+	bldr.Field(3).(*types.UUIDBuilder).Append(id)
+	bldr.Field(4).(*types.JSONBuilder).Append(map[string]any{"a": nil})
+	bldr.Field(5).(*array.TimestampBuilder).Append(arrow.Timestamp(ts.UnixMicro()))
+	bldr.Field(6).(*array.TimestampBuilder).Append(arrow.Timestamp(ts.UnixNano()))
+	bldr.Field(7).(*array.Date32Builder).Append(arrow.Date32FromTime(ts))
+	bldr.Field(8).(*array.Time64Builder).Append(arrow.Time64((3*time.Hour + 4*time.Minute + 5*time.Second + 123456*time.Microsecond).Microseconds()))
+	strs := bldr.Field(9).(*array.ListBuilder)
+	strs.Append(true)
+	strs.ValueBuilder().(*array.StringBuilder).Append("a")
+	strs.ValueBuilder().(*array.StringBuilder).AppendNull()
+	strs.ValueBuilder().(*array.StringBuilder).Append("b")
+	st := bldr.Field(10).(*array.StructBuilder)
+	st.Append(true)
+	st.FieldBuilder(0).(*array.StringBuilder).Append("bar")
+	bldr.Field(11).(*array.MonthDayNanoIntervalBuilder).Append(arrow.MonthDayNanoInterval{Months: 1, Days: 2, Nanoseconds: 3})
+	// second row is all nulls
+	for i := range table.Columns {
+		bldr.Field(i).AppendNull()
+	}
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	cl := &Client{}
+	md, dp, err := protoDescriptor(cl.bigQuerySchemaForTable(table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dp == nil {
+		t.Fatal("expected normalized descriptor")
+	}
+
+	rows, err := recordToProtoRows(md, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	want := []string{
+		`{"int":"-7","uint64":"18446744073709551615","string":"foo","uuid":"2c6a3c2b-6d38-4e2a-9f3c-5c7f5a7b2d11","json":"{\"a\":null}",` +
+			`"timestamp":"1704164645123456","timestamp_ns":{"timestamp":"1704164645123456","nanoseconds":"789"},"date":19724,"time":"03:04:05.123456",` +
+			`"strings":["a","b"],"struct":{"foo":"bar"},"interval":{"months":"1","days":"2","nanoseconds":"3"}}`,
+		`{}`,
+	}
+	for i, row := range rows {
+		msg := dynamicpb.NewMessage(md)
+		if err := proto.Unmarshal(row, msg); err != nil {
+			t.Fatal(err)
+		}
+		b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, wantRow any
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(want[i]), &wantRow); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(wantRow, got); diff != "" {
+			t.Errorf("row %d mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestChunkRows(t *testing.T) {
+	rows := [][]byte{make([]byte, 4), make([]byte, 4), make([]byte, 10), make([]byte, 1)}
+	got := chunkRows(rows, 8)
+	want := [][][]byte{rows[0:2], rows[2:3], rows[3:4]}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("chunkRows() mismatch (-want +got):\n%s", diff)
+	}
+	if got := chunkRows(nil, 8); len(got) != 0 {
+		t.Errorf("chunkRows(nil) = %v, want no chunks", got)
+	}
+}
+
+func TestRecordToProtoRowsNestedTypesAsJSON(t *testing.T) {
+	table := &schema.Table{
+		Name: "test",
+		Columns: schema.ColumnList{
+			{Name: "strings", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+			{Name: "struct", Type: arrow.StructOf(arrow.Field{Name: "foo", Type: arrow.BinaryTypes.String, Nullable: true})},
+			{Name: "map", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.BinaryTypes.String)},
+		},
+	}
+
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+	defer bldr.Release()
+	strs := bldr.Field(0).(*array.ListBuilder)
+	strs.Append(true)
+	strs.ValueBuilder().(*array.StringBuilder).Append("a")
+	strs.ValueBuilder().(*array.StringBuilder).AppendNull()
+	st := bldr.Field(1).(*array.StructBuilder)
+	st.Append(true)
+	st.FieldBuilder(0).(*array.StringBuilder).AppendNull()
+	m := bldr.Field(2).(*array.MapBuilder)
+	m.Append(true)
+	m.KeyBuilder().(*array.StringBuilder).Append("k")
+	m.ItemBuilder().(*array.StringBuilder).AppendNull()
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	cl := &Client{spec: Spec{NestedTypesAsJSON: true}}
+	md, _, err := protoDescriptor(cl.bigQuerySchemaForTable(table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := recordToProtoRows(md, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(rows[0], msg); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`["a",null]`, `{"foo":null}`, `[{"key":"k","value":null}]`}
+	for i, w := range want {
+		if got := msg.Get(fieldByIndex(md, i)).String(); got != w {
+			t.Errorf("column %s = %s, want %s", table.Columns[i].Name, got, w)
+		}
+	}
+}
@@ -72,6 +72,10 @@ func appendValue(builder array.Builder, value any) error {
 		builder.AppendNull()
 		return nil
 	}
+	if s, ok := value.(string); ok && arrow.IsNested(builder.Type().ID()) {
+		// maps, as well as structs & lists when `nested_types_as_json` is enabled, are read from JSON columns
+		return builder.UnmarshalOne(json.NewDecoder(strings.NewReader(s)))
+	}
 	switch bldr := builder.(type) {
 	case *array.StructBuilder:
 		m := value.([]bigquery.Value)
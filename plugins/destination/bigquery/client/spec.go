@@ -40,6 +40,27 @@ func (t TimePartitioningOption) Validate() error {
 	return fmt.Errorf("%v is not a valid option for time partitioning. Options are: %v", string(t), TimePartitioningOptions)
 }
 
+type WriteAPIOption string
+
+const (
+	WriteAPIOptionStorageWrite    = WriteAPIOption("storage_write")
+	WriteAPIOptionStreamingInsert = WriteAPIOption("streaming_insert")
+)
+
+var WriteAPIOptions = []WriteAPIOption{
+	WriteAPIOptionStorageWrite,
+	WriteAPIOptionStreamingInsert,
+}
+
+func (w WriteAPIOption) Validate() error {
+	for _, v := range WriteAPIOptions {
+		if w == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("%v is not a valid option for write API. Options are: %v", string(w), WriteAPIOptions)
+}
+
 type Spec struct {
 	// The id of the project where the destination BigQuery database resides.
 	ProjectID string `json:"project_id" jsonschema:"required,minLength=1"`
@@ -55,6 +76,27 @@ type Spec struct {
 	// The time partitioning to use when creating tables. The partition time column used will always be `_cq_sync_time` so that all rows for a sync run will be partitioned on the hour/day the sync started.
 	TimePartitioning TimePartitioningOption `json:"time_partitioning"`
 
+	// Map of table names or glob patterns to the options overriding the way the matching tables are partitioned and clustered, for example:
+	//
+	// ```yaml
+	// tables:
+	//   "aws_cloudtrail_*":
+	//     partition_type: "day"
+	//     partition_column: "event_time"
+	//     partition_expiration: "2160h"
+	//     clustering_fields: ["account_id", "region"]
+	// ```
+	//
+	// Exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+	// The partitioning of existing tables can't be changed, but their clustering and partition expiration are updated during migration.
+	Tables map[string]*TableOptions `json:"tables"`
+
+	// When enabled, struct, map, list and JSON columns are created as native BigQuery `JSON` columns.
+	// This keeps the `null` values of lists, which are otherwise dropped as `REPEATED` columns don't support them,
+	// as well as the `null` fields of structs and maps.
+	// Existing `RECORD` and `REPEATED` columns aren't converted, so the tables need to be dropped and recreated after enabling this option.
+	NestedTypesAsJSON bool `json:"nested_types_as_json"`
+
 	// GCP service account key content.
 	// This allows for using different service accounts for the GCP source and BigQuery destination.
 	// If using service account keys, it is best to use [environment or file variable substitution](/docs/advanced-topics/environment-variable-substitution).
@@ -63,6 +105,19 @@ type Spec struct {
 	// The BigQuery API endpoint to use. This is useful for testing against a local emulator.
 	Endpoint string `json:"endpoint"`
 
+	// The API used to write the rows:
+	//
+	// - `storage_write` uses the [Storage Write API](https://cloud.google.com/bigquery/docs/write-api) default stream.
+	//   The rows are available for DML statements (such as the ones used for `overwrite` and `overwrite-delete-stale` write modes) right after being written.
+	//
+	// - `streaming_insert` uses the [legacy streaming API](https://cloud.google.com/bigquery/docs/streaming-data-into-bigquery), as a fallback for projects that can't use the Storage Write API.
+	//   The rows can't be modified by DML statements while they are in the streaming buffer, so deleting stale rows may fail.
+	WriteAPI WriteAPIOption `json:"write_api"`
+
+	// The BigQuery Storage Write API (gRPC) endpoint to use, in `host:port` format. This is useful for testing against a local emulator.
+	// When set, the connection is made without TLS and authentication.
+	StorageWriteEndpoint string `json:"storage_write_endpoint"`
+
 	// Number of records to write before starting a new object.
 	BatchSize int `json:"batch_size" jsonschema:"minimum=1,default=10000"`
 
@@ -80,6 +135,9 @@ func (s *Spec) SetDefaults() {
 	if s.TimePartitioning == "" {
 		s.TimePartitioning = TimePartitioningOptionNone
 	}
+	if s.WriteAPI == "" {
+		s.WriteAPI = WriteAPIOptionStorageWrite
+	}
 	if s.BatchSize == 0 {
 		s.BatchSize = batchSize
 	}
@@ -101,6 +159,19 @@ func (s *Spec) Validate() error {
 	if err := s.TimePartitioning.Validate(); err != nil {
 		return fmt.Errorf("time_partitioning: %w", err)
 	}
+	if err := s.WriteAPI.Validate(); err != nil {
+		return fmt.Errorf("write_api: %w", err)
+	}
+	for pattern, options := range s.Tables {
+		if options == nil {
+			continue
+		}
+		resolved := *options
+		s.resolveTableOptions(&resolved)
+		if err := resolved.Validate(); err != nil {
+			return fmt.Errorf("tables[%q]: %w", pattern, err)
+		}
+	}
 	if len(s.ServiceAccountKeyJSON) > 0 {
 		if err := isValidJson(s.ServiceAccountKeyJSON); err != nil {
 			return fmt.Errorf("invalid json for service_account_key_json: %w", err)
@@ -130,3 +201,12 @@ func (TimePartitioningOption) JSONSchemaExtend(sc *jsonschema.Schema) {
 		sc.Enum[i] = TimePartitioningOptions[i]
 	}
 }
+
+func (WriteAPIOption) JSONSchemaExtend(sc *jsonschema.Schema) {
+	sc.Type = "string"
+	sc.Default = WriteAPIOptionStorageWrite
+	sc.Enum = make([]any, len(WriteAPIOptions))
+	for i := range WriteAPIOptions {
+		sc.Enum[i] = WriteAPIOptions[i]
+	}
+}
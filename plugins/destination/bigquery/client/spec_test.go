@@ -66,6 +66,38 @@ func TestJSONSchema(t *testing.T) {
 			Name: "spec with proper batch_size",
 			Spec: `{"project_id": "foo", "dataset_id": "bar", "batch_size": 7}`,
 		},
+		{
+			Name: "spec with tables",
+			Spec: `{"project_id": "foo", "dataset_id": "bar", "tables": {"aws_*": {"partition_type": "month", "partition_column": "created_at", "partition_expiration": "720h", "clustering_fields": ["account_id"]}}}`,
+		},
+		{
+			Name: "spec with integer_range partitioning",
+			Spec: `{"project_id": "foo", "dataset_id": "bar", "tables": {"aws_*": {"partition_type": "integer_range", "partition_column": "id", "partition_range": {"start": 0, "end": 100, "interval": 10}}}}`,
+		},
+		{
+			Name: "spec with invalid partition_type",
+			Spec: `{"project_id": "foo", "dataset_id": "bar", "tables": {"aws_*": {"partition_type": "week"}}}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with partition_range without interval",
+			Spec: `{"project_id": "foo", "dataset_id": "bar", "tables": {"aws_*": {"partition_type": "integer_range", "partition_column": "id", "partition_range": {"end": 100}}}}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with too many clustering_fields",
+			Spec: `{"project_id": "foo", "dataset_id": "bar", "tables": {"aws_*": {"clustering_fields": ["a", "b", "c", "d", "e"]}}}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with empty clustering_fields entry",
+			Spec: `{"project_id": "foo", "dataset_id": "bar", "tables": {"aws_*": {"clustering_fields": [""]}}}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with nested_types_as_json",
+			Spec: `{"project_id": "foo", "dataset_id": "bar", "nested_types_as_json": true}`,
+		},
 		{
 			Name: "spec with unknown field",
 			Spec: `{"project_id": "foo", "dataset_id": "bar", "unknown": "test"}`,
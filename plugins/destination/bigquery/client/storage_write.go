@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/bigquery/storage/managedwriter"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// AppendRows requests are limited to 10 MB, so we leave some room for the request metadata
+const maxAppendRowsBytes = 9 * 1024 * 1024
+
+// storageWriteRows writes the rows to the default stream of the table using the Storage Write API.
+func (c *Client) storageWriteRows(ctx context.Context, name string, table *schema.Table, msgs message.WriteInserts) error {
+	md, dp, err := protoDescriptor(c.bigQuerySchemaForTable(table))
+	if err != nil {
+		return fmt.Errorf("failed to build proto descriptor for table %s: %w", table.Name, err)
+	}
+
+	var rows [][]byte
+	for _, msg := range msgs {
+		recordRows, err := recordToProtoRows(md, msg.Record)
+		if err != nil {
+			return fmt.Errorf("failed to convert rows for table %s: %w", table.Name, err)
+		}
+		rows = append(rows, recordRows...)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+
+	for err := c.appendProtoRows(timeoutCtx, name, dp, rows); err != nil; err = c.appendProtoRows(timeoutCtx, name, dp, rows) {
+		// the table may not be visible to the Storage Write API right after being created, so wait a bit and retry until it is
+		if status.Code(err) == codes.NotFound && timeoutCtx.Err() == nil {
+			c.logger.Info().Str("table", name).Msg("Table does not exist yet, waiting for it to be created before retrying write")
+			time.Sleep(1 * time.Second)
+			continue
+		}
+		return fmt.Errorf("failed to write rows to BigQuery table %s: %w", name, err)
+	}
+	return nil
+}
+
+// managedStream is the default stream writer of a table, reused for all the batches written to the table
+type managedStream struct {
+	stream *managedwriter.ManagedStream
+	dp     *descriptorpb.DescriptorProto
+}
+
+func (c *Client) appendProtoRows(ctx context.Context, name string, dp *descriptorpb.DescriptorProto, rows [][]byte) error {
+	ms, err := c.managedStream(ctx, name, dp)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*managedwriter.AppendResult, 0)
+	for _, chunk := range chunkRows(rows, maxAppendRowsBytes) {
+		result, err := ms.AppendRows(ctx, chunk)
+		if err != nil {
+			c.closeManagedStream(name)
+			return err
+		}
+		results = append(results, result)
+	}
+
+	for _, result := range results {
+		resp, err := result.FullResponse(ctx)
+		if err != nil {
+			c.closeManagedStream(name)
+			return err
+		}
+		if rowErrors := resp.GetRowErrors(); len(rowErrors) > 0 {
+			return fmt.Errorf("%d rows were rejected, first error (row %d): %s", len(rowErrors), rowErrors[0].GetIndex(), rowErrors[0].GetMessage())
+		}
+	}
+	return nil
+}
+
+// managedStream returns the default stream writer of the table, opening a new one if there's none or the table schema changed.
+func (c *Client) managedStream(ctx context.Context, name string, dp *descriptorpb.DescriptorProto) (*managedwriter.ManagedStream, error) {
+	c.streamsMu.Lock()
+	defer c.streamsMu.Unlock()
+	if ms, ok := c.streams[name]; ok {
+		if proto.Equal(ms.dp, dp) {
+			return ms.stream, nil
+		}
+		c.closeManagedStreamLocked(name)
+	}
+
+	// the stream outlives the batch, so it isn't bound to the batch timeout
+	stream, err := c.writeClient.NewManagedStream(context.WithoutCancel(ctx),
+		managedwriter.WithDestinationTable(managedwriter.TableParentFromParts(c.spec.ProjectID, c.spec.DatasetID, name)),
+		managedwriter.WithType(managedwriter.DefaultStream),
+		managedwriter.WithSchemaDescriptor(dp),
+		managedwriter.EnableWriteRetries(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.streams[name] = &managedStream{stream: stream, dp: dp}
+	return stream, nil
+}
+
+// closeManagedStream closes the default stream writer of the table, if it's open.
+// It's used when the stream failed, and when the table is migrated, as it may have been recreated.
+func (c *Client) closeManagedStream(name string) {
+	c.streamsMu.Lock()
+	defer c.streamsMu.Unlock()
+	c.closeManagedStreamLocked(name)
+}
+
+func (c *Client) closeManagedStreamLocked(name string) {
+	if ms, ok := c.streams[name]; ok {
+		if err := ms.stream.Close(); err != nil {
+			c.logger.Debug().Err(err).Str("table", name).Msg("Failed to close Storage Write API stream")
+		}
+		delete(c.streams, name)
+	}
+}
+
+// closeManagedStreams closes the default stream writers of all the tables.
+func (c *Client) closeManagedStreams() error {
+	c.streamsMu.Lock()
+	defer c.streamsMu.Unlock()
+	var errs []error
+	for name, ms := range c.streams {
+		if err := ms.stream.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close Storage Write API stream of table %s: %w", name, err))
+		}
+		delete(c.streams, name)
+	}
+	return errors.Join(errs...)
+}
+
+// chunkRows splits the serialized rows into chunks of at most maxBytes in total (but at least one row each).
+func chunkRows(rows [][]byte, maxBytes int) [][][]byte {
+	var chunks [][][]byte
+	start, size := 0, 0
+	for i, row := range rows {
+		if i > start && size+len(row) > maxBytes {
+			chunks = append(chunks, rows[start:i])
+			start, size = i, 0
+		}
+		size += len(row)
+	}
+	if start < len(rows) {
+		chunks = append(chunks, rows[start:])
+	}
+	return chunks
+}
@@ -0,0 +1,277 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/cloudquery/plugin-sdk/v4/configtype"
+	"github.com/cloudquery/plugin-sdk/v4/glob"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/invopop/jsonschema"
+)
+
+// BigQuery allows up to 4 clustering columns
+const maxClusteringFields = 4
+
+type PartitionTypeOption string
+
+const (
+	PartitionTypeOptionNone         = PartitionTypeOption("none")
+	PartitionTypeOptionHour         = PartitionTypeOption("hour")
+	PartitionTypeOptionDay          = PartitionTypeOption("day")
+	PartitionTypeOptionMonth        = PartitionTypeOption("month")
+	PartitionTypeOptionYear         = PartitionTypeOption("year")
+	PartitionTypeOptionIntegerRange = PartitionTypeOption("integer_range")
+)
+
+var PartitionTypeOptions = []PartitionTypeOption{
+	PartitionTypeOptionNone,
+	PartitionTypeOptionHour,
+	PartitionTypeOptionDay,
+	PartitionTypeOptionMonth,
+	PartitionTypeOptionYear,
+	PartitionTypeOptionIntegerRange,
+}
+
+func (p PartitionTypeOption) Validate() error {
+	for _, v := range PartitionTypeOptions {
+		if p == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("%v is not a valid option for partition type. Options are: %v", string(p), PartitionTypeOptions)
+}
+
+func (p PartitionTypeOption) isTime() bool {
+	switch p {
+	case PartitionTypeOptionHour, PartitionTypeOptionDay, PartitionTypeOptionMonth, PartitionTypeOptionYear:
+		return true
+	default:
+		return false
+	}
+}
+
+func (PartitionTypeOption) JSONSchemaExtend(sc *jsonschema.Schema) {
+	sc.Type = "string"
+	sc.Enum = make([]any, len(PartitionTypeOptions))
+	for i := range PartitionTypeOptions {
+		sc.Enum[i] = PartitionTypeOptions[i]
+	}
+}
+
+// IntegerRange defines the partitions of `integer_range` partitioned tables.
+type IntegerRange struct {
+	// The start of the range, inclusive.
+	Start int64 `json:"start"`
+
+	// The end of the range, exclusive.
+	End int64 `json:"end" jsonschema:"required"`
+
+	// The width of each partition.
+	Interval int64 `json:"interval" jsonschema:"required,minimum=1"`
+}
+
+// TableOptions allows to override the way the matching tables are created.
+type TableOptions struct {
+	// The partitioning to use for the matching tables:
+	//
+	// - `hour`, `day`, `month` & `year` partition the table by the `TIMESTAMP`, `DATE` or `DATETIME` column set in `partition_column`.
+	//
+	// - `integer_range` partitions the table by the `INTEGER` column set in `partition_column` into the ranges defined by `partition_range`.
+	//
+	// - `none` disables partitioning.
+	//
+	// If not set, `time_partitioning` is used.
+	PartitionType PartitionTypeOption `json:"partition_type,omitempty"`
+
+	// The column to partition the table by.
+	// Defaults to `_cq_sync_time` for time partitioning, and is required for `integer_range` partitioning.
+	PartitionColumn string `json:"partition_column,omitempty"`
+
+	// The ranges to partition the table into. Required for `integer_range` partitioning.
+	PartitionRange *IntegerRange `json:"partition_range,omitempty"`
+
+	// How long to keep the partitions of time partitioned tables for, for example `720h`.
+	// The rows of expired partitions are deleted by BigQuery.
+	// If not set, the partitions don't expire.
+	PartitionExpiration configtype.Duration `json:"partition_expiration,omitempty"`
+
+	// The columns to [cluster](https://cloud.google.com/bigquery/docs/clustered-tables) the table by, in order.
+	// Up to 4 columns can be used.
+	ClusteringFields []string `json:"clustering_fields,omitempty" jsonschema:"minLength=1,maxItems=4"`
+}
+
+// Validate checks the options, with the partition type already resolved.
+func (o *TableOptions) Validate() error {
+	if err := o.PartitionType.Validate(); err != nil {
+		return fmt.Errorf("partition_type: %w", err)
+	}
+
+	if o.PartitionType == PartitionTypeOptionIntegerRange {
+		if len(o.PartitionColumn) == 0 {
+			return fmt.Errorf("partition_column is required for integer_range partitioning")
+		}
+		if o.PartitionRange == nil {
+			return fmt.Errorf("partition_range is required for integer_range partitioning")
+		}
+		if o.PartitionRange.Interval <= 0 {
+			return fmt.Errorf("partition_range: interval must be positive")
+		}
+		if o.PartitionRange.End <= o.PartitionRange.Start {
+			return fmt.Errorf("partition_range: end must be greater than start")
+		}
+	} else if o.PartitionRange != nil {
+		return fmt.Errorf("partition_range is only supported for integer_range partitioning")
+	}
+
+	if o.PartitionType == PartitionTypeOptionNone && len(o.PartitionColumn) > 0 {
+		return fmt.Errorf("partition_column requires partitioning")
+	}
+
+	switch expiration := o.PartitionExpiration.Duration(); {
+	case expiration < 0:
+		return fmt.Errorf("partition_expiration must not be negative")
+	case expiration > 0 && !o.PartitionType.isTime():
+		return fmt.Errorf("partition_expiration is only supported for time partitioning")
+	}
+
+	if len(o.ClusteringFields) > maxClusteringFields {
+		return fmt.Errorf("up to %d clustering_fields are supported, got %d", maxClusteringFields, len(o.ClusteringFields))
+	}
+	for _, field := range o.ClusteringFields {
+		if len(field) == 0 {
+			return fmt.Errorf("empty clustering_fields entry")
+		}
+	}
+
+	return nil
+}
+
+// validateColumns checks that the partitioning & clustering columns are present in the table schema and have supported types.
+func (o *TableOptions) validateColumns(s bigquery.Schema) error {
+	fields := make(map[string]*bigquery.FieldSchema, len(s))
+	for _, f := range s {
+		fields[f.Name] = f
+	}
+
+	if len(o.PartitionColumn) > 0 {
+		f, ok := fields[o.PartitionColumn]
+		if !ok {
+			return fmt.Errorf("partition column %q not found", o.PartitionColumn)
+		}
+		switch {
+		case f.Repeated:
+			return fmt.Errorf("partition column %q can't be a repeated column", o.PartitionColumn)
+		case o.PartitionType.isTime() && f.Type != bigquery.TimestampFieldType && f.Type != bigquery.DateFieldType && f.Type != bigquery.DateTimeFieldType:
+			return fmt.Errorf("partition column %q must be of TIMESTAMP, DATE or DATETIME type for time partitioning, got %s", o.PartitionColumn, f.Type)
+		case o.PartitionType == PartitionTypeOptionIntegerRange && f.Type != bigquery.IntegerFieldType:
+			return fmt.Errorf("partition column %q must be of INTEGER type for integer_range partitioning, got %s", o.PartitionColumn, f.Type)
+		}
+	}
+
+	for _, name := range o.ClusteringFields {
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("clustering column %q not found", name)
+		}
+	}
+	return nil
+}
+
+func (o *TableOptions) timePartitioning() *bigquery.TimePartitioning {
+	var partitioningType bigquery.TimePartitioningType
+	switch o.PartitionType {
+	case PartitionTypeOptionHour:
+		partitioningType = bigquery.HourPartitioningType
+	case PartitionTypeOptionDay:
+		partitioningType = bigquery.DayPartitioningType
+	case PartitionTypeOptionMonth:
+		partitioningType = bigquery.MonthPartitioningType
+	case PartitionTypeOptionYear:
+		partitioningType = bigquery.YearPartitioningType
+	default:
+		return nil
+	}
+	return &bigquery.TimePartitioning{
+		Type:       partitioningType,
+		Field:      o.PartitionColumn,
+		Expiration: o.PartitionExpiration.Duration(),
+	}
+}
+
+func (o *TableOptions) rangePartitioning() *bigquery.RangePartitioning {
+	if o.PartitionType != PartitionTypeOptionIntegerRange {
+		return nil
+	}
+	return &bigquery.RangePartitioning{
+		Field: o.PartitionColumn,
+		Range: &bigquery.RangePartitioningRange{
+			Start:    o.PartitionRange.Start,
+			End:      o.PartitionRange.End,
+			Interval: o.PartitionRange.Interval,
+		},
+	}
+}
+
+func (o *TableOptions) clustering() *bigquery.Clustering {
+	if len(o.ClusteringFields) == 0 {
+		return nil
+	}
+	return &bigquery.Clustering{Fields: o.ClusteringFields}
+}
+
+func (s *Spec) tablePatterns() []string {
+	patterns := make([]string, 0, len(s.Tables))
+	for pattern := range s.Tables {
+		patterns = append(patterns, pattern)
+	}
+	// longer patterns are more specific, so they're matched first
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return patterns
+}
+
+// MatchTableOptions returns the `tables` entry matching the table, or nil if there's none.
+// Exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+func (s *Spec) MatchTableOptions(table string) *TableOptions {
+	if options, ok := s.Tables[table]; ok {
+		return options
+	}
+	for _, pattern := range s.tablePatterns() {
+		if glob.Glob(pattern, table) {
+			return s.Tables[pattern]
+		}
+	}
+	return nil
+}
+
+// TableOptions returns the options the table is created with:
+// the matching `tables` entry with the `time_partitioning` default applied.
+func (s *Spec) TableOptions(table string) *TableOptions {
+	var options TableOptions
+	if matched := s.MatchTableOptions(table); matched != nil {
+		options = *matched
+	}
+	s.resolveTableOptions(&options)
+	return &options
+}
+
+func (s *Spec) resolveTableOptions(options *TableOptions) {
+	if len(options.PartitionType) == 0 {
+		switch s.TimePartitioning {
+		case TimePartitioningOptionHour:
+			options.PartitionType = PartitionTypeOptionHour
+		case TimePartitioningOptionDay:
+			options.PartitionType = PartitionTypeOptionDay
+		default:
+			options.PartitionType = PartitionTypeOptionNone
+		}
+	}
+	if options.PartitionType.isTime() && len(options.PartitionColumn) == 0 {
+		options.PartitionColumn = schema.CqSyncTimeColumn.Name
+	}
+}
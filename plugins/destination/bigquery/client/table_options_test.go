@@ -0,0 +1,211 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/cloudquery/plugin-sdk/v4/configtype"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSpec_TableOptions(t *testing.T) {
+	s := Spec{
+		TimePartitioning: TimePartitioningOptionDay,
+		Tables: map[string]*TableOptions{
+			"aws_*": {ClusteringFields: []string{"account_id"}},
+			"aws_ec2_*": {
+				PartitionType:       PartitionTypeOptionMonth,
+				PartitionColumn:     "launch_time",
+				PartitionExpiration: configtype.NewDuration(720 * time.Hour),
+			},
+			"aws_ec2_instances": {
+				PartitionType:   PartitionTypeOptionIntegerRange,
+				PartitionColumn: "ami_launch_index",
+				PartitionRange:  &IntegerRange{Start: 0, End: 100, Interval: 10},
+			},
+			"gcp_*": {PartitionType: PartitionTypeOptionNone},
+		},
+	}
+
+	cases := []struct {
+		table string
+		want  TableOptions
+	}{
+		{
+			table: "azure_compute_vms",
+			want:  TableOptions{PartitionType: PartitionTypeOptionDay, PartitionColumn: "_cq_sync_time"},
+		},
+		{
+			table: "aws_s3_buckets",
+			want:  TableOptions{PartitionType: PartitionTypeOptionDay, PartitionColumn: "_cq_sync_time", ClusteringFields: []string{"account_id"}},
+		},
+		{
+			table: "aws_ec2_images",
+			want: TableOptions{
+				PartitionType:       PartitionTypeOptionMonth,
+				PartitionColumn:     "launch_time",
+				PartitionExpiration: configtype.NewDuration(720 * time.Hour),
+			},
+		},
+		{
+			table: "aws_ec2_instances",
+			want: TableOptions{
+				PartitionType:   PartitionTypeOptionIntegerRange,
+				PartitionColumn: "ami_launch_index",
+				PartitionRange:  &IntegerRange{Start: 0, End: 100, Interval: 10},
+			},
+		},
+		{
+			table: "gcp_compute_instances",
+			want:  TableOptions{PartitionType: PartitionTypeOptionNone},
+		},
+	}
+	for _, tc := range cases {
+		got := s.TableOptions(tc.table)
+		if diff := cmp.Diff(tc.want, *got, cmp.AllowUnexported(configtype.Duration{})); diff != "" {
+			t.Errorf("TableOptions(%q) mismatch (-want +got):\n%s", tc.table, diff)
+		}
+	}
+}
+
+func TestSpec_ValidateTables(t *testing.T) {
+	cases := []struct {
+		name    string
+		options TableOptions
+		wantErr bool
+	}{
+		{name: "clustering only", options: TableOptions{ClusteringFields: []string{"a", "b"}}},
+		{name: "time partitioning", options: TableOptions{PartitionType: PartitionTypeOptionYear, PartitionExpiration: configtype.NewDuration(time.Hour)}},
+		{name: "integer range", options: TableOptions{PartitionType: PartitionTypeOptionIntegerRange, PartitionColumn: "id", PartitionRange: &IntegerRange{End: 10, Interval: 1}}},
+		{name: "integer range without column", options: TableOptions{PartitionType: PartitionTypeOptionIntegerRange, PartitionRange: &IntegerRange{End: 10, Interval: 1}}, wantErr: true},
+		{name: "integer range without range", options: TableOptions{PartitionType: PartitionTypeOptionIntegerRange, PartitionColumn: "id"}, wantErr: true},
+		{name: "integer range with empty range", options: TableOptions{PartitionType: PartitionTypeOptionIntegerRange, PartitionColumn: "id", PartitionRange: &IntegerRange{Start: 10, End: 10, Interval: 1}}, wantErr: true},
+		{name: "integer range with expiration", options: TableOptions{PartitionType: PartitionTypeOptionIntegerRange, PartitionColumn: "id", PartitionRange: &IntegerRange{End: 10, Interval: 1}, PartitionExpiration: configtype.NewDuration(time.Hour)}, wantErr: true},
+		{name: "range with time partitioning", options: TableOptions{PartitionType: PartitionTypeOptionDay, PartitionRange: &IntegerRange{End: 10, Interval: 1}}, wantErr: true},
+		{name: "expiration without partitioning", options: TableOptions{PartitionExpiration: configtype.NewDuration(time.Hour)}, wantErr: true},
+		{name: "column without partitioning", options: TableOptions{PartitionType: PartitionTypeOptionNone, PartitionColumn: "id"}, wantErr: true},
+		{name: "invalid partition type", options: TableOptions{PartitionType: "week"}, wantErr: true},
+		{name: "too many clustering fields", options: TableOptions{ClusteringFields: []string{"a", "b", "c", "d", "e"}}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Spec{ProjectID: "project", DatasetID: "dataset", Tables: map[string]*TableOptions{"test": &tc.options}}
+			s.SetDefaults()
+			err := s.Validate()
+			if tc.wantErr && err == nil {
+				t.Errorf("expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTableOptions_ValidateColumns(t *testing.T) {
+	s := bigquery.Schema{
+		{Name: "_cq_sync_time", Type: bigquery.TimestampFieldType},
+		{Name: "id", Type: bigquery.IntegerFieldType},
+		{Name: "ids", Type: bigquery.IntegerFieldType, Repeated: true},
+		{Name: "name", Type: bigquery.StringFieldType},
+	}
+	cases := []struct {
+		name    string
+		options TableOptions
+		wantErr bool
+	}{
+		{name: "time partitioning", options: TableOptions{PartitionType: PartitionTypeOptionDay, PartitionColumn: "_cq_sync_time", ClusteringFields: []string{"name", "id"}}},
+		{name: "integer range", options: TableOptions{PartitionType: PartitionTypeOptionIntegerRange, PartitionColumn: "id"}},
+		{name: "missing partition column", options: TableOptions{PartitionType: PartitionTypeOptionDay, PartitionColumn: "created_at"}, wantErr: true},
+		{name: "time partitioning by string", options: TableOptions{PartitionType: PartitionTypeOptionDay, PartitionColumn: "name"}, wantErr: true},
+		{name: "integer range by timestamp", options: TableOptions{PartitionType: PartitionTypeOptionIntegerRange, PartitionColumn: "_cq_sync_time"}, wantErr: true},
+		{name: "integer range by repeated", options: TableOptions{PartitionType: PartitionTypeOptionIntegerRange, PartitionColumn: "ids"}, wantErr: true},
+		{name: "missing clustering column", options: TableOptions{ClusteringFields: []string{"region"}}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.options.validateColumns(s)
+			if tc.wantErr && err == nil {
+				t.Errorf("expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPartitioningMatches(t *testing.T) {
+	options := TableOptions{PartitionType: PartitionTypeOptionIntegerRange, PartitionColumn: "id", PartitionRange: &IntegerRange{End: 10, Interval: 1}}
+	cases := []struct {
+		name    string
+		md      bigquery.TableMetadata
+		options TableOptions
+		want    bool
+	}{
+		{name: "not partitioned", options: TableOptions{PartitionType: PartitionTypeOptionNone}, want: true},
+		{name: "time partitioned", md: bigquery.TableMetadata{TimePartitioning: &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "_cq_sync_time"}}, options: TableOptions{PartitionType: PartitionTypeOptionDay, PartitionColumn: "_cq_sync_time"}, want: true},
+		{name: "different time partitioning", md: bigquery.TableMetadata{TimePartitioning: &bigquery.TimePartitioning{Type: bigquery.HourPartitioningType, Field: "_cq_sync_time"}}, options: TableOptions{PartitionType: PartitionTypeOptionDay, PartitionColumn: "_cq_sync_time"}},
+		{name: "range partitioned", md: bigquery.TableMetadata{RangePartitioning: options.rangePartitioning()}, options: options, want: true},
+		{name: "different range", md: bigquery.TableMetadata{RangePartitioning: &bigquery.RangePartitioning{Field: "id", Range: &bigquery.RangePartitioningRange{End: 20, Interval: 1}}}, options: options},
+		{name: "partitioning added", options: options},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := partitioningMatches(&tc.md, &tc.options); got != tc.want {
+				t.Errorf("partitioningMatches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpdateTableOptions(t *testing.T) {
+	c := &Client{spec: Spec{
+		Tables: map[string]*TableOptions{
+			"expiring":     {PartitionType: PartitionTypeOptionDay, PartitionExpiration: configtype.NewDuration(720 * time.Hour)},
+			"not_expiring": {PartitionType: PartitionTypeOptionDay},
+		},
+	}}
+	cases := []struct {
+		name              string
+		table             string
+		expiration        time.Duration
+		wantUpdate        bool
+		wantNewExpiration time.Duration
+	}{
+		{name: "add expiration", table: "expiring", wantUpdate: true, wantNewExpiration: 720 * time.Hour},
+		{name: "change expiration", table: "expiring", expiration: time.Hour, wantUpdate: true, wantNewExpiration: 720 * time.Hour},
+		{name: "same expiration", table: "expiring", expiration: 720 * time.Hour},
+		// the expiration is removed by sending a zero expiration
+		{name: "remove expiration", table: "not_expiring", expiration: time.Hour, wantUpdate: true},
+		{name: "no expiration", table: "not_expiring"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			md := &bigquery.TableMetadata{TimePartitioning: &bigquery.TimePartitioning{
+				Type:                   bigquery.DayPartitioningType,
+				Field:                  "_cq_sync_time",
+				Expiration:             tc.expiration,
+				RequirePartitionFilter: true,
+			}}
+			var tm bigquery.TableMetadataToUpdate
+			c.updateTableOptions(&tm, md, c.spec.MatchTableOptions(tc.table), tc.table)
+			if !tc.wantUpdate {
+				if tm.TimePartitioning != nil {
+					t.Fatalf("TimePartitioning = %+v, want nil", tm.TimePartitioning)
+				}
+				return
+			}
+			want := &bigquery.TimePartitioning{
+				Type:                   bigquery.DayPartitioningType,
+				Field:                  "_cq_sync_time",
+				Expiration:             tc.wantNewExpiration,
+				RequirePartitionFilter: true,
+			}
+			if diff := cmp.Diff(want, tm.TimePartitioning); diff != "" {
+				t.Errorf("TimePartitioning mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -24,7 +24,7 @@ func (c *Client) ColumnToBigQuerySchema(col schema.Column) *bigquery.FieldSchema
 
 	// TODO: handle repeated; we currently don't handle the case where we get a list of lists,
 	//       but that's not a valid case right now and isn't being explicitly tested for.
-	if isListType(col.Type) {
+	if isListType(col.Type) && !c.nestedAsJSON(col.Type) {
 		sc.Repeated = true
 	}
 	return &sc
@@ -34,8 +34,15 @@ func isListType(t arrow.DataType) bool {
 	return t.ID() == arrow.LIST || t.ID() == arrow.LARGE_LIST || t.ID() == arrow.FIXED_SIZE_LIST
 }
 
+// nestedAsJSON reports whether the values of the type are stored as native BigQuery `JSON`, as set by `nested_types_as_json`.
+func (c *Client) nestedAsJSON(dataType arrow.DataType) bool {
+	return c.spec.NestedTypesAsJSON && arrow.IsNested(dataType.ID())
+}
+
 func (c *Client) DataTypeToBigQueryType(dataType arrow.DataType) bigquery.FieldType {
 	switch {
+	case c.nestedAsJSON(dataType):
+		return bigquery.JSONFieldType
 	// handle known extensions that require special handling
 	case typeOneOf(dataType,
 		types.ExtensionTypes.JSON):
@@ -138,6 +145,8 @@ func (c *Client) DataTypeToBigQueryType(dataType arrow.DataType) bigquery.FieldT
 
 func (c *Client) DataTypeToBigQuerySchema(dataType arrow.DataType) bigquery.Schema {
 	switch {
+	case c.nestedAsJSON(dataType):
+		return nil
 	case dataType.ID() == arrow.STRUCT:
 		v := dataType.(*arrow.StructType)
 		fields := make([]*bigquery.FieldSchema, len(v.Fields()))
@@ -51,3 +51,24 @@ func TestClient_DataTypeToBigQueryType(t *testing.T) {
 		}
 	}
 }
+
+func TestClient_ColumnToBigQuerySchemaNestedTypesAsJSON(t *testing.T) {
+	cases := []struct {
+		col  schema.Column
+		want *bigquery.FieldSchema
+	}{
+		{col: schema.Column{Name: "string", Type: arrow.BinaryTypes.String}, want: &bigquery.FieldSchema{Name: "string", Type: bigquery.StringFieldType}},
+		{col: schema.Column{Name: "json", Type: types.ExtensionTypes.JSON}, want: &bigquery.FieldSchema{Name: "json", Type: bigquery.JSONFieldType}},
+		{col: schema.Column{Name: "string_list", Type: arrow.ListOf(arrow.BinaryTypes.String)}, want: &bigquery.FieldSchema{Name: "string_list", Type: bigquery.JSONFieldType}},
+		{col: schema.Column{Name: "map", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.BinaryTypes.String)}, want: &bigquery.FieldSchema{Name: "map", Type: bigquery.JSONFieldType}},
+		{col: schema.Column{Name: "struct", Type: arrow.StructOf([]arrow.Field{{Name: "foo", Type: arrow.BinaryTypes.String}}...)}, want: &bigquery.FieldSchema{Name: "struct", Type: bigquery.JSONFieldType}},
+		{col: schema.Column{Name: "timestamp_ns", Type: arrow.FixedWidthTypes.Timestamp_ns}, want: &bigquery.FieldSchema{Name: "timestamp_ns", Type: bigquery.RecordFieldType, Schema: bigquery.Schema{{Name: "timestamp", Type: bigquery.TimestampFieldType}, {Name: "nanoseconds", Type: bigquery.IntegerFieldType}}}},
+	}
+	cl := &Client{spec: Spec{NestedTypesAsJSON: true}}
+	for _, c := range cases {
+		got := cl.ColumnToBigQuerySchema(c.col)
+		if diff := cmp.Diff(got, c.want); diff != "" {
+			t.Errorf("ColumnToBigQuerySchema(%v) mismatch (-got +want):\n%s", c.col, diff)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/arrow/array"
 	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
 	"github.com/cloudquery/plugin-sdk/v4/types"
 	"google.golang.org/api/googleapi"
 )
@@ -34,10 +35,32 @@ func (c *Client) Write(ctx context.Context, res <-chan message.WriteMessage) err
 	if err := c.writer.Flush(ctx); err != nil {
 		return fmt.Errorf("failed to flush: %w", err)
 	}
-	return nil
+	return c.mergeStaging(ctx)
 }
 
 func (c *Client) WriteTableBatch(ctx context.Context, name string, msgs message.WriteInserts) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	table := msgs[0].GetTable()
+	if len(table.PrimaryKeys()) > 0 {
+		// `overwrite` write mode
+		return c.upsertRows(ctx, table, msgs)
+	}
+	return c.appendRows(ctx, name, table, msgs)
+}
+
+// appendRows writes the rows to the BigQuery table with the API configured in `write_api`.
+// The table may be a staging table, so its name is passed separately.
+func (c *Client) appendRows(ctx context.Context, name string, table *schema.Table, msgs message.WriteInserts) error {
+	if c.spec.WriteAPI == WriteAPIOptionStreamingInsert {
+		return c.insertRows(ctx, name, msgs)
+	}
+	return c.storageWriteRows(ctx, name, table, msgs)
+}
+
+func (c *Client) insertRows(ctx context.Context, name string, msgs message.WriteInserts) error {
 	inserter := c.client.Dataset(c.spec.DatasetID).Table(name).Inserter()
 	inserter.IgnoreUnknownValues = true
 	inserter.SkipInvalidRows = false
@@ -47,10 +70,19 @@ func (c *Client) WriteTableBatch(ctx context.Context, name string, msgs message.
 		for i := 0; i < int(rec.NumRows()); i++ {
 			saver := &item{cols: make(map[string]bigquery.Value, rec.NumCols())}
 			for n, col := range rec.Columns() {
-				if col.IsValid(i) {
+				if !col.IsValid(i) {
 					// save some bandwidth by not sending nil values
-					saver.cols[rec.ColumnName(n)] = getValueForBigQuery(col, i)
+					continue
+				}
+				if c.nestedAsJSON(col.DataType()) {
+					value, err := nestedJSONValue(col, i)
+					if err != nil {
+						return fmt.Errorf("failed to convert column %s: %w", rec.ColumnName(n), err)
+					}
+					saver.cols[rec.ColumnName(n)] = value
+					continue
 				}
+				saver.cols[rec.ColumnName(n)] = getValueForBigQuery(col, i)
 			}
 			batch = append(batch, saver)
 		}
@@ -73,6 +105,15 @@ func (c *Client) WriteTableBatch(ctx context.Context, name string, msgs message.
 	return nil
 }
 
+// nestedJSONValue serializes struct, map & list values to JSON, keeping the null values.
+func nestedJSONValue(col arrow.Array, i int) (string, error) {
+	b, err := json.Marshal(col.GetOneForMarshal(i))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
 func getValueForBigQuery(col arrow.Array, i int) any {
 	switch v := col.(type) {
 	case *array.Struct:
@@ -97,9 +138,8 @@ func getValueForBigQuery(col arrow.Array, i int) any {
 			}
 			if slc.IsNull(j) {
 				// LIMITATION: BigQuery does not support null values in repeated columns.
-				// Therefore, these get stripped out here. In the future, perhaps we should support
-				// an option to use JSON instead of repeated columns for users who need to preserve
-				// the null values.
+				// Therefore, these get stripped out here. Users who need to preserve the null values
+				// can enable `nested_types_as_json`.
 				continue
 			}
 			elems = append(elems, getValueForBigQuery(slc, j))
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/client/spec"
+	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/queries"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+func (c *Client) DeleteStale(ctx context.Context, messages message.WriteDeleteStales) error {
+	for _, msg := range messages {
+		if err := c.deleteStale(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteStale removes the rows from the local table (on every shard, if `distributed` is enabled),
+// as neither lightweight deletes nor partition manipulations are supported for distributed tables.
+func (c *Client) deleteStale(ctx context.Context, msg *message.WriteDeleteStale) error {
+	localTable := c.spec.LocalTableName(msg.TableName)
+	if c.spec.DeleteStaleMode == spec.DeleteStaleModeDropPartition {
+		partitioned, err := c.partitionedForDeleteStale(ctx, localTable)
+		if err != nil {
+			return err
+		}
+		if partitioned {
+			return c.dropStalePartitions(ctx, msg)
+		}
+		c.logger.Warn().Str("table", msg.TableName).
+			Msg("Table isn't partitioned by source name and sync time, falling back to delete")
+	}
+
+	query, params := queries.DeleteStale(localTable, c.spec.Cluster, msg.SourceName, msg.SyncTime)
+	if err := c.conn.Exec(ctx, query, params...); err != nil {
+		return fmt.Errorf("failed to delete stale rows from table %q: %w", msg.TableName, err)
+	}
+	return nil
+}
+
+func (c *Client) partitionedForDeleteStale(ctx context.Context, table string) (bool, error) {
+	query, params := queries.GetPartitionKey(c.database, table)
+	var partitionKey string
+	if err := c.conn.QueryRow(ctx, query, params...).Scan(&partitionKey); err != nil {
+		return false, fmt.Errorf("failed to read partition key of table %q: %w", table, err)
+	}
+	return queries.PartitionedBy(partitionKey, []string{schema.CqSourceNameColumn.Name, schema.CqSyncTimeColumn.Name}), nil
+}
+
+func (c *Client) dropStalePartitions(ctx context.Context, msg *message.WriteDeleteStale) error {
+	// the distributed table (if any) lists the partitions from all the shards
+	query, params := queries.StalePartitions(msg.TableName, msg.SourceName, msg.SyncTime)
+	rows, err := c.conn.Query(ctx, query, params...)
+	if err != nil {
+		return fmt.Errorf("failed to list stale partitions of table %q: %w", msg.TableName, err)
+	}
+
+	var partitionIDs []string
+	for rows.Next() {
+		var partitionID string
+		if err := rows.Scan(&partitionID); err != nil {
+			rows.Close()
+			return err
+		}
+		partitionIDs = append(partitionIDs, partitionID)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, partitionID := range partitionIDs {
+		c.logger.Debug().Str("table", msg.TableName).Str("partition", partitionID).Msg("Dropping stale partition")
+		if err := c.conn.Exec(ctx, queries.DropPartition(c.spec.LocalTableName(msg.TableName), c.spec.Cluster, partitionID)); err != nil {
+			return fmt.Errorf("failed to drop stale partition %q of table %q: %w", partitionID, msg.TableName, err)
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"slices"
 
+	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/client/spec"
 	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/queries"
 	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/typeconv"
 	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/util"
@@ -21,12 +22,17 @@ func (c *Client) MigrateTables(ctx context.Context, messages message.WriteMigrat
 		return err
 	}
 
+	haveKeys, err := c.getTablesKeys(ctx, messages)
+	if err != nil {
+		return err
+	}
+
 	want, err := typeconv.CanonizedTables(messages)
 	if err != nil {
 		return err
 	}
 
-	if err := c.checkForced(have, want, messages); err != nil {
+	if err := c.checkForced(have, haveKeys, want, messages); err != nil {
 		return err
 	}
 
@@ -51,14 +57,14 @@ func (c *Client) MigrateTables(ctx context.Context, messages message.WriteMigrat
 				return c.createTable(ctx, want)
 			}
 
-			return c.autoMigrate(ctx, have, want)
+			return c.autoMigrate(ctx, have, haveKeys, want)
 		})
 	}
 
 	return eg.Wait()
 }
 
-func (c *Client) checkForced(have, want schema.Tables, messages message.WriteMigrateTables) error {
+func (c *Client) checkForced(have schema.Tables, haveKeys map[string]queries.TableKeys, want schema.Tables, messages message.WriteMigrateTables) error {
 	forcedErr := false
 	for _, m := range messages {
 		if m.MigrateForce {
@@ -78,6 +84,13 @@ func (c *Client) checkForced(have, want schema.Tables, messages message.WriteMig
 				Msg("migrate manually or consider using 'migrate_mode: forced'")
 			forcedErr = true
 		}
+		if keysChanges := c.keysChanges(want, haveKeys); len(keysChanges) > 0 {
+			c.logger.Error().
+				Str("table", m.Table.Name).
+				Strs("changes", keysChanges).
+				Msg("migrate manually or consider using 'migrate_mode: forced'")
+			forcedErr = true
+		}
 	}
 
 	if forcedErr {
@@ -86,6 +99,41 @@ func (c *Client) checkForced(have, want schema.Tables, messages message.WriteMig
 	return nil
 }
 
+// keysChanges lists the changes to the table keys explicitly set in the `tables` spec option
+// and the switch to distributed tables, as those require the table to be recreated.
+func (c *Client) keysChanges(table *schema.Table, haveKeys map[string]queries.TableKeys) []string {
+	have, ok := haveKeys[table.Name]
+	if !ok {
+		if c.spec.Distributed {
+			return []string{"distributed: local table " + c.spec.LocalTableName(table.Name) + " doesn't exist"}
+		}
+		return nil
+	}
+
+	matched := c.spec.MatchTableOptions(table.Name)
+	if matched == nil {
+		return nil
+	}
+	options := *matched
+	if c.spec.Distributed && len(options.Engine) > 0 {
+		options.Engine = spec.ReplicatedEngine(options.Engine)
+	}
+	return queries.KeysChanges(table, have, &options)
+}
+
+// optionsChanges lists the changes to the TTL and settings explicitly set in the `tables` spec option.
+func (c *Client) optionsChanges(table *schema.Table, haveKeys map[string]queries.TableKeys) []string {
+	have, ok := haveKeys[table.Name]
+	if !ok {
+		return nil
+	}
+	matched := c.spec.MatchTableOptions(table.Name)
+	if matched == nil {
+		return nil
+	}
+	return queries.OptionsChanges(have, matched)
+}
+
 func unsafeChanges(changes []schema.TableColumnChange) []schema.TableColumnChange {
 	unsafe := make([]schema.TableColumnChange, 0, len(changes))
 	for _, c := range changes {
@@ -99,7 +147,9 @@ func unsafeChanges(changes []schema.TableColumnChange) []schema.TableColumnChang
 func (c *Client) createTable(ctx context.Context, table *schema.Table) (err error) {
 	c.logger.Debug().Str("table", table.Name).Msg("Table doesn't exist, creating")
 
-	query, err := queries.CreateTable(table, c.spec.Cluster, c.spec.Engine)
+	localTable := *table
+	localTable.Name = c.spec.LocalTableName(table.Name)
+	query, err := queries.CreateTable(&localTable, c.spec.Cluster, c.spec.TableOptions(table.Name))
 	if err != nil {
 		return err
 	}
@@ -107,13 +157,31 @@ func (c *Client) createTable(ctx context.Context, table *schema.Table) (err erro
 	if err := c.conn.Exec(ctx, query); err != nil {
 		return fmt.Errorf("failed to create table, query:\n%s\nerror: %w", query, err)
 	}
+
+	if !c.spec.Distributed {
+		return nil
+	}
+
+	query = queries.CreateDistributedTable(table.Name, localTable.Name, c.database, c.spec.Cluster, c.spec.ShardingKey)
+	if err := c.conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create distributed table, query:\n%s\nerror: %w", query, err)
+	}
 	return nil
 }
 
 func (c *Client) dropTable(ctx context.Context, table *schema.Table) error {
 	c.logger.Debug().Str("table", table.Name).Msg("Dropping table")
 
-	return c.conn.Exec(ctx, queries.DropTable(table, c.spec.Cluster))
+	if err := c.conn.Exec(ctx, queries.DropTable(table, c.spec.Cluster)); err != nil {
+		return err
+	}
+
+	if !c.spec.Distributed {
+		return nil
+	}
+
+	// the table may have been created before enabling distributed tables, so the local table may not exist
+	return c.conn.Exec(ctx, queries.DropTable(&schema.Table{Name: c.spec.LocalTableName(table.Name)}, c.spec.Cluster))
 }
 
 func needsTableDrop(change schema.TableColumnChange) bool {
@@ -131,10 +199,10 @@ func needsTableDrop(change schema.TableColumnChange) bool {
 	return true
 }
 
-func (c *Client) autoMigrate(ctx context.Context, have, want *schema.Table) error {
+func (c *Client) autoMigrate(ctx context.Context, have *schema.Table, haveKeys map[string]queries.TableKeys, want *schema.Table) error {
 	changes := want.GetChanges(have)
 
-	if unsafe := unsafeChanges(changes); len(unsafe) > 0 {
+	if unsafe := unsafeChanges(changes); len(unsafe) > 0 || len(c.keysChanges(want, haveKeys)) > 0 {
 		// we can get here only with migrate_mode: forced
 		if err := c.dropTable(ctx, have); err != nil {
 			return err
@@ -143,6 +211,13 @@ func (c *Client) autoMigrate(ctx context.Context, have, want *schema.Table) erro
 		return c.createTable(ctx, want)
 	}
 
+	if optionsChanges := c.optionsChanges(want, haveKeys); len(optionsChanges) > 0 {
+		c.logger.Warn().
+			Str("table", want.Name).
+			Strs("changes", optionsChanges).
+			Msg("TTL and settings aren't changed on existing tables, apply them with 'ALTER TABLE ... MODIFY TTL' and 'ALTER TABLE ... MODIFY SETTING' or recreate the table")
+	}
+
 	for _, change := range changes {
 		// we only handle new columns
 		if change.Type != schema.TableColumnChangeTypeAdd {
@@ -151,14 +226,21 @@ func (c *Client) autoMigrate(ctx context.Context, have, want *schema.Table) erro
 
 		c.logger.Debug().Str("table", want.Name).Str("column", change.Current.Name).Msg("Adding new column")
 
-		query, err := queries.AddColumn(want.Name, c.spec.Cluster, change.Current)
-		if err != nil {
-			return err
+		// the local table goes first, as the distributed table reads from it
+		tables := []string{c.spec.LocalTableName(want.Name)}
+		if c.spec.Distributed {
+			tables = append(tables, want.Name)
 		}
+		for _, table := range tables {
+			query, err := queries.AddColumn(table, c.spec.Cluster, change.Current)
+			if err != nil {
+				return err
+			}
 
-		err = c.conn.Exec(ctx, query)
-		if err != nil {
-			return err
+			err = c.conn.Exec(ctx, query)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -9,6 +9,8 @@ import (
 
 const (
 	MergeTree = "MergeTree"
+
+	replicated = "Replicated"
 )
 
 // Engine allows to specify a custom table engine to be used.
@@ -28,37 +30,38 @@ func (e *Engine) String() string {
 
 func (e *Engine) params() []string {
 	res := make([]string, len(e.Parameters))
-
 	for i, p := range e.Parameters {
-		switch t := p.(type) {
-		case string:
-			res[i] = "'" + t + "'"
-		case int:
-			res[i] = strconv.Itoa(t)
-		case int32:
-			res[i] = strconv.FormatInt(int64(t), 10)
-		case int64:
-			res[i] = strconv.FormatInt(t, 10)
-		case float32:
-			res[i] = strconv.FormatFloat(float64(t), 'f', -1, 32)
-		case float64:
-			res[i] = strconv.FormatFloat(t, 'f', -1, 64)
-		case json.Number:
-			res[i] = t.String()
-		case bool:
-			if t {
-				res[i] = "true"
-			} else {
-				res[i] = "false"
-			}
-		default:
-			res[i] = fmt.Sprint(p)
-		}
+		res[i] = formatValue(p)
 	}
-
 	return res
 }
 
+func formatValue(p any) string {
+	switch t := p.(type) {
+	case string:
+		return "'" + t + "'"
+	case int:
+		return strconv.Itoa(t)
+	case int32:
+		return strconv.FormatInt(int64(t), 10)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case json.Number:
+		return t.String()
+	case bool:
+		if t {
+			return "true"
+		}
+		return "false"
+	default:
+		return fmt.Sprint(p)
+	}
+}
+
 func (e *Engine) Validate() error {
 	if !strings.HasSuffix(e.Name, MergeTree) {
 		return fmt.Errorf("only *MergeTree table engine family is supported at the moment, got %q", e.Name)
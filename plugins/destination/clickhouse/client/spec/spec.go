@@ -9,6 +9,16 @@ import (
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/cloudquery/plugin-sdk/v4/configtype"
 	"github.com/invopop/jsonschema"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+type DeleteStaleMode string
+
+const (
+	// DeleteStaleModeDelete removes stale rows with a lightweight `DELETE` statement
+	DeleteStaleModeDelete DeleteStaleMode = "delete"
+	// DeleteStaleModeDropPartition partitions new tables by `_cq_source_name` and `_cq_sync_time` and drops the stale partitions
+	DeleteStaleModeDropPartition DeleteStaleMode = "drop_partition"
 )
 
 // CloudQuery ClickHouse destination plugin spec.
@@ -25,10 +35,40 @@ type Spec struct {
 	// If the value is empty, DDL operations will affect only the server the plugin is connected to.
 	Cluster string `json:"cluster,omitempty"`
 
+	// When enabled, every table is created as a local table replicated on each shard of `cluster`
+	// (the `*MergeTree` engine is replaced with its `Replicated*MergeTree` counterpart)
+	// and a [`Distributed`](https://clickhouse.com/docs/en/engines/table-engines/special/distributed) table over the local tables.
+	// The distributed table gets the original table name, and the rows are inserted through it.
+	//
+	// Requires `cluster` to be set.
+	Distributed bool `json:"distributed,omitempty" jsonschema:"default=false"`
+
+	// [Sharding key](https://clickhouse.com/docs/en/engines/table-engines/special/distributed#shard-key) expression for the distributed tables.
+	ShardingKey string `json:"sharding_key,omitempty" jsonschema:"minLength=1,default=cityHash64(_cq_id)"`
+
+	// Suffix added to the table name to get the name of the local table when `distributed` is enabled.
+	LocalTableSuffix string `json:"local_table_suffix,omitempty" jsonschema:"minLength=1,default=_local"`
+
 	// Engine to be used for tables.
 	// Only [`*MergeTree` family](https://clickhouse.com/docs/en/engines/table-engines/mergetree-family) is supported at the moment.
 	Engine *Engine `json:"engine,omitempty"`
 
+	// Map of table names or glob patterns to the options overriding the way the matching tables are created, for example:
+	//
+	// ```yaml
+	// tables:
+	//   "aws_cloudtrail_*":
+	//     engine: "ReplacingMergeTree"
+	//     partition_by: "toYYYYMM(assumeNotNull(_cq_sync_time))"
+	//     ttl: "toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 90 DAY"
+	// ```
+	//
+	// Exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+	// Changing `engine`, `partition_by` or `order_by` of an existing table requires `migrate_mode: forced`, as the table has to be recreated.
+	// Changes to `ttl` and `settings` of an existing table are only logged as warnings,
+	// apply them with `ALTER TABLE ... MODIFY TTL` and `ALTER TABLE ... MODIFY SETTING`.
+	Tables map[string]*TableOptions `json:"tables,omitempty"`
+
 	// PEM-encoded certificate authorities.
 	// When set, a certificate pool will be created by appending the certificates to the system pool.
 	//
@@ -44,6 +84,16 @@ type Spec struct {
 
 	// Maximum interval between batch writes.
 	BatchTimeout *configtype.Duration `json:"batch_timeout,omitempty"`
+
+	// How stale rows are removed when `write_mode` is `overwrite-delete-stale`.
+	//
+	// - `delete` removes the stale rows with a [lightweight `DELETE`](https://clickhouse.com/docs/en/sql-reference/statements/delete).
+	//   Requires ClickHouse 23.3 or later (or the `allow_experimental_lightweight_delete` setting enabled for older versions).
+	//
+	// - `drop_partition` creates new tables with `PARTITION BY (_cq_source_name, _cq_sync_time)`
+	//   and drops the partitions of previous syncs instead.
+	//   Tables that aren't partitioned this way fall back to `delete`.
+	DeleteStaleMode DeleteStaleMode `json:"delete_stale_mode,omitempty" jsonschema:"enum=delete,enum=drop_partition,default=delete"`
 }
 
 func (s *Spec) Options() (*clickhouse.Options, error) {
@@ -90,16 +140,63 @@ func (s *Spec) SetDefaults() {
 		d := configtype.NewDuration(20 * time.Second) // 20s
 		s.BatchTimeout = &d
 	}
+
+	if s.DeleteStaleMode == "" {
+		s.DeleteStaleMode = DeleteStaleModeDelete
+	}
+
+	if s.ShardingKey == "" {
+		s.ShardingKey = "cityHash64(_cq_id)"
+	}
+
+	if s.LocalTableSuffix == "" {
+		s.LocalTableSuffix = "_local"
+	}
 }
 
 func (s *Spec) Validate() error {
+	if s.Distributed && len(s.Cluster) == 0 {
+		return fmt.Errorf("cluster is required when distributed is enabled")
+	}
+	switch s.DeleteStaleMode {
+	case "", DeleteStaleModeDelete, DeleteStaleModeDropPartition:
+	default:
+		return fmt.Errorf("invalid delete_stale_mode: %q", s.DeleteStaleMode)
+	}
+	for pattern, options := range s.Tables {
+		if options == nil {
+			continue
+		}
+		if err := options.Validate(); err != nil {
+			return fmt.Errorf("invalid options for tables %q: %w", pattern, err)
+		}
+	}
 	return s.Engine.Validate()
 }
 
+// LocalTableName returns the name of the table the rows are stored in: the local table when `distributed` is enabled.
+func (s *Spec) LocalTableName(table string) string {
+	if s.Distributed {
+		return table + s.LocalTableSuffix
+	}
+	return table
+}
+
 // we need to set default for batch_timeout
 func (Spec) JSONSchemaExtend(sc *jsonschema.Schema) {
 	batchTimeout := sc.Properties.Value("batch_timeout").OneOf[0] // 0 - val, 1 - null
 	batchTimeout.Default = "20s"
+
+	// distributed tables require cluster
+	distributed := orderedmap.New[string, *jsonschema.Schema]()
+	distributed.Set("distributed", &jsonschema.Schema{Type: "boolean", Const: true})
+	cluster := orderedmap.New[string, *jsonschema.Schema]()
+	cluster.Set("cluster", &jsonschema.Schema{Type: "string", MinLength: &([]uint64{1}[0])})
+	sc.AllOf = append(sc.AllOf, &jsonschema.Schema{
+		Title: "Require `cluster` when `distributed` is enabled",
+		If:    &jsonschema.Schema{Properties: distributed, Required: []string{"distributed"}},
+		Then:  &jsonschema.Schema{Properties: cluster, Required: []string{"cluster"}},
+	})
 }
 
 //go:embed schema.json
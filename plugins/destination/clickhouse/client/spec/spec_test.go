@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/cloudquery/codegen/jsonschema"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSpec_JSONSchemaExtend(t *testing.T) {
@@ -55,6 +56,47 @@ func TestSpec_JSONSchemaExtend(t *testing.T) {
 			Name: "proper cluster",
 			Spec: `{"connection_string":"abc","cluster":"abc"}`,
 		},
+		{
+			Name: "distributed with cluster",
+			Spec: `{"connection_string":"abc","cluster":"abc","distributed":true}`,
+		},
+		{
+			Name: "distributed without cluster",
+			Err:  true,
+			Spec: `{"connection_string":"abc","distributed":true}`,
+		},
+		{
+			Name: "distributed with empty cluster",
+			Err:  true,
+			Spec: `{"connection_string":"abc","cluster":"","distributed":true}`,
+		},
+		{
+			Name: "disabled distributed without cluster",
+			Spec: `{"connection_string":"abc","distributed":false}`,
+		},
+		{
+			Name: "null distributed",
+			Err:  true,
+			Spec: `{"connection_string":"abc","distributed":null}`,
+		},
+		{
+			Name: "proper sharding_key",
+			Spec: `{"connection_string":"abc","sharding_key":"rand()"}`,
+		},
+		{
+			Name: "empty sharding_key",
+			Err:  true,
+			Spec: `{"connection_string":"abc","sharding_key":""}`,
+		},
+		{
+			Name: "proper local_table_suffix",
+			Spec: `{"connection_string":"abc","local_table_suffix":"_shard"}`,
+		},
+		{
+			Name: "empty local_table_suffix",
+			Err:  true,
+			Spec: `{"connection_string":"abc","local_table_suffix":""}`,
+		},
 		// Engine is tested separately, we will test only null here
 		{
 			Name: "null engine",
@@ -132,5 +174,68 @@ func TestSpec_JSONSchemaExtend(t *testing.T) {
 			Name: "null batch_timeout",
 			Spec: `{"connection_string":"abc","batch_timeout":null}`,
 		},
+		// TableOptions are tested separately
+		{
+			Name: "null tables",
+			Spec: `{"connection_string":"abc","tables":null}`,
+		},
+		{
+			Name: "bad tables",
+			Err:  true,
+			Spec: `{"connection_string":"abc","tables":["aws_*"]}`,
+		},
+		{
+			Name: "proper tables",
+			Spec: `{"connection_string":"abc","tables":{"aws_*":{"partition_by":"toYYYYMM(assumeNotNull(_cq_sync_time))"}}}`,
+		},
+		{
+			Name: "empty delete_stale_mode",
+			Err:  true,
+			Spec: `{"connection_string":"abc","delete_stale_mode":""}`,
+		},
+		{
+			Name: "null delete_stale_mode",
+			Err:  true,
+			Spec: `{"connection_string":"abc","delete_stale_mode":null}`,
+		},
+		{
+			Name: "bad delete_stale_mode",
+			Err:  true,
+			Spec: `{"connection_string":"abc","delete_stale_mode":"truncate"}`,
+		},
+		{
+			Name: "delete delete_stale_mode",
+			Spec: `{"connection_string":"abc","delete_stale_mode":"delete"}`,
+		},
+		{
+			Name: "drop_partition delete_stale_mode",
+			Spec: `{"connection_string":"abc","delete_stale_mode":"drop_partition"}`,
+		},
 	})
 }
+
+func TestSpec_Distributed(t *testing.T) {
+	s := &Spec{
+		Cluster: "my_cluster",
+		Tables: map[string]*TableOptions{
+			"aws_*": {Engine: "ReplacingMergeTree"},
+		},
+	}
+	s.SetDefaults()
+	require.NoError(t, s.Validate())
+	require.Equal(t, "aws_ec2_instances", s.LocalTableName("aws_ec2_instances"))
+	require.Equal(t, "MergeTree()", s.TableOptions("gcp_compute_instances").Engine)
+
+	s.Distributed = true
+	require.NoError(t, s.Validate())
+	require.Equal(t, "aws_ec2_instances_local", s.LocalTableName("aws_ec2_instances"))
+	require.Equal(t, "cityHash64(_cq_id)", s.ShardingKey)
+	require.Equal(t, "ReplicatedMergeTree()", s.TableOptions("gcp_compute_instances").Engine)
+	require.Equal(t, "ReplicatedReplacingMergeTree", s.TableOptions("aws_ec2_instances").Engine)
+
+	s.Engine = &Engine{Name: "ReplicatedMergeTree", Parameters: []any{"/clickhouse/tables/{shard}/{database}/{table}", "{replica}"}}
+	require.Equal(t, "ReplicatedMergeTree('/clickhouse/tables/{shard}/{database}/{table}', '{replica}')", s.TableOptions("gcp_compute_instances").Engine)
+
+	s.Cluster = ""
+	require.Error(t, s.Validate())
+}
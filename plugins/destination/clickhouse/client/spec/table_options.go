@@ -0,0 +1,162 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloudquery/plugin-sdk/v4/glob"
+	"github.com/invopop/jsonschema"
+)
+
+// DeleteStalePartitionBy is the partition key used for new tables when `delete_stale_mode` is `drop_partition`
+const DeleteStalePartitionBy = "(`_cq_source_name`, `_cq_sync_time`)"
+
+var (
+	tableEngineRegexp = regexp.MustCompile(`^[A-Za-z]*MergeTree(\(.*\))?$`)
+	settingNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// TableOptions allows to override the way the matching tables are created.
+// The columns are `Nullable` unless they're part of the primary key or `NOT NULL`,
+// so engine parameters (such as the version column of `ReplacingMergeTree`) can only reference those,
+// and nullable columns have to be wrapped with `assumeNotNull` in TTL expressions.
+type TableOptions struct {
+	// Table engine definition, for example `ReplacingMergeTree`.
+	// Overrides `engine` for the matching tables.
+	// Only [`*MergeTree` family](https://clickhouse.com/docs/en/engines/table-engines/mergetree-family) is supported at the moment.
+	Engine string `json:"engine,omitempty" jsonschema:"pattern=^[A-Za-z]*MergeTree(\\(.*\\))?$"`
+
+	// [`PARTITION BY`](https://clickhouse.com/docs/en/engines/table-engines/mergetree-family/custom-partitioning-key) expression,
+	// for example `toYYYYMM(assumeNotNull(_cq_sync_time))`.
+	PartitionBy string `json:"partition_by,omitempty"`
+
+	// `ORDER BY` expressions.
+	// If empty, the table is ordered by its primary key and `NOT NULL` columns.
+	OrderBy []string `json:"order_by,omitempty" jsonschema:"minLength=1"`
+
+	// [`TTL`](https://clickhouse.com/docs/en/engines/table-engines/mergetree-family/mergetree#table_engine-mergetree-ttl) expression,
+	// for example `toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 90 DAY`.
+	TTL string `json:"ttl,omitempty"`
+
+	// Additional [table settings](https://clickhouse.com/docs/en/operations/settings/merge-tree-settings), for example `index_granularity: 8192`.
+	// `allow_nullable_key` is always set to `1` unless specified here.
+	Settings map[string]any `json:"settings,omitempty"`
+}
+
+func (TableOptions) JSONSchemaExtend(sc *jsonschema.Schema) {
+	settings := sc.Properties.Value("settings").OneOf[0] // 0 - val, 1 - null
+	settings.AdditionalProperties = &jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{{Type: "string"}, {Type: "number"}, {Type: "boolean"}},
+	}
+	settings.PropertyNames = &jsonschema.Schema{Pattern: settingNameRegexp.String()}
+}
+
+func (o *TableOptions) Validate() error {
+	if len(o.Engine) > 0 && !tableEngineRegexp.MatchString(o.Engine) {
+		return fmt.Errorf("only *MergeTree table engine family is supported at the moment, got %q", o.Engine)
+	}
+
+	for _, expr := range o.OrderBy {
+		if len(expr) == 0 {
+			return fmt.Errorf("empty order_by expression")
+		}
+	}
+
+	for name, value := range o.Settings {
+		if !settingNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid setting name %q", name)
+		}
+		switch t := value.(type) {
+		case string, int, int32, int64, float32, float64, json.Number, bool: // supported types
+		default:
+			return fmt.Errorf("unsupported type %T for setting %q", t, name)
+		}
+	}
+
+	return nil
+}
+
+// EngineName returns the name of the table engine without parameters.
+func (o *TableOptions) EngineName() string {
+	name, _, _ := strings.Cut(o.Engine, "(")
+	return strings.TrimSpace(name)
+}
+
+// SettingsList returns the table settings in `name=value` format sorted by name, with `allow_nullable_key=1` added.
+func (o *TableOptions) SettingsList() []string {
+	const allowNullableKey = "allow_nullable_key"
+
+	names := make([]string, 0, len(o.Settings))
+	for name := range o.Settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	settings := make([]string, 0, len(names)+1)
+	if _, ok := o.Settings[allowNullableKey]; !ok {
+		settings = append(settings, allowNullableKey+"=1") // allows nullable keys
+	}
+	for _, name := range names {
+		settings = append(settings, name+"="+formatValue(o.Settings[name]))
+	}
+	return settings
+}
+
+func (s *Spec) tablePatterns() []string {
+	patterns := make([]string, 0, len(s.Tables))
+	for pattern := range s.Tables {
+		patterns = append(patterns, pattern)
+	}
+	// longer patterns are more specific, so they're matched first
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return patterns
+}
+
+// MatchTableOptions returns the `tables` entry matching the table, or nil if there's none.
+// Exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+func (s *Spec) MatchTableOptions(table string) *TableOptions {
+	if options, ok := s.Tables[table]; ok {
+		return options
+	}
+	for _, pattern := range s.tablePatterns() {
+		if glob.Glob(pattern, table) {
+			return s.Tables[pattern]
+		}
+	}
+	return nil
+}
+
+// ReplicatedEngine returns the `Replicated*MergeTree` counterpart of the table engine definition.
+func ReplicatedEngine(engine string) string {
+	if strings.HasPrefix(engine, replicated) {
+		return engine
+	}
+	return replicated + engine
+}
+
+// TableOptions returns the options the (local) table is created with:
+// the matching `tables` entry with the `engine`, `distributed` and `delete_stale_mode` defaults applied.
+func (s *Spec) TableOptions(table string) *TableOptions {
+	var options TableOptions
+	if matched := s.MatchTableOptions(table); matched != nil {
+		options = *matched
+	}
+	if len(options.Engine) == 0 {
+		options.Engine = s.Engine.String()
+	}
+	if s.Distributed {
+		options.Engine = ReplicatedEngine(options.Engine)
+	}
+	if len(options.PartitionBy) == 0 && s.DeleteStaleMode == DeleteStaleModeDropPartition {
+		options.PartitionBy = DeleteStalePartitionBy
+	}
+	return &options
+}
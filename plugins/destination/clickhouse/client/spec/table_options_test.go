@@ -0,0 +1,125 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/cloudquery/codegen/jsonschema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableOptionsJSONSchema(t *testing.T) {
+	schema, err := jsonschema.Generate(TableOptions{})
+	require.NoError(t, err)
+
+	jsonschema.TestJSONSchema(t, string(schema), []jsonschema.TestCase{
+		{
+			Name: "empty",
+			Spec: `{}`,
+		},
+		{
+			Name: "extra keyword",
+			Err:  true,
+			Spec: `{"extra":true}`,
+		},
+		{
+			Name: "proper engine",
+			Spec: `{"engine":"ReplacingMergeTree"}`,
+		},
+		{
+			Name: "engine without parameters",
+			Spec: `{"engine":"MergeTree"}`,
+		},
+		{
+			Name: "bad engine",
+			Err:  true,
+			Spec: `{"engine":"Log"}`,
+		},
+		{
+			Name: "proper partition_by",
+			Spec: `{"partition_by":"toYYYYMM(assumeNotNull(_cq_sync_time))"}`,
+		},
+		{
+			Name: "bad partition_by",
+			Err:  true,
+			Spec: `{"partition_by":123}`,
+		},
+		{
+			Name: "proper order_by",
+			Spec: `{"order_by":["_cq_id"]}`,
+		},
+		{
+			Name: "null order_by",
+			Spec: `{"order_by":null}`,
+		},
+		{
+			Name: "empty order_by expression",
+			Err:  true,
+			Spec: `{"order_by":[""]}`,
+		},
+		{
+			Name: "proper ttl",
+			Spec: `{"ttl":"toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 90 DAY"}`,
+		},
+		{
+			Name: "proper settings",
+			Spec: `{"settings":{"index_granularity":8192,"storage_policy":"hot_cold","ttl_only_drop_parts":true}}`,
+		},
+		{
+			Name: "bad settings value",
+			Err:  true,
+			Spec: `{"settings":{"index_granularity":[8192]}}`,
+		},
+		{
+			Name: "bad settings name",
+			Err:  true,
+			Spec: `{"settings":{"index granularity":8192}}`,
+		},
+	})
+}
+
+func TestTableOptionsValidate(t *testing.T) {
+	require.NoError(t, (&TableOptions{
+		Engine:   "ReplacingMergeTree",
+		OrderBy:  []string{"_cq_id"},
+		Settings: map[string]any{"index_granularity": float64(8192)},
+	}).Validate())
+	require.Error(t, (&TableOptions{Engine: "Log"}).Validate())
+	require.Error(t, (&TableOptions{OrderBy: []string{""}}).Validate())
+	require.Error(t, (&TableOptions{Settings: map[string]any{"index_granularity": []any{8192}}}).Validate())
+}
+
+func TestTableOptionsSettingsList(t *testing.T) {
+	require.Equal(t, []string{"allow_nullable_key=1"}, (&TableOptions{}).SettingsList())
+	require.Equal(t,
+		[]string{"allow_nullable_key=1", "index_granularity=8192", "storage_policy='hot_cold'"},
+		(&TableOptions{Settings: map[string]any{"storage_policy": "hot_cold", "index_granularity": float64(8192)}}).SettingsList(),
+	)
+	require.Equal(t,
+		[]string{"allow_nullable_key=false"},
+		(&TableOptions{Settings: map[string]any{"allow_nullable_key": false}}).SettingsList(),
+	)
+}
+
+func TestSpec_TableOptions(t *testing.T) {
+	s := &Spec{
+		Tables: map[string]*TableOptions{
+			"aws_*":                 {PartitionBy: "toYYYYMM(assumeNotNull(_cq_sync_time))"},
+			"aws_cloudtrail_*":      {Engine: "ReplacingMergeTree"},
+			"aws_cloudtrail_events": {TTL: "toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 90 DAY"},
+		},
+	}
+	s.SetDefaults()
+
+	require.Equal(t, &TableOptions{
+		Engine: "MergeTree()",
+		TTL:    "toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 90 DAY",
+	}, s.TableOptions("aws_cloudtrail_events"))
+	require.Equal(t, &TableOptions{Engine: "ReplacingMergeTree"}, s.TableOptions("aws_cloudtrail_trails"))
+	require.Equal(t, &TableOptions{Engine: "MergeTree()", PartitionBy: "toYYYYMM(assumeNotNull(_cq_sync_time))"}, s.TableOptions("aws_ec2_instances"))
+	require.Equal(t, &TableOptions{Engine: "MergeTree()"}, s.TableOptions("gcp_compute_instances"))
+	require.Nil(t, s.MatchTableOptions("gcp_compute_instances"))
+
+	s.DeleteStaleMode = DeleteStaleModeDropPartition
+	require.Equal(t, &TableOptions{Engine: "MergeTree()", PartitionBy: DeleteStalePartitionBy}, s.TableOptions("gcp_compute_instances"))
+	require.Equal(t, &TableOptions{Engine: "MergeTree()", PartitionBy: "toYYYYMM(assumeNotNull(_cq_sync_time))"}, s.TableOptions("aws_ec2_instances"))
+}
@@ -24,3 +24,18 @@ func (c *Client) getTableDefinitions(ctx context.Context, messages message.Write
 
 	return queries.ScanTableSchemas(rows, messages)
 }
+
+func (c *Client) getTablesKeys(ctx context.Context, messages message.WriteMigrateTables) (map[string]queries.TableKeys, error) {
+	query, params := queries.GetTablesKeys(c.database)
+	rows, err := c.conn.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	localTables := make(map[string]string, len(messages))
+	for _, m := range messages {
+		localTables[c.spec.LocalTableName(m.Table.Name)] = m.Table.Name
+	}
+	return queries.ScanTablesKeys(rows, localTables)
+}
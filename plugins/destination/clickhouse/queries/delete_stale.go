@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/util"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+var staleCondition = util.SanitizeID(schema.CqSourceNameColumn.Name) + " = @sourceName AND " +
+	util.SanitizeID(schema.CqSyncTimeColumn.Name) + " < @syncTime"
+
+func staleParams(sourceName string, syncTime time.Time) []any {
+	return []any{
+		driver.NamedValue{Name: "sourceName", Value: sourceName},
+		// _cq_sync_time is DateTime64(6), so we need to bind the value with the matching precision
+		clickhouse.DateNamed("syncTime", syncTime.UTC().Truncate(time.Microsecond), clickhouse.MicroSeconds),
+	}
+}
+
+// DeleteStale removes the rows of the source that were synced before the sync time using lightweight delete.
+func DeleteStale(table, cluster, sourceName string, syncTime time.Time) (query string, params []any) {
+	return "DELETE FROM " + tableNamePart(table, cluster) + " WHERE " + staleCondition,
+		staleParams(sourceName, syncTime)
+}
+
+// StalePartitions lists the IDs of the partitions holding the rows of the source that were synced before the sync time.
+// It's only correct for the tables partitioned by PartitionKey.
+func StalePartitions(table, sourceName string, syncTime time.Time) (query string, params []any) {
+	return "SELECT DISTINCT `_partition_id` FROM " + util.SanitizeID(table) + " WHERE " + staleCondition,
+		staleParams(sourceName, syncTime)
+}
+
+func DropPartition(table, cluster, partitionID string) string {
+	return "ALTER TABLE " + tableNamePart(table, cluster) + " DROP PARTITION ID " + quote(partitionID)
+}
+
+func GetPartitionKey(database, table string) (query string, params []any) {
+	const partitionKeyQuery = "SELECT `partition_key` FROM system.tables WHERE `database` = @databaseName AND `name` = @tableName"
+	return partitionKeyQuery, []any{
+		driver.NamedValue{Name: "databaseName", Value: database},
+		driver.NamedValue{Name: "tableName", Value: table},
+	}
+}
+
+// PartitionedBy checks if the partition key read from system.tables consists of the columns.
+func PartitionedBy(partitionKey string, columns []string) bool {
+	return normalizeKey(partitionKey) == strings.Join(columns, ",")
+}
@@ -0,0 +1,56 @@
+package queries
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteStale(t *testing.T) {
+	syncTime := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	query, params := DeleteStale("table_name", "", "my_source", syncTime)
+	ensureContents(t, query, "delete_stale.sql")
+	require.Equal(t, []any{
+		driver.NamedValue{Name: "sourceName", Value: "my_source"},
+		clickhouse.DateNamed("syncTime", syncTime.Truncate(time.Microsecond), clickhouse.MicroSeconds),
+	}, params)
+}
+
+func TestDeleteStaleCluster(t *testing.T) {
+	query, _ := DeleteStale("table_name", "my_cluster", "my_source", time.Now())
+	ensureContents(t, query, "delete_stale_cluster.sql")
+}
+
+func TestStalePartitions(t *testing.T) {
+	query, _ := StalePartitions("table_name", "my_source", time.Now())
+	ensureContents(t, query, "stale_partitions.sql")
+}
+
+func TestDropPartition(t *testing.T) {
+	ensureContents(t, DropPartition("table_name", "", "a1b2c3"), "drop_partition.sql")
+}
+
+func TestDropPartitionCluster(t *testing.T) {
+	ensureContents(t, DropPartition("table_name", "my_cluster", "a1b2c3"), "drop_partition_cluster.sql")
+}
+
+func TestPartitionedBy(t *testing.T) {
+	columns := []string{schema.CqSourceNameColumn.Name, schema.CqSyncTimeColumn.Name}
+	for _, tc := range []struct {
+		partitionKey string
+		expected     bool
+	}{
+		{partitionKey: "(_cq_source_name, _cq_sync_time)", expected: true},
+		{partitionKey: "_cq_source_name, _cq_sync_time", expected: true},
+		{partitionKey: "tuple(`_cq_source_name`, `_cq_sync_time`)", expected: true},
+		{partitionKey: "_cq_sync_time", expected: false},
+		{partitionKey: "toYYYYMM(_cq_sync_time)", expected: false},
+		{partitionKey: "", expected: false},
+	} {
+		require.Equal(t, tc.expected, PartitionedBy(tc.partitionKey, columns), tc.partitionKey)
+	}
+}
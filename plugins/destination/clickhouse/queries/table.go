@@ -1,7 +1,11 @@
 package queries
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/client/spec"
 	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/typeconv/arrow/types"
 	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/util"
 	"github.com/cloudquery/plugin-sdk/v4/message"
@@ -49,3 +53,142 @@ func tableNamePart(table, cluster string) string {
 	}
 	return util.SanitizeID(table)
 }
+
+// TableKeys holds the table properties set by the table options.
+// Engine, PartitionKey and SortingKey can't be changed without recreating the table.
+type TableKeys struct {
+	Engine       string
+	PartitionKey string
+	SortingKey   string
+	// TTL and Settings are parsed from the full engine definition
+	TTL      string
+	Settings map[string]string
+}
+
+func GetTablesKeys(database string) (query string, params []any) {
+	const tablesKeysQuery = "SELECT `name`, `engine`, `partition_key`, `sorting_key`, `engine_full` FROM system.tables WHERE `database` = @databaseName"
+	return tablesKeysQuery, []any{driver.NamedValue{Name: "databaseName", Value: database}}
+}
+
+// ScanTablesKeys doesn't close rows, so that's on caller.
+// The keys are read from the tables listed in localTables (local table name -> table name), and saved under the table name.
+func ScanTablesKeys(rows driver.Rows, localTables map[string]string) (map[string]TableKeys, error) {
+	res := make(map[string]TableKeys, len(localTables))
+
+	var localTable, engineFull string
+	for rows.Next() {
+		var keys TableKeys
+		if err := rows.Scan(&localTable, &keys.Engine, &keys.PartitionKey, &keys.SortingKey, &engineFull); err != nil {
+			return nil, err
+		}
+		keys.TTL, keys.Settings = parseEngineFull(engineFull)
+
+		table, ok := localTables[localTable]
+		if !ok {
+			// only save the info about required tables
+			continue
+		}
+		res[table] = keys
+	}
+
+	return res, rows.Err()
+}
+
+// KeysChanges lists the differences between the existing table keys and the ones explicitly set in the table options.
+func KeysChanges(table *schema.Table, have TableKeys, options *spec.TableOptions) []string {
+	var changes []string
+	if len(options.Engine) > 0 && options.EngineName() != have.Engine {
+		changes = append(changes, "engine: "+have.Engine+" -> "+options.EngineName())
+	}
+	if len(options.PartitionBy) > 0 && normalizeKey(options.PartitionBy) != normalizeKey(have.PartitionKey) {
+		changes = append(changes, "partition key: "+have.PartitionKey+" -> "+options.PartitionBy)
+	}
+	if orderBy := strings.Join(OrderBy(table, options), ", "); len(options.OrderBy) > 0 && normalizeKey(orderBy) != normalizeKey(have.SortingKey) {
+		changes = append(changes, "sorting key: "+have.SortingKey+" -> "+orderBy)
+	}
+	return changes
+}
+
+// OptionsChanges lists the differences between the TTL and settings of the existing table and the ones explicitly set in the table options.
+// Unlike the keys, those can be changed on the existing table with `ALTER TABLE`.
+func OptionsChanges(have TableKeys, options *spec.TableOptions) []string {
+	var changes []string
+	if len(options.TTL) > 0 && normalizeTTL(options.TTL) != normalizeTTL(have.TTL) {
+		changes = append(changes, "ttl: "+have.TTL+" -> "+options.TTL)
+	}
+	for _, setting := range options.SettingsList() {
+		name, value, _ := strings.Cut(setting, "=")
+		if _, ok := options.Settings[name]; !ok {
+			// the allow_nullable_key default
+			continue
+		}
+		haveValue, ok := have.Settings[name]
+		if !ok {
+			haveValue = "default"
+		}
+		if haveValue != value {
+			changes = append(changes, "setting "+name+": "+haveValue+" -> "+value)
+		}
+	}
+	return changes
+}
+
+// parseEngineFull returns the TTL expression and the settings of the full engine definition from system.tables, such as
+// `MergeTree ORDER BY _cq_id TTL toDateTime(_cq_sync_time) + toIntervalDay(90) SETTINGS index_granularity = 8192`.
+func parseEngineFull(engineFull string) (ttl string, settings map[string]string) {
+	settings = make(map[string]string)
+	if i := strings.LastIndex(engineFull, " SETTINGS "); i >= 0 {
+		for _, setting := range strings.Split(engineFull[i+len(" SETTINGS "):], ", ") {
+			if name, value, ok := strings.Cut(setting, " = "); ok {
+				settings[name] = value
+			}
+		}
+		engineFull = engineFull[:i]
+	}
+	if i := strings.Index(engineFull, " TTL "); i >= 0 {
+		ttl = engineFull[i+len(" TTL "):]
+	}
+	return ttl, settings
+}
+
+var intervalRegexp = regexp.MustCompile(`(?i)INTERVAL(-?\d+)(SECOND|MINUTE|HOUR|DAY|WEEK|MONTH|QUARTER|YEAR)`)
+
+// normalizeTTL strips the formatting differences between the user input and the TTL stored in system.tables,
+// where `INTERVAL 90 DAY` is stored as `toIntervalDay(90)`.
+func normalizeTTL(ttl string) string {
+	ttl = normalizeKey(ttl)
+	return intervalRegexp.ReplaceAllStringFunc(ttl, func(interval string) string {
+		match := intervalRegexp.FindStringSubmatch(interval)
+		unit := strings.ToUpper(match[2][:1]) + strings.ToLower(match[2][1:])
+		return "toInterval" + unit + "(" + match[1] + ")"
+	})
+}
+
+// normalizeKey strips the formatting differences between the user input and the key stored in system.tables.
+func normalizeKey(key string) string {
+	key = strings.NewReplacer("`", "", " ", "").Replace(key)
+	key = strings.TrimPrefix(key, "tuple")
+	for enclosedInParens(key) {
+		key = key[1 : len(key)-1]
+	}
+	return key
+}
+
+func enclosedInParens(key string) bool {
+	if !strings.HasPrefix(key, "(") {
+		return false
+	}
+	depth := 0
+	for i, r := range key {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i == len(key)-1
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package queries
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/cloudquery/plugins/destination/clickhouse/client/spec"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysChanges(t *testing.T) {
+	table := &schema.Table{
+		Name: "table_name",
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			schema.CqSyncTimeColumn,
+			schema.Column{Name: "extra_col", Type: arrow.PrimitiveTypes.Float64, NotNull: true},
+		},
+	}
+	have := TableKeys{
+		Engine:       "ReplacingMergeTree",
+		PartitionKey: "toYYYYMM(assumeNotNull(_cq_sync_time))",
+		SortingKey:   "extra_col, _cq_id",
+	}
+
+	for _, tc := range []struct {
+		name     string
+		options  *spec.TableOptions
+		expected []string
+	}{
+		{
+			name:    "no options",
+			options: &spec.TableOptions{},
+		},
+		{
+			name: "same options",
+			options: &spec.TableOptions{
+				Engine:      "ReplacingMergeTree",
+				PartitionBy: "(toYYYYMM(assumeNotNull(`_cq_sync_time`)))",
+				OrderBy:     []string{"`extra_col`", "_cq_id"},
+				TTL:         "toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 90 DAY",
+			},
+		},
+		{
+			name: "changed options",
+			options: &spec.TableOptions{
+				Engine:      "MergeTree",
+				PartitionBy: "toYYYYMMDD(assumeNotNull(_cq_sync_time))",
+				OrderBy:     []string{"_cq_id"},
+			},
+			expected: []string{
+				"engine: ReplacingMergeTree -> MergeTree",
+				"partition key: toYYYYMM(assumeNotNull(_cq_sync_time)) -> toYYYYMMDD(assumeNotNull(_cq_sync_time))",
+				"sorting key: extra_col, _cq_id -> _cq_id",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, KeysChanges(table, have, tc.options))
+		})
+	}
+}
+
+func TestOptionsChanges(t *testing.T) {
+	ttl, settings := parseEngineFull("ReplacingMergeTree PARTITION BY toYYYYMM(assumeNotNull(_cq_sync_time)) ORDER BY (_cq_id, extra_col) " +
+		"TTL toDateTime(assumeNotNull(_cq_sync_time)) + toIntervalDay(90) SETTINGS allow_nullable_key = 1, index_granularity = 8192, storage_policy = 'hot_cold'")
+	have := TableKeys{TTL: ttl, Settings: settings}
+	require.Equal(t, "toDateTime(assumeNotNull(_cq_sync_time)) + toIntervalDay(90)", have.TTL)
+	require.Equal(t, map[string]string{"allow_nullable_key": "1", "index_granularity": "8192", "storage_policy": "'hot_cold'"}, have.Settings)
+
+	for _, tc := range []struct {
+		name     string
+		options  *spec.TableOptions
+		expected []string
+	}{
+		{
+			name:    "no options",
+			options: &spec.TableOptions{},
+		},
+		{
+			name: "same options",
+			options: &spec.TableOptions{
+				TTL:      "toDateTime(assumeNotNull(`_cq_sync_time`)) + INTERVAL 90 DAY",
+				Settings: map[string]any{"storage_policy": "hot_cold", "index_granularity": json.Number("8192")},
+			},
+		},
+		{
+			name: "changed options",
+			options: &spec.TableOptions{
+				TTL:      "toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 30 DAY",
+				Settings: map[string]any{"storage_policy": "cold", "merge_with_ttl_timeout": 3600},
+			},
+			expected: []string{
+				"ttl: toDateTime(assumeNotNull(_cq_sync_time)) + toIntervalDay(90) -> toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 30 DAY",
+				"setting merge_with_ttl_timeout: default -> 3600",
+				"setting storage_policy: 'hot_cold' -> 'cold'",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, OptionsChanges(have, tc.options))
+		})
+	}
+}
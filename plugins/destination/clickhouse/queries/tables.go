@@ -27,7 +27,15 @@ func sortKeys(table *schema.Table) []string {
 	return slices.Clip(keys)
 }
 
-func CreateTable(table *schema.Table, cluster string, engine *spec.Engine) (string, error) {
+// OrderBy returns the `ORDER BY` expressions for the table.
+func OrderBy(table *schema.Table, options *spec.TableOptions) []string {
+	if len(options.OrderBy) > 0 {
+		return options.OrderBy
+	}
+	return util.Sanitized(sortKeys(table)...)
+}
+
+func CreateTable(table *schema.Table, cluster string, options *spec.TableOptions) (string, error) {
 	builder := strings.Builder{}
 	builder.WriteString("CREATE TABLE ")
 	builder.WriteString(tableNamePart(table.Name, cluster))
@@ -44,20 +52,39 @@ func CreateTable(table *schema.Table, cluster string, engine *spec.Engine) (stri
 		}
 	}
 	builder.WriteString("\n) ENGINE = ")
-	builder.WriteString(engine.String())
+	builder.WriteString(options.Engine)
+	if len(options.PartitionBy) > 0 {
+		builder.WriteString(" PARTITION BY ")
+		builder.WriteString(options.PartitionBy)
+	}
 	builder.WriteString(" ORDER BY ")
-	if orderBy := sortKeys(table); len(orderBy) > 0 {
+	if orderBy := OrderBy(table, options); len(orderBy) > 0 {
 		builder.WriteString("(")
-		builder.WriteString(strings.Join(util.Sanitized(orderBy...), ", "))
+		builder.WriteString(strings.Join(orderBy, ", "))
 		builder.WriteString(")")
 	} else {
 		builder.WriteString("tuple()")
 	}
-	builder.WriteString(" SETTINGS allow_nullable_key=1") // allows nullable keys
+	if len(options.TTL) > 0 {
+		builder.WriteString(" TTL ")
+		builder.WriteString(options.TTL)
+	}
+	builder.WriteString(" SETTINGS ")
+	builder.WriteString(strings.Join(options.SettingsList(), ", "))
 
 	return builder.String(), nil
 }
 
+// CreateDistributedTable creates a Distributed table over the local tables on each shard of the cluster.
+func CreateDistributedTable(table, localTable, database, cluster, shardingKey string) string {
+	return "CREATE TABLE " + tableNamePart(table, cluster) + " AS " + util.SanitizeID(localTable) +
+		" ENGINE = Distributed(" + strings.Join([]string{quote(cluster), quote(database), quote(localTable), shardingKey}, ", ") + ")"
+}
+
+func quote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
 func DropTable(table *schema.Table, cluster string) string {
 	return "DROP TABLE IF EXISTS " + tableNamePart(table.Name, cluster)
 }
@@ -11,6 +11,10 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func defaultOptions() *spec.TableOptions {
+	return &spec.TableOptions{Engine: spec.DefaultEngine().String()}
+}
+
 func TestCreateTable(t *testing.T) {
 	query, err := CreateTable(&schema.Table{
 		Name: "table_name",
@@ -27,7 +31,7 @@ func TestCreateTable(t *testing.T) {
 			schema.Column{Name: "extra_inet_col", Type: types.NewInetType()},
 			schema.Column{Name: "extra_inet_arr_col", Type: arrow.ListOf(types.NewInetType())},
 		},
-	}, "", spec.DefaultEngine())
+	}, "", defaultOptions())
 	require.NoError(t, err)
 	ensureContents(t, query, "create_table.sql")
 }
@@ -40,7 +44,7 @@ func TestCreateTableNoOrderBy(t *testing.T) {
 			schema.Column{Name: "extra_inet_col", Type: types.NewInetType()},
 			schema.Column{Name: "extra_inet_arr_col", Type: arrow.ListOf(types.NewInetType())},
 		},
-	}, "", spec.DefaultEngine())
+	}, "", defaultOptions())
 	require.NoError(t, err)
 	ensureContents(t, query, "create_table_empty_order_by.sql")
 }
@@ -61,7 +65,7 @@ func TestCreateTableOnCluster(t *testing.T) {
 			schema.Column{Name: "extra_inet_col", Type: types.NewInetType()},
 			schema.Column{Name: "extra_inet_arr_col", Type: arrow.ListOf(types.NewInetType())},
 		},
-	}, "my_cluster", spec.DefaultEngine())
+	}, "my_cluster", defaultOptions())
 	require.NoError(t, err)
 	ensureContents(t, query, "create_table_cluster.sql")
 }
@@ -82,14 +86,67 @@ func TestCreateTableWithEngine(t *testing.T) {
 			schema.Column{Name: "extra_inet_col", Type: types.NewInetType()},
 			schema.Column{Name: "extra_inet_arr_col", Type: arrow.ListOf(types.NewInetType())},
 		},
-	}, "", &spec.Engine{
+	}, "", &spec.TableOptions{Engine: (&spec.Engine{
 		Name:       "ReplicatedMergeTree",
 		Parameters: []any{"a", "b", 1, int32(2), int64(3), float32(1.2), float64(3.4), json.Number("327"), false, true},
-	})
+	}).String()})
 	require.NoError(t, err)
 	ensureContents(t, query, "create_table_engine.sql")
 }
 
+func TestCreateTablePartitionBy(t *testing.T) {
+	query, err := CreateTable(&schema.Table{
+		Name: "table_name",
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			schema.CqParentIDColumn,
+			schema.CqSourceNameColumn,
+			schema.CqSyncTimeColumn,
+			schema.Column{
+				Name:    "extra_col",
+				Type:    arrow.PrimitiveTypes.Float64,
+				NotNull: true,
+			},
+		},
+	}, "", &spec.TableOptions{Engine: spec.DefaultEngine().String(), PartitionBy: spec.DeleteStalePartitionBy})
+	require.NoError(t, err)
+	ensureContents(t, query, "create_table_partition_by.sql")
+}
+
+func TestCreateTableWithOptions(t *testing.T) {
+	query, err := CreateTable(&schema.Table{
+		Name: "table_name",
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			schema.CqParentIDColumn,
+			schema.CqSourceNameColumn,
+			schema.CqSyncTimeColumn,
+			schema.Column{
+				Name:    "extra_col",
+				Type:    arrow.PrimitiveTypes.Float64,
+				NotNull: true,
+			},
+		},
+	}, "my_cluster", &spec.TableOptions{
+		Engine:      "ReplacingMergeTree",
+		PartitionBy: "toYYYYMM(assumeNotNull(_cq_sync_time))",
+		OrderBy:     []string{"_cq_id", "toDate(_cq_sync_time)"},
+		TTL:         "toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 90 DAY",
+		Settings: map[string]any{
+			"storage_policy":    "hot_cold",
+			"index_granularity": json.Number("8192"),
+		},
+	})
+	require.NoError(t, err)
+	ensureContents(t, query, "create_table_options.sql")
+}
+
+func TestCreateDistributedTable(t *testing.T) {
+	query := CreateDistributedTable("table_name", "table_name_local", "my_database", "my_cluster", "cityHash64(_cq_id)")
+
+	ensureContents(t, query, "create_distributed_table.sql")
+}
+
 func TestDropTable(t *testing.T) {
 	query := DropTable(&schema.Table{Name: "table_name"}, "")
 
@@ -0,0 +1 @@
+CREATE TABLE `table_name` ON CLUSTER `my_cluster` AS `table_name_local` ENGINE = Distributed('my_cluster', 'my_database', 'table_name_local', cityHash64(_cq_id))
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE `table_name` ON CLUSTER `my_cluster` (
+  `_cq_id` UUID,
+  `_cq_parent_id` Nullable(UUID),
+  `_cq_source_name` Nullable(String),
+  `_cq_sync_time` Nullable(DateTime64(6)),
+  `extra_col` Float64
+) ENGINE = ReplacingMergeTree PARTITION BY toYYYYMM(assumeNotNull(_cq_sync_time)) ORDER BY (_cq_id, toDate(_cq_sync_time)) TTL toDateTime(assumeNotNull(_cq_sync_time)) + INTERVAL 90 DAY SETTINGS allow_nullable_key=1, index_granularity=8192, storage_policy='hot_cold'
\ No newline at end of file
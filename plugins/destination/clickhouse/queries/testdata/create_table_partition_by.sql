@@ -0,0 +1,7 @@
+CREATE TABLE `table_name` (
+  `_cq_id` UUID,
+  `_cq_parent_id` Nullable(UUID),
+  `_cq_source_name` Nullable(String),
+  `_cq_sync_time` Nullable(DateTime64(6)),
+  `extra_col` Float64
+) ENGINE = MergeTree() PARTITION BY (`_cq_source_name`, `_cq_sync_time`) ORDER BY (`extra_col`, `_cq_id`) SETTINGS allow_nullable_key=1
\ No newline at end of file
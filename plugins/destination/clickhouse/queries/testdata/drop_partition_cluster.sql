@@ -0,0 +1 @@
+ALTER TABLE `table_name` ON CLUSTER `my_cluster` DROP PARTITION ID 'a1b2c3'
\ No newline at end of file
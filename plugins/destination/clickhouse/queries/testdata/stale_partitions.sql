@@ -0,0 +1 @@
+SELECT DISTINCT `_partition_id` FROM `table_name` WHERE `_cq_source_name` = @sourceName AND `_cq_sync_time` < @syncTime
\ No newline at end of file
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// bulkItem is a single action of a bulk request: the action metadata line and the document line.
+type bulkItem struct {
+	meta []byte
+	doc  []byte
+}
+
+type bulkResponse struct {
+	Took   int64 `json:"took"`
+	Errors bool  `json:"errors"`
+	// Items holds the results of the actions, in the order of the request.
+	// Each result is keyed by the action type, such as `index` or `create`.
+	Items []map[string]bulkResponseItem `json:"items"`
+}
+
+type bulkResponseItem struct {
+	Index  string                 `json:"_index"`
+	Status int                    `json:"status"`
+	Error  *bulkResponseItemError `json:"error,omitempty"`
+}
+
+type bulkResponseItemError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func (e *bulkResponseItemError) String() string {
+	return e.Type + ": " + e.Reason
+}
+
+// deadLetter is the document written to the dead letter index in place of a document not matching the index mappings.
+type deadLetter struct {
+	Timestamp   string `json:"@timestamp"`
+	Table       string `json:"table"`
+	Index       string `json:"index"`
+	ErrorType   string `json:"error_type"`
+	ErrorReason string `json:"error_reason"`
+	// Document is the original document as a string, so that it doesn't conflict with the dead letter index mappings.
+	Document string `json:"document"`
+}
+
+// mappingErrorTypes are the errors of the documents not matching the index mappings.
+var mappingErrorTypes = map[string]bool{
+	"mapper_parsing_exception":         true,
+	"document_parsing_exception":       true,
+	"strict_dynamic_mapping_exception": true,
+}
+
+// writeData writes the documents to the index of the table, checking the result of every document.
+// The documents rejected with 429 Too Many Requests are retried with exponential backoff, up to max_retries times.
+// If dead_letter_index is set, the documents not matching the index mappings are written to it.
+// Any other failure is returned as an error, after the rest of the documents are written.
+func (c *Client) writeData(ctx context.Context, table *schema.Table, items []bulkItem) error {
+	index := c.getIndexName(table, time.Now())
+	retryBackoff := backoff.NewExponentialBackOff()
+	var failures []*bulkResponseItemError
+	var deadLetters []bulkItem
+	pending := items
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			wait := retryBackoff.NextBackOff()
+			c.logger.Debug().Str("index", index).Int("documents", len(pending)).Dur("wait", wait).Msg("retrying documents rejected with 429 Too Many Requests")
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		results, err := c.bulk(ctx, index, pending)
+		if err != nil {
+			return err
+		}
+		var retry []bulkItem
+		for i, result := range results {
+			switch {
+			case result.Error == nil:
+				continue
+			case result.Status == http.StatusTooManyRequests && attempt < *c.spec.MaxRetries:
+				retry = append(retry, pending[i])
+			case c.spec.DeadLetterIndex != "" && mappingErrorTypes[result.Error.Type]:
+				deadLetters = append(deadLetters, newDeadLetterItem(table, index, result.Error, pending[i]))
+			default:
+				failures = append(failures, result.Error)
+			}
+		}
+		pending = retry
+	}
+
+	if len(deadLetters) > 0 {
+		c.logger.Warn().Str("table", table.Name).Str("index", c.spec.DeadLetterIndex).Int("documents", len(deadLetters)).Msg("writing documents not matching the index mappings to the dead letter index")
+		results, err := c.bulk(ctx, c.spec.DeadLetterIndex, deadLetters)
+		if err != nil {
+			return fmt.Errorf("failed to write to dead letter index: %w", err)
+		}
+		for _, result := range results {
+			if result.Error != nil {
+				failures = append(failures, result.Error)
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to write %d of %d documents to index %s: %s", len(failures), len(items), index, failures[0])
+	}
+	return nil
+}
+
+// bulk sends the bulk request, returning the results of the actions in the order of the items.
+func (c *Client) bulk(ctx context.Context, index string, items []bulkItem) ([]bulkResponseItem, error) {
+	body := new(bytes.Buffer)
+	for _, item := range items {
+		body.Grow(len(item.meta) + len(item.doc) + 2)
+		body.Write(item.meta)
+		body.WriteByte('\n')
+		body.Write(item.doc)
+		body.WriteByte('\n')
+	}
+	resp, err := c.client.Bulk(body,
+		c.client.Bulk.WithContext(ctx),
+		c.client.Bulk.WithIndex(index),
+		c.client.Bulk.WithRefresh(c.spec.Refresh),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bulk request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return nil, fmt.Errorf("bulk request failed: %s", resp.String())
+	}
+	var bulkResp bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&bulkResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bulk response: %w", err)
+	}
+	if len(bulkResp.Items) != len(items) {
+		return nil, fmt.Errorf("bulk response has %d items, expected %d", len(bulkResp.Items), len(items))
+	}
+	results := make([]bulkResponseItem, len(items))
+	for i, item := range bulkResp.Items {
+		for _, result := range item {
+			results[i] = result
+		}
+	}
+	return results, nil
+}
+
+func newDeadLetterItem(table *schema.Table, index string, itemErr *bulkResponseItemError, item bulkItem) bulkItem {
+	doc, _ := json.Marshal(deadLetter{
+		Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+		Table:       table.Name,
+		Index:       index,
+		ErrorType:   itemErr.Type,
+		ErrorReason: itemErr.Reason,
+		Document:    string(item.doc),
+	})
+	// the create action is supported by both indices and data streams
+	return bulkItem{meta: []byte(`{"create":{}}`), doc: doc}
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/rs/zerolog"
+)
+
+type bulkRequest struct {
+	index string
+	docs  []map[string]any
+}
+
+// newTestBulkClient returns a client of a fake Elasticsearch server answering the bulk requests with the given function,
+// which returns the status and error type of each document.
+func newTestBulkClient(t *testing.T, spec Spec, respond func(req bulkRequest, doc map[string]any) (int, string)) (*Client, *[]bulkRequest) {
+	t.Helper()
+	var lock sync.Mutex
+	var requests []bulkRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		req := bulkRequest{index: strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0]}
+		if r.URL.Query().Get("refresh") != spec.Refresh {
+			t.Errorf("expected refresh %q, got %q", spec.Refresh, r.URL.Query().Get("refresh"))
+		}
+		scanner := bufio.NewScanner(r.Body)
+		for i := 0; scanner.Scan(); i++ {
+			if i%2 == 0 {
+				continue // action metadata
+			}
+			var doc map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+				t.Error(err)
+			}
+			req.docs = append(req.docs, doc)
+		}
+		lock.Lock()
+		requests = append(requests, req)
+		lock.Unlock()
+
+		items := make([]string, len(req.docs))
+		for i, doc := range req.docs {
+			status, errType := respond(req, doc)
+			if errType == "" {
+				items[i] = fmt.Sprintf(`{"index":{"_index":%q,"status":%d}}`, req.index, status)
+			} else {
+				items[i] = fmt.Sprintf(`{"index":{"_index":%q,"status":%d,"error":{"type":%q,"reason":"failed"}}}`, req.index, status, errType)
+			}
+		}
+		fmt.Fprintf(w, `{"took":1,"errors":true,"items":[%s]}`, strings.Join(items, ","))
+	}))
+	t.Cleanup(srv.Close)
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec.SetDefaults()
+	return &Client{logger: zerolog.Nop(), spec: &spec, client: es}, &requests
+}
+
+func testBulkItems(ids ...string) []bulkItem {
+	items := make([]bulkItem, len(ids))
+	for i, id := range ids {
+		items[i] = bulkItem{meta: []byte(`{"index":{}}`), doc: []byte(fmt.Sprintf(`{"id":%q}`, id))}
+	}
+	return items
+}
+
+func TestWriteDataRetriesTooManyRequests(t *testing.T) {
+	table := &schema.Table{Name: "test_table", Columns: schema.ColumnList{{Name: "id", Type: arrow.BinaryTypes.String}}}
+	attempts := map[string]int{}
+	c, requests := newTestBulkClient(t, Spec{Refresh: "false"}, func(_ bulkRequest, doc map[string]any) (int, string) {
+		id := doc["id"].(string)
+		attempts[id]++
+		if id == "b" && attempts[id] < 3 {
+			return http.StatusTooManyRequests, "es_rejected_execution_exception"
+		}
+		return http.StatusCreated, ""
+	})
+
+	if err := c.writeData(context.Background(), table, testBulkItems("a", "b", "c")); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 3 {
+		t.Fatalf("expected 3 bulk requests, got %d", len(*requests))
+	}
+	for _, retry := range (*requests)[1:] {
+		if len(retry.docs) != 1 || retry.docs[0]["id"] != "b" {
+			t.Errorf("expected only the rejected document to be retried, got %v", retry.docs)
+		}
+	}
+}
+
+func TestWriteDataTooManyRequestsRetriesExhausted(t *testing.T) {
+	table := &schema.Table{Name: "test_table", Columns: schema.ColumnList{{Name: "id", Type: arrow.BinaryTypes.String}}}
+	maxRetries := 1
+	c, requests := newTestBulkClient(t, Spec{MaxRetries: &maxRetries}, func(bulkRequest, map[string]any) (int, string) {
+		return http.StatusTooManyRequests, "es_rejected_execution_exception"
+	})
+
+	err := c.writeData(context.Background(), table, testBulkItems("a", "b"))
+	if err == nil || !strings.Contains(err.Error(), "failed to write 2 of 2 documents") {
+		t.Fatalf("expected write error, got %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 bulk requests, got %d", len(*requests))
+	}
+}
+
+func TestWriteDataMappingErrors(t *testing.T) {
+	table := &schema.Table{Name: "test_table", Columns: schema.ColumnList{{Name: "id", Type: arrow.BinaryTypes.String}}}
+	respond := func(req bulkRequest, doc map[string]any) (int, string) {
+		if req.index == "dead-letter" || doc["id"] != "b" {
+			return http.StatusCreated, ""
+		}
+		return http.StatusBadRequest, "document_parsing_exception"
+	}
+
+	t.Run("write error", func(t *testing.T) {
+		c, requests := newTestBulkClient(t, Spec{}, respond)
+		err := c.writeData(context.Background(), table, testBulkItems("a", "b"))
+		if err == nil || !strings.Contains(err.Error(), "failed to write 1 of 2 documents") || !strings.Contains(err.Error(), "document_parsing_exception") {
+			t.Fatalf("expected write error, got %v", err)
+		}
+		if len(*requests) != 1 {
+			t.Fatalf("expected 1 bulk request, got %d", len(*requests))
+		}
+	})
+
+	t.Run("dead letter index", func(t *testing.T) {
+		c, requests := newTestBulkClient(t, Spec{DeadLetterIndex: "dead-letter"}, respond)
+		if err := c.writeData(context.Background(), table, testBulkItems("a", "b")); err != nil {
+			t.Fatal(err)
+		}
+		if len(*requests) != 2 {
+			t.Fatalf("expected 2 bulk requests, got %d", len(*requests))
+		}
+		deadLetters := (*requests)[1]
+		if deadLetters.index != "dead-letter" || len(deadLetters.docs) != 1 {
+			t.Fatalf("expected 1 document written to the dead letter index, got %v", deadLetters)
+		}
+		doc := deadLetters.docs[0]
+		if doc["table"] != "test_table" || doc["error_type"] != "document_parsing_exception" || doc["document"] != `{"id":"b"}` {
+			t.Errorf("unexpected dead letter %v", doc)
+		}
+	})
+}
@@ -5,11 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/cloudquery/plugin-sdk/v4/plugin"
-	"github.com/cloudquery/plugin-sdk/v4/schema"
 	"github.com/cloudquery/plugin-sdk/v4/writers/batchwriter"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/rs/zerolog"
@@ -24,13 +24,15 @@ type Client struct {
 	client      *elasticsearch.Client
 	typedClient *elasticsearch.TypedClient
 	writer      *batchwriter.BatchWriter
+	syncID      string
 }
 
-func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, _ plugin.NewClientOptions) (plugin.Client, error) {
+func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, opts plugin.NewClientOptions) (plugin.Client, error) {
 	var err error
 	c := &Client{
 		logger: logger.With().Str("module", "elasticsearch-dest").Logger(),
 		spec:   &Spec{},
+		syncID: opts.InvocationID,
 	}
 	if err := json.Unmarshal(specBytes, c.spec); err != nil {
 		return nil, err
@@ -40,6 +42,9 @@ func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, _ plugin.
 	if err := c.spec.Validate(); err != nil {
 		return nil, err
 	}
+	if c.syncID == "" && (strings.Contains(c.spec.IndexName, varSyncID) || strings.Contains(c.spec.AppendIndexName, varSyncID)) {
+		return nil, fmt.Errorf("index name contains %s. Upgrade your CLI to use this placeholder variable", varSyncID)
+	}
 	c.writer, err = batchwriter.New(c, batchwriter.WithBatchSize(c.spec.BatchSize), batchwriter.WithBatchSizeBytes(c.spec.BatchSizeBytes), batchwriter.WithLogger(c.logger))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create batch writer: %w", err)
@@ -67,8 +72,9 @@ func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, _ plugin.
 			}
 			return retryBackoff.NextBackOff()
 		},
-		// Retry up to 5 attempts
-		MaxRetries: 5,
+		// Retry up to max_retries attempts
+		MaxRetries:   *c.spec.MaxRetries,
+		DisableRetry: *c.spec.MaxRetries == 0,
 	}
 	es, err := elasticsearch.NewTypedClient(cfg)
 	if err != nil {
@@ -98,19 +104,3 @@ func (c *Client) Close(ctx context.Context) error {
 	}
 	return nil
 }
-
-func (*Client) getIndexNamePattern(table *schema.Table) string {
-	hasPrimaryKeys := len(table.PrimaryKeys()) > 0
-	if hasPrimaryKeys {
-		return table.Name
-	}
-	return table.Name + "-*"
-}
-
-func (*Client) getIndexName(table *schema.Table, t time.Time) string {
-	hasPrimaryKeys := len(table.PrimaryKeys()) > 0
-	if hasPrimaryKeys {
-		return table.Name
-	}
-	return table.Name + "-" + t.Format(time.DateOnly)
-}
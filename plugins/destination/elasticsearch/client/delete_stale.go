@@ -34,8 +34,9 @@ func (c *Client) DeleteStale(ctx context.Context, msgs message.WriteDeleteStales
 		msg := msg
 		g.Go(func() error {
 			syncTimeStr := msg.SyncTime.Format(time.RFC3339)
+			// deleting stale entries is meant for tables with primary keys, so the indices are matched using index_name
 			return c.deleteStaleIndex(gctx,
-				msg.TableName,
+				indexNamePattern(c.spec.IndexName, msg.TableName),
 				&deletebyquery.Request{
 					Query: &types.Query{
 						Bool: &types.BoolQuery{
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/glob"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+const (
+	varTable  = "{{TABLE}}"
+	varYear   = "{{YEAR}}"
+	varMonth  = "{{MONTH}}"
+	varDay    = "{{DAY}}"
+	varHour   = "{{HOUR}}"
+	varSyncID = "{{SYNC_ID}}"
+)
+
+// useDataStream reports whether the table is written to a data stream instead of regular indices.
+func (c *Client) useDataStream(table *schema.Table) bool {
+	return c.spec.DataStreams && len(table.PrimaryKeys()) == 0
+}
+
+func (c *Client) indexNameTemplate(table *schema.Table) string {
+	if len(table.PrimaryKeys()) > 0 {
+		return c.spec.IndexName
+	}
+	return c.spec.AppendIndexName
+}
+
+// getIndexName returns the name of the index (or data stream) the table is written to at the given time.
+func (c *Client) getIndexName(table *schema.Table, t time.Time) string {
+	t = t.UTC()
+	return strings.NewReplacer(
+		varTable, table.Name,
+		varYear, t.Format("2006"),
+		varMonth, t.Format("01"),
+		varDay, t.Format("02"),
+		varHour, t.Format("15"),
+		varSyncID, c.syncID,
+	).Replace(c.indexNameTemplate(table))
+}
+
+// getIndexNamePattern returns the pattern matching all the indices (or data streams) the table is written to.
+func (c *Client) getIndexNamePattern(table *schema.Table) string {
+	return indexNamePattern(c.indexNameTemplate(table), table.Name)
+}
+
+func indexNamePattern(template string, tableName string) string {
+	pattern := strings.NewReplacer(
+		varTable, tableName,
+		varYear, "*",
+		varMonth, "*",
+		varDay, "*",
+		varHour, "*",
+		varSyncID, "*",
+	).Replace(template)
+	for strings.Contains(pattern, "**") {
+		pattern = strings.ReplaceAll(pattern, "**", "*")
+	}
+	return pattern
+}
+
+// getColumnMapping returns the column_mappings override of the column, or nil if there's none.
+func (c *Client) getColumnMapping(tableName string, columnName string) map[string]any {
+	if mapping, ok := c.spec.ColumnMappings[tableName][columnName]; ok {
+		return mapping
+	}
+	patterns := make([]string, 0, len(c.spec.ColumnMappings))
+	for pattern := range c.spec.ColumnMappings {
+		patterns = append(patterns, pattern)
+	}
+	// longer patterns are more specific, so they're matched first
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		mapping, ok := c.spec.ColumnMappings[pattern][columnName]
+		if ok && glob.Glob(pattern, tableName) {
+			return mapping
+		}
+	}
+	return nil
+}
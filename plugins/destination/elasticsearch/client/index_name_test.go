@@ -0,0 +1,166 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+func testTables() (withPKs *schema.Table, withoutPKs *schema.Table) {
+	withPKs = &schema.Table{
+		Name: "test_table",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+			{Name: "name", Type: arrow.BinaryTypes.String},
+			schema.CqSyncTimeColumn,
+		},
+	}
+	withoutPKs = &schema.Table{
+		Name: "test_table",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.BinaryTypes.String},
+			{Name: "name", Type: arrow.BinaryTypes.String},
+			schema.CqSyncTimeColumn,
+		},
+	}
+	return withPKs, withoutPKs
+}
+
+func TestGetIndexName(t *testing.T) {
+	withPKs, withoutPKs := testTables()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		name            string
+		spec            Spec
+		table           *schema.Table
+		expectedName    string
+		expectedPattern string
+	}{
+		{
+			name:            "default with primary keys",
+			table:           withPKs,
+			expectedName:    "test_table",
+			expectedPattern: "test_table",
+		},
+		{
+			name:            "default without primary keys",
+			table:           withoutPKs,
+			expectedName:    "test_table-2024-01-02",
+			expectedPattern: "test_table-*-*-*",
+		},
+		{
+			name:            "default data stream",
+			spec:            Spec{DataStreams: true},
+			table:           withoutPKs,
+			expectedName:    "test_table",
+			expectedPattern: "test_table",
+		},
+		{
+			name:            "prefix",
+			spec:            Spec{IndexName: "cq-{{TABLE}}"},
+			table:           withPKs,
+			expectedName:    "cq-test_table",
+			expectedPattern: "cq-test_table",
+		},
+		{
+			name:            "date pattern & sync ID",
+			spec:            Spec{AppendIndexName: "{{TABLE}}-{{SYNC_ID}}-{{YEAR}}{{MONTH}}{{DAY}}{{HOUR}}"},
+			table:           withoutPKs,
+			expectedName:    "test_table-sync-2024010203",
+			expectedPattern: "test_table-*-*",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.spec.SetDefaults()
+			c := &Client{spec: &tc.spec, syncID: "sync"}
+			if name := c.getIndexName(tc.table, now); name != tc.expectedName {
+				t.Errorf("expected index name %q, got %q", tc.expectedName, name)
+			}
+			if pattern := c.getIndexNamePattern(tc.table); pattern != tc.expectedPattern {
+				t.Errorf("expected index name pattern %q, got %q", tc.expectedPattern, pattern)
+			}
+		})
+	}
+}
+
+func TestGetColumnMapping(t *testing.T) {
+	c := &Client{spec: &Spec{ColumnMappings: map[string]map[string]map[string]any{
+		"*":            {"arn": {"type": "keyword"}, "name": {"type": "keyword"}},
+		"aws_*":        {"arn": {"type": "wildcard"}},
+		"aws_ec2_*":    {"arn": {"type": "text"}},
+		"aws_ec2_vpcs": {"name": {"type": "text"}},
+	}}}
+	cases := []struct {
+		table, column string
+		expected      any
+	}{
+		{table: "gcp_projects", column: "arn", expected: "keyword"},
+		{table: "aws_s3_buckets", column: "arn", expected: "wildcard"},
+		{table: "aws_ec2_instances", column: "arn", expected: "text"},
+		{table: "aws_ec2_instances", column: "name", expected: "keyword"},
+		{table: "aws_ec2_vpcs", column: "name", expected: "text"},
+		{table: "aws_ec2_vpcs", column: "id", expected: nil},
+	}
+	for _, tc := range cases {
+		mapping := c.getColumnMapping(tc.table, tc.column)
+		var mappingType any
+		if mapping != nil {
+			mappingType = mapping["type"]
+		}
+		if mappingType != tc.expected {
+			t.Errorf("%s.%s: expected mapping type %v, got %v", tc.table, tc.column, tc.expected, mappingType)
+		}
+	}
+}
+
+func TestGetIndexTemplate(t *testing.T) {
+	_, withoutPKs := testTables()
+	replicas := 0
+	spec := Spec{
+		DataStreams:      true,
+		ILMPolicy:        "policy",
+		NumberOfShards:   3,
+		NumberOfReplicas: &replicas,
+		ColumnMappings:   map[string]map[string]map[string]any{"test_*": {"name": {"type": "keyword"}}},
+	}
+	spec.SetDefaults()
+	c := &Client{spec: &spec}
+	tmpl, err := c.getIndexTemplate(withoutPKs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		IndexPatterns []string        `json:"index_patterns"`
+		DataStream    *map[string]any `json:"data_stream"`
+		Template      struct {
+			Settings map[string]any `json:"settings"`
+			Mappings struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"mappings"`
+		} `json:"template"`
+	}
+	if err := json.Unmarshal([]byte(tmpl), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.IndexPatterns) != 1 || got.IndexPatterns[0] != "test_table" {
+		t.Errorf("unexpected index patterns %v", got.IndexPatterns)
+	}
+	if got.DataStream == nil {
+		t.Error("expected data stream template")
+	}
+	settings, _ := json.Marshal(got.Template.Settings)
+	if expected := `{"lifecycle":{"name":"policy"},"number_of_replicas":"0","number_of_shards":"3"}`; string(settings) != expected {
+		t.Errorf("expected settings %s, got %s", expected, settings)
+	}
+	properties := got.Template.Mappings.Properties
+	for column, expected := range map[string]string{"id": "text", "name": "keyword", "_cq_sync_time": "date", timestampField: "date"} {
+		if properties[column]["type"] != expected {
+			t.Errorf("expected %s to be mapped as %s, got %v", column, expected, properties[column])
+		}
+	}
+}
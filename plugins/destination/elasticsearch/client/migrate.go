@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/apache/arrow/go/v16/arrow"
@@ -25,36 +27,8 @@ func (c *Client) MigrateTables(ctx context.Context, msgs message.WriteMigrateTab
 		}
 
 		if msg.MigrateForce {
-			var indicesToDelete []string
-			pat := c.getIndexNamePattern(table)
-			if strings.HasSuffix(pat, "*") {
-				resp, err := c.client.Indices.Get([]string{pat},
-					c.client.Indices.Get.WithContext(ctx),
-					c.client.Indices.Get.WithIgnoreUnavailable(true),
-					c.client.Indices.Get.WithFeatures("aliases"),
-				)
-				if err != nil {
-					return fmt.Errorf("failed to get indices: %w", err)
-				}
-				if resp.IsError() {
-					return fmt.Errorf("failed to get indices: %s", resp.String())
-				}
-
-				var indices map[string]any
-				if err := json.NewDecoder(resp.Body).Decode(&indices); err != nil {
-					return fmt.Errorf("failed to decode response body: %w", err)
-				}
-				_ = resp.Body.Close()
-
-				indicesToDelete = maps.Keys(indices)
-			} else {
-				indicesToDelete = []string{table.Name}
-			}
-
-			if len(indicesToDelete) > 0 {
-				if err := c.deleteIndices(ctx, indicesToDelete); err != nil {
-					return fmt.Errorf("failed to delete indices: %w", err)
-				}
+			if err := c.deleteTableIndices(ctx, table); err != nil {
+				return err
 			}
 		}
 
@@ -77,29 +51,135 @@ func (c *Client) MigrateTables(ctx context.Context, msgs message.WriteMigrateTab
 	return nil
 }
 
+// indexTemplate is types.IndexTemplate with the mappings of the properties as arbitrary values,
+// as the column_mappings overrides can't be represented as types.Property.
+type indexTemplate struct {
+	types.IndexTemplate
+	Template indexTemplateSummary `json:"template"`
+}
+
+type indexTemplateSummary struct {
+	types.IndexTemplateSummary
+	Mappings indexTemplateMappings `json:"mappings"`
+}
+
+type indexTemplateMappings struct {
+	Properties map[string]any `json:"properties"`
+}
+
 func (c *Client) getIndexTemplate(table *schema.Table) (string, error) {
-	properties := map[string]types.Property{}
+	properties := map[string]any{}
 	for _, col := range table.Columns {
+		if mapping := c.getColumnMapping(table.Name, col.Name); mapping != nil {
+			properties[col.Name] = mapping
+			continue
+		}
 		properties[col.Name] = arrowTypeToElasticsearchProperty(col.Type)
 	}
-	tmp := types.IndexTemplate{
-		AllowAutoCreate: nil,
-		ComposedOf:      []string{},
-		DataStream:      nil,
-		IndexPatterns:   []string{c.getIndexNamePattern(table)},
-		Meta_:           nil,
-		Priority:        nil,
-		Template: &types.IndexTemplateSummary{
-			Mappings: &types.TypeMapping{
+	var dataStream *types.IndexTemplateDataStreamConfiguration
+	if c.useDataStream(table) {
+		dataStream = types.NewIndexTemplateDataStreamConfiguration()
+		properties[timestampField] = arrowTypeToElasticsearchProperty(arrow.FixedWidthTypes.Timestamp_us)
+	}
+	tmp := indexTemplate{
+		IndexTemplate: types.IndexTemplate{
+			AllowAutoCreate: nil,
+			ComposedOf:      []string{},
+			DataStream:      dataStream,
+			IndexPatterns:   []string{c.getIndexNamePattern(table)},
+			Meta_:           nil,
+			Priority:        nil,
+			Version:         nil,
+		},
+		Template: indexTemplateSummary{
+			IndexTemplateSummary: types.IndexTemplateSummary{
+				Settings: c.getIndexSettings(),
+			},
+			Mappings: indexTemplateMappings{
 				Properties: properties,
 			},
 		},
-		Version: nil,
 	}
 	b, err := json.Marshal(tmp)
 	return string(b), err
 }
 
+func (c *Client) getIndexSettings() *types.IndexSettings {
+	if c.spec.ILMPolicy == "" && c.spec.NumberOfShards == 0 && c.spec.NumberOfReplicas == nil {
+		return nil
+	}
+	settings := types.NewIndexSettings()
+	if c.spec.ILMPolicy != "" {
+		settings.Lifecycle = &types.IndexSettingsLifecycle{Name: c.spec.ILMPolicy}
+	}
+	if c.spec.NumberOfShards > 0 {
+		settings.NumberOfShards = strconv.Itoa(c.spec.NumberOfShards)
+	}
+	if c.spec.NumberOfReplicas != nil {
+		settings.NumberOfReplicas = strconv.Itoa(*c.spec.NumberOfReplicas)
+	}
+	return settings
+}
+
+// deleteTableIndices deletes the indices (or the data streams) the table was written to.
+func (c *Client) deleteTableIndices(ctx context.Context, table *schema.Table) error {
+	pat := c.getIndexNamePattern(table)
+	if c.useDataStream(table) {
+		if err := c.deleteDataStreams(ctx, pat); err != nil {
+			return fmt.Errorf("failed to delete data streams: %w", err)
+		}
+		return nil
+	}
+
+	indicesToDelete := []string{pat}
+	if strings.Contains(pat, "*") {
+		resp, err := c.client.Indices.Get([]string{pat},
+			c.client.Indices.Get.WithContext(ctx),
+			c.client.Indices.Get.WithIgnoreUnavailable(true),
+			c.client.Indices.Get.WithFeatures("aliases"),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get indices: %w", err)
+		}
+		if resp.IsError() {
+			return fmt.Errorf("failed to get indices: %s", resp.String())
+		}
+
+		var indices map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&indices); err != nil {
+			return fmt.Errorf("failed to decode response body: %w", err)
+		}
+		_ = resp.Body.Close()
+
+		indicesToDelete = maps.Keys(indices)
+	}
+
+	if len(indicesToDelete) > 0 {
+		if err := c.deleteIndices(ctx, indicesToDelete); err != nil {
+			return fmt.Errorf("failed to delete indices: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) deleteDataStreams(ctx context.Context, pattern string) error {
+	c.logger.Debug().Str("pattern", pattern).Msg("deleting data streams")
+	resp, err := c.client.Indices.DeleteDataStream([]string{pattern},
+		c.client.Indices.DeleteDataStream.WithContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete data streams: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete data streams: %s", resp.String())
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
 func (c *Client) deleteIndices(ctx context.Context, names []string) error {
 	c.logger.Debug().Strs("indices", names).Msg("deleting indices")
 	resp, err := c.client.Indices.Delete(names,
@@ -2,7 +2,10 @@ package client
 
 import (
 	_ "embed"
+	"fmt"
 	"runtime"
+	"slices"
+	"strings"
 
 	"github.com/invopop/jsonschema"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
@@ -11,6 +14,12 @@ import (
 const (
 	defaultBatchSize      = 1000
 	defaultBatchSizeBytes = 5 * 1024 * 1024
+
+	defaultRefresh    = "wait_for"
+	defaultMaxRetries = 5
+
+	defaultIndexName       = varTable
+	defaultAppendIndexName = varTable + "-" + varYear + "-" + varMonth + "-" + varDay
 )
 
 type Spec struct {
@@ -49,6 +58,70 @@ type Spec struct {
 
 	// Number of bytes to batch together per request.
 	BatchSizeBytes int `json:"batch_size_bytes" jsonschema:"minimum=1,default=5242880"`
+
+	// Controls when the written documents become visible to search, see the
+	// [`refresh` parameter](https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-refresh.html):
+	//
+	// - `wait_for` waits for the next refresh of the index before completing each batch
+	// - `true` refreshes the index after each batch
+	// - `false` doesn't wait for the documents to be visible, which is the fastest option for busy clusters
+	Refresh string `json:"refresh" jsonschema:"enum=wait_for,enum=true,enum=false,default=wait_for"`
+
+	// Maximum number of retries of the requests and of the documents rejected with `429 Too Many Requests`.
+	// The retries use exponential backoff.
+	MaxRetries *int `json:"max_retries" jsonschema:"minimum=0,default=5"`
+
+	// Name of the index the documents not matching the index mappings are written to,
+	// together with the table, the target index and the error.
+	// If not set, these documents are reported as write errors.
+	DeadLetterIndex string `json:"dead_letter_index" jsonschema:"example=cq-dead-letter"`
+
+	// Template of the names of the indices the tables with primary keys are written to.
+	// The template supports the following placeholder variables:
+	//
+	// - `{{TABLE}}` will be replaced with the table name (required)
+	// - `{{YEAR}}` will be replaced with the current year in `YYYY` format
+	// - `{{MONTH}}` will be replaced with the current month in `MM` format
+	// - `{{DAY}}` will be replaced with the current day in `DD` format
+	// - `{{HOUR}}` will be replaced with the current hour in `HH` format
+	// - `{{SYNC_ID}}` will be replaced with the unique identifier of the sync
+	//
+	// The other placeholders must be separated from `{{TABLE}}` by a character that can't be part of a table name, such as `-` or `.`.
+	//
+	// **Note** that timestamps are in `UTC` and will be the current time at the time the batch is written, not when the sync started.
+	IndexName string `json:"index_name" jsonschema:"default={{TABLE}},example=cq-{{TABLE}}"`
+
+	// Template of the names of the indices (or data streams, if `data_streams` is `true`) the tables without primary keys,
+	// such as the tables synced in `append` mode, are written to.
+	// Supports the same placeholder variables as `index_name`.
+	// Defaults to `{{TABLE}}-{{YEAR}}-{{MONTH}}-{{DAY}}`, or `{{TABLE}}` if `data_streams` is `true`.
+	AppendIndexName string `json:"append_index_name" jsonschema:"example={{TABLE}}-{{YEAR}}-{{MONTH}}"`
+
+	// If `true`, the tables without primary keys are written to [data streams](https://www.elastic.co/guide/en/elasticsearch/reference/current/data-streams.html)
+	// instead of regular indices.
+	// The `@timestamp` field of the documents is set to the `_cq_sync_time` column.
+	DataStreams bool `json:"data_streams" jsonschema:"default=false"`
+
+	// Name of the [ILM policy](https://www.elastic.co/guide/en/elasticsearch/reference/current/index-lifecycle-management.html)
+	// attached to the indices and data streams created by the plugin.
+	// The policy has to exist in the cluster.
+	ILMPolicy string `json:"ilm_policy"`
+
+	// Number of primary shards of the indices created by the plugin.
+	// If not set, the cluster default is used.
+	NumberOfShards int `json:"number_of_shards" jsonschema:"minimum=0"`
+
+	// Number of replicas of each primary shard of the indices created by the plugin.
+	// If not set, the cluster default is used.
+	NumberOfReplicas *int `json:"number_of_replicas" jsonschema:"minimum=0"`
+
+	// Overrides the [mappings](https://www.elastic.co/guide/en/elasticsearch/reference/current/mapping-types.html) of the columns,
+	// by table name (or glob pattern) and column name.
+	// For example, `{"aws_*": {"arn": {"type": "keyword"}}}` maps the `arn` column of the AWS tables as `keyword` instead of `text`.
+	//
+	// If several entries match the table, the mapping of the column is taken from the most specific one:
+	// exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+	ColumnMappings map[string]map[string]map[string]any `json:"column_mappings"`
 }
 
 //go:embed schema.json
@@ -67,9 +140,114 @@ func (s *Spec) SetDefaults() {
 	if s.BatchSizeBytes == 0 {
 		s.BatchSizeBytes = defaultBatchSizeBytes
 	}
+	if s.Refresh == "" {
+		s.Refresh = defaultRefresh
+	}
+	if s.MaxRetries == nil {
+		maxRetries := defaultMaxRetries
+		s.MaxRetries = &maxRetries
+	}
+	if s.IndexName == "" {
+		s.IndexName = defaultIndexName
+	}
+	if s.AppendIndexName == "" {
+		if s.DataStreams {
+			s.AppendIndexName = defaultIndexName
+		} else {
+			s.AppendIndexName = defaultAppendIndexName
+		}
+	}
 }
 
-func (*Spec) Validate() error {
+func (s *Spec) Validate() error {
+	switch s.Refresh {
+	case "wait_for", "true", "false":
+	default:
+		return fmt.Errorf("invalid refresh %q", s.Refresh)
+	}
+	if s.MaxRetries != nil && *s.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+	if s.DeadLetterIndex != "" {
+		if err := validateIndexName(s.DeadLetterIndex); err != nil {
+			return fmt.Errorf("invalid dead_letter_index: %w", err)
+		}
+	}
+	if err := validateIndexNameTemplate(s.IndexName); err != nil {
+		return fmt.Errorf("invalid index_name: %w", err)
+	}
+	if err := validateIndexNameTemplate(s.AppendIndexName); err != nil {
+		return fmt.Errorf("invalid append_index_name: %w", err)
+	}
+	if s.NumberOfShards < 0 {
+		return fmt.Errorf("number_of_shards must not be negative")
+	}
+	if s.NumberOfReplicas != nil && *s.NumberOfReplicas < 0 {
+		return fmt.Errorf("number_of_replicas must not be negative")
+	}
+	for pattern, columns := range s.ColumnMappings {
+		for column, mapping := range columns {
+			if len(mapping) == 0 {
+				return fmt.Errorf("empty column_mappings entry for column %q of tables %q", column, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+func validateIndexNameTemplate(template string) error {
+	if !strings.Contains(template, varTable) {
+		return fmt.Errorf("the %s placeholder must be present", varTable)
+	}
+	if strings.Contains(strings.NewReplacer(varTable, "", varYear, "", varMonth, "", varDay, "", varHour, "", varSyncID, "").Replace(template), "{{") {
+		return fmt.Errorf("unknown placeholder in %q", template)
+	}
+	if err := validateTableSeparators(template); err != nil {
+		return err
+	}
+	// the placeholders are replaced with sample values, as only the rest of the template is checked
+	return validateIndexName(strings.NewReplacer(varTable, "table", varYear, "2006", varMonth, "01", varDay, "02", varHour, "15", varSyncID, "sync").Replace(template))
+}
+
+// validateTableSeparators checks that the placeholders replaced by wildcards in the index name patterns are separated from `{{TABLE}}`
+// by a character table names can't contain, so that the pattern of a table (such as `aws_ec2-*`) doesn't match the indices of other tables
+// (such as `aws_ec2_instances-2024`).
+func validateTableSeparators(template string) error {
+	wildcards := []string{varYear, varMonth, varDay, varHour, varSyncID}
+	hasWildcard := func(s string) bool {
+		return slices.ContainsFunc(wildcards, func(v string) bool { return strings.Contains(s, v) })
+	}
+	for rest, offset := template, 0; ; {
+		idx := strings.Index(rest, varTable)
+		if idx < 0 {
+			return nil
+		}
+		before, after := template[:offset+idx], rest[idx+len(varTable):]
+		if hasWildcard(before) && isTableNameChar(before[len(before)-1]) {
+			return fmt.Errorf("%s must be preceded by a separator that can't be part of a table name, such as \"-\" or \".\", in %q", varTable, template)
+		}
+		if hasWildcard(after) && isTableNameChar(after[0]) {
+			return fmt.Errorf("%s must be followed by a separator that can't be part of a table name, such as \"-\" or \".\", in %q", varTable, template)
+		}
+		rest, offset = after, offset+idx+len(varTable)
+	}
+}
+
+// isTableNameChar reports whether the character can be part of a table name, or of a placeholder
+func isTableNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '{' || c == '}'
+}
+
+func validateIndexName(name string) error {
+	if strings.ContainsAny(name, `\/*?"<>| ,#:{}`) {
+		return fmt.Errorf("%q contains characters not allowed in index names", name)
+	}
+	if strings.ToLower(name) != name {
+		return fmt.Errorf("%q must be lowercase", name)
+	}
+	if strings.HasPrefix(name, "-") || strings.HasPrefix(name, "_") || strings.HasPrefix(name, "+") {
+		return fmt.Errorf("%q must not start with -, _ or +", name)
+	}
 	return nil
 }
 
@@ -93,4 +271,9 @@ func (Spec) JSONSchemaExtend(sc *jsonschema.Schema) {
 		}(),
 		Required: []string{"addresses", "cloud_id"},
 	}
+
+	// the index name templates have to contain the table name, unless empty to use the default
+	for _, name := range []string{"index_name", "append_index_name"} {
+		sc.Properties.Value(name).Pattern = `^$|\{\{TABLE\}\}`
+	}
 }
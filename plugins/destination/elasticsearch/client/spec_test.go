@@ -55,5 +55,96 @@ func TestJSONSchema(t *testing.T) {
 			Name: "spec with valid cloud_id",
 			Spec: `{"cloud_id": "cloud_id"}`,
 		},
+		{
+			Name: "spec with index_name",
+			Spec: `{"index_name": "cq-{{TABLE}}-{{SYNC_ID}}"}`,
+		},
+		{
+			Name: "spec with index_name without table",
+			Spec: `{"index_name": "cq-{{YEAR}}"}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with append_index_name without table",
+			Spec: `{"append_index_name": "cq"}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with data_streams",
+			Spec: `{"data_streams": true, "ilm_policy": "policy"}`,
+		},
+		{
+			Name: "spec with shards & replicas",
+			Spec: `{"number_of_shards": 3, "number_of_replicas": 0}`,
+		},
+		{
+			Name: "spec with negative number_of_replicas",
+			Spec: `{"number_of_replicas": -1}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with refresh",
+			Spec: `{"refresh": "false"}`,
+		},
+		{
+			Name: "spec with invalid refresh",
+			Spec: `{"refresh": "never"}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with max_retries & dead_letter_index",
+			Spec: `{"max_retries": 0, "dead_letter_index": "cq-dead-letter"}`,
+		},
+		{
+			Name: "spec with negative max_retries",
+			Spec: `{"max_retries": -1}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with column_mappings",
+			Spec: `{"column_mappings": {"aws_*": {"arn": {"type": "keyword"}}}}`,
+		},
+		{
+			Name: "spec with invalid column_mappings",
+			Spec: `{"column_mappings": {"aws_*": {"arn": "keyword"}}}`,
+			Err:  true,
+		},
 	})
 }
+
+func TestSpecValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		spec Spec
+		err  bool
+	}{
+		{name: "defaults", spec: Spec{}},
+		{name: "prefix", spec: Spec{IndexName: "cq-{{TABLE}}", AppendIndexName: "cq-{{TABLE}}-{{YEAR}}.{{MONTH}}"}},
+		{name: "missing table", spec: Spec{IndexName: "cq"}, err: true},
+		{name: "unknown placeholder", spec: Spec{IndexName: "{{TABLE}}-{{MINUTE}}"}, err: true},
+		{name: "invalid character", spec: Spec{IndexName: "cq*{{TABLE}}"}, err: true},
+		{name: "uppercase", spec: Spec{AppendIndexName: "CQ-{{TABLE}}"}, err: true},
+		{name: "invalid prefix", spec: Spec{IndexName: "_{{TABLE}}"}, err: true},
+		{name: "suffix without separator", spec: Spec{AppendIndexName: "{{TABLE}}{{YEAR}}"}, err: true},
+		{name: "suffix with table name separator", spec: Spec{AppendIndexName: "{{TABLE}}_{{YEAR}}"}, err: true},
+		{name: "prefix with table name separator", spec: Spec{IndexName: "{{SYNC_ID}}_{{TABLE}}"}, err: true},
+		{name: "placeholder prefix", spec: Spec{IndexName: "{{SYNC_ID}}-{{TABLE}}", AppendIndexName: "cq_{{TABLE}}.{{YEAR}}{{MONTH}}"}},
+		{name: "negative shards", spec: Spec{NumberOfShards: -1}, err: true},
+		{name: "invalid refresh", spec: Spec{Refresh: "never"}, err: true},
+		{name: "dead letter index", spec: Spec{DeadLetterIndex: "cq-dead-letter"}},
+		{name: "invalid dead letter index", spec: Spec{DeadLetterIndex: "Dead Letter"}, err: true},
+		{name: "empty column mapping", spec: Spec{ColumnMappings: map[string]map[string]map[string]any{"*": {"arn": {}}}}, err: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.spec.SetDefaults()
+			err := tc.spec.Validate()
+			if tc.err && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tc.err && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
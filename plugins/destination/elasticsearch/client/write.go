@@ -1,11 +1,9 @@
 package client
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"strings"
 	"time"
 
@@ -17,10 +15,8 @@ import (
 	"github.com/segmentio/fasthash/fnv1a"
 )
 
-type bulkResponse struct {
-	Took   int64 `json:"took"`
-	Errors bool  `json:"errors"`
-}
+// timestampField is the field data streams require the documents to have.
+const timestampField = "@timestamp"
 
 func (c *Client) Write(ctx context.Context, msgs <-chan message.WriteMessage) error {
 	if err := c.writer.Write(ctx, msgs); err != nil {
@@ -39,72 +35,52 @@ func (c *Client) WriteTableBatch(ctx context.Context, _ string, msgs message.Wri
 
 	// all messages correspond to the same table
 	table := msgs[0].GetTable()
-	data := new(bytes.Buffer)
+	var items []bulkItem
 	for _, msg := range msgs {
-		if err := c.appendToWriteBuffer(table, msg.Record, data); err != nil {
+		var err error
+		if items, err = c.appendBulkItems(table, msg.Record, items); err != nil {
 			return err
 		}
 	}
 
-	return c.writeData(ctx, table, data)
+	return c.writeData(ctx, table, items)
 }
 
-func (c *Client) appendToWriteBuffer(table *schema.Table, record arrow.Record, buf *bytes.Buffer) error {
+func (c *Client) appendBulkItems(table *schema.Table, record arrow.Record, items []bulkItem) ([]bulkItem, error) {
 	pks := table.PrimaryKeysIndexes() // do some work up front to avoid doing it for every resource
+	dataStream := c.useDataStream(table)
+	now := time.Now().UTC().Format(time.RFC3339Nano)
 	for r := 0; r < int(record.NumRows()); r++ {
 		doc := map[string]any{}
 		for i, col := range record.Columns() {
 			doc[record.ColumnName(i)] = c.getValueForElasticsearch(col, r)
 		}
+		if dataStream {
+			// data streams require a timestamp, which is the sync time if available
+			doc[timestampField] = now
+			if syncTime, ok := doc[schema.CqSyncTimeColumn.Name]; ok && syncTime != nil {
+				doc[timestampField] = syncTime
+			}
+		}
 		data, err := json.Marshal(doc)
 		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %w", err)
+			return nil, fmt.Errorf("failed to marshal JSON: %w", err)
 		}
 
 		var meta []byte
-		if len(pks) > 0 {
+		switch {
+		case dataStream:
+			// data streams only accept the create operation
+			meta = []byte(`{"create":{}}`)
+		case len(pks) > 0:
 			docID := fmt.Sprint(resourceID(record, r, pks))
-			meta = []byte(fmt.Sprintf(`{"index":{"_id":"%s"}}%s`, docID, "\n"))
-		} else {
-			meta = []byte(`{"index":{}}` + "\n")
+			meta = []byte(fmt.Sprintf(`{"index":{"_id":"%s"}}`, docID))
+		default:
+			meta = []byte(`{"index":{}}`)
 		}
-		data = append(data, "\n"...)
-		buf.Grow(len(meta) + len(data))
-		buf.Write(meta)
-		buf.Write(data)
-	}
-	return nil
-}
-
-func (c *Client) writeData(ctx context.Context, table *schema.Table, buf *bytes.Buffer) error {
-	// get the sync time from the first resource in the batch (here we assume that all resources in the batch
-	// have the same sync time. At the moment this assumption holds.)
-	syncTime := time.Now()
-	index := c.getIndexName(table, syncTime)
-	resp, err := c.client.Bulk(bytes.NewReader(buf.Bytes()),
-		c.client.Bulk.WithContext(ctx),
-		c.client.Bulk.WithIndex(index),
-		c.client.Bulk.WithRefresh("wait_for"), // returns only once the data is written
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create bulk request: %w", err)
+		items = append(items, bulkItem{meta: meta, doc: data})
 	}
-	defer resp.Body.Close()
-	if resp.IsError() {
-		return fmt.Errorf("bulk request failed: %s", resp.String())
-	}
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read bulk response: %w", err)
-	}
-	var bulkResp bulkResponse
-	if err := json.Unmarshal(b, &bulkResp); err != nil {
-		return fmt.Errorf("failed to unmarshal bulk response: %w", err)
-	}
-	if bulkResp.Errors {
-		return fmt.Errorf("bulk request had errors: %s", string(b))
-	}
-	return nil
+	return items, nil
 }
 
 func (c *Client) getValueForElasticsearch(col arrow.Array, i int) any {
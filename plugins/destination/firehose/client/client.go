@@ -3,6 +3,8 @@ package client
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -11,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/firehose"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/cloudquery/cloudquery/plugins/destination/firehose/client/spec"
+	"github.com/cloudquery/filetypes/v4"
 	"github.com/cloudquery/plugin-sdk/v4/plugin"
 	"github.com/cloudquery/plugin-sdk/v4/schema"
 	"github.com/goccy/go-json"
@@ -19,13 +22,24 @@ import (
 )
 
 type Client struct {
-	firehoseClient *firehose.Client
-	spec           spec.Spec
+	// streams by ARN
+	streams map[string]*stream
+	spec    spec.Spec
+
+	// nil if `format` isn't set
+	*filetypes.Client
+	deadLetterFile *os.File
 
 	logger zerolog.Logger
 	plugin.UnimplementedSource
 }
 
+type stream struct {
+	arn            string
+	name           string
+	firehoseClient *firehose.Client
+}
+
 var _ plugin.Client = (*Client)(nil)
 
 func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, _ plugin.NewClientOptions) (plugin.Client, error) {
@@ -38,25 +52,65 @@ func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, _ plugin.
 		return nil, err
 	}
 
-	parsedARN, err := arn.Parse(s.StreamARN)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse firehose stream ARN: %w", err)
+	streamARNs := s.StreamARNs()
+	parsedARNs := make([]arn.ARN, len(streamARNs))
+	for i, streamARN := range streamARNs {
+		parsedARN, err := arn.Parse(streamARN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse firehose stream ARN: %w", err)
+		}
+		parsedARNs[i] = parsedARN
 	}
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(parsedARN.Region))
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(parsedARNs[0].Region))
 	if err != nil {
 		return nil, fmt.Errorf("unable to load AWS SDK config: %w", err)
 	}
 	if err := validateCredentials(ctx, cfg); err != nil {
 		return nil, err
 	}
-	return &Client{
-		logger:         logger.With().Str("module", "firehose").Logger(),
-		spec:           s,
-		firehoseClient: firehose.NewFromConfig(cfg),
-	}, nil
+
+	c := &Client{
+		logger:  logger.With().Str("module", "firehose").Logger(),
+		spec:    s,
+		streams: make(map[string]*stream, len(streamARNs)),
+	}
+	// streams in the same region share the client
+	firehoseClients := make(map[string]*firehose.Client)
+	for i, parsedARN := range parsedARNs {
+		arnResource := strings.Split(parsedARN.Resource, "/")
+		if len(arnResource) != 2 {
+			return nil, fmt.Errorf("invalid firehose stream ARN: %s", streamARNs[i])
+		}
+		firehoseClient, ok := firehoseClients[parsedARN.Region]
+		if !ok {
+			region := parsedARN.Region
+			firehoseClient = firehose.NewFromConfig(cfg, func(o *firehose.Options) { o.Region = region })
+			firehoseClients[region] = firehoseClient
+		}
+		c.streams[streamARNs[i]] = &stream{arn: streamARNs[i], name: arnResource[1], firehoseClient: firehoseClient}
+	}
+
+	if s.Format != "" {
+		c.Client, err = filetypes.NewClient(&c.spec.FileSpec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create filetypes client: %w", err)
+		}
+	}
+	if s.OversizedRecords == spec.OversizedRecordsDeadLetter {
+		c.deadLetterFile, err = os.OpenFile(s.DeadLetterFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open dead letter file: %w", err)
+		}
+	}
+	return c, nil
 }
 
-func (*Client) Close(context.Context) error { return nil }
+func (c *Client) Close(context.Context) error {
+	if c.deadLetterFile != nil {
+		return c.deadLetterFile.Close()
+	}
+	return nil
+}
 
 func (*Client) Read(context.Context, *schema.Table, chan<- arrow.Record) error {
 	return plugin.ErrNotImplemented
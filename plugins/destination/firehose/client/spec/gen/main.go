@@ -7,13 +7,22 @@ import (
 	"runtime"
 
 	"github.com/cloudquery/cloudquery/plugins/destination/firehose/client/spec"
-	"github.com/cloudquery/codegen/jsonschema"
+	cqjsonschema "github.com/cloudquery/codegen/jsonschema"
+	"github.com/cloudquery/filetypes/v4"
+	"github.com/invopop/jsonschema"
 )
 
 func main() {
 	fmt.Println("Generating JSON schema for plugin spec")
-	jsonschema.GenerateIntoFile(new(spec.Spec), path.Join(currDir(), "..", "schema.json"),
-		jsonschema.WithAddGoComments("github.com/cloudquery/cloudquery/plugins/destination/firehose/client/spec", path.Join(currDir(), "..")),
+	cqjsonschema.GenerateIntoFile(new(spec.Spec), path.Join(currDir(), "..", "schema.json"),
+		append(filetypes.FileSpec{}.JSONSchemaOptions(),
+			cqjsonschema.WithAddGoComments("github.com/cloudquery/cloudquery/plugins/destination/firehose/client/spec", path.Join(currDir(), "..")),
+			cqjsonschema.WithAddGoComments("github.com/cloudquery/filetypes/v4", path.Join(currDir(), "..", "..", "..", "vendor", "github.com/cloudquery/filetypes/v4")),
+			func(r *jsonschema.Reflector) {
+				// not required for this plugin
+				r.NullableFromType = false
+			},
+		)...,
 	)
 }
 
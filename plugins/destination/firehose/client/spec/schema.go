@@ -0,0 +1,62 @@
+package spec
+
+import (
+	"slices"
+
+	"github.com/cloudquery/filetypes/v4"
+	"github.com/invopop/jsonschema"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func (s Spec) JSONSchemaExtend(sc *jsonschema.Schema) {
+	s.FileSpec.JSONSchemaExtend(sc) // need to call manually
+
+	// `format` is optional, rows are sent as JSON objects with the table name added if it isn't set
+	sc.Required = slices.DeleteFunc(sc.Required, func(property string) bool { return property == "format" })
+
+	// Firehose concatenates the records into files, and concatenated Parquet files can't be read
+	format := sc.Properties.Value("format")
+	format.Enum = slices.DeleteFunc(format.Enum, func(value any) bool { return value == string(filetypes.FormatTypeParquet) })
+	formatSpec := &jsonschema.Schema{OneOf: slices.DeleteFunc(sc.OneOf, func(option *jsonschema.Schema) bool {
+		return option.Properties.Value("format").Const == string(filetypes.FormatTypeParquet)
+	})}
+	sc.OneOf = nil
+
+	sc.Properties.Value("streams").MinProperties = &([]uint64{1}[0])
+	sc.Properties.Value("streams").AdditionalProperties.MinLength = &([]uint64{1}[0])
+
+	required := func(property string) *jsonschema.Schema {
+		return &jsonschema.Schema{Required: []string{property}}
+	}
+
+	sc.AllOf = append(sc.AllOf,
+		&jsonschema.Schema{
+			Title: "Validate `format_spec` when `format` is set",
+			If:    required("format"),
+			Then:  formatSpec,
+		},
+		&jsonschema.Schema{
+			Title: "Require `stream_arn` or `streams`",
+			AnyOf: []*jsonschema.Schema{required("stream_arn"), required("streams")},
+		},
+		&jsonschema.Schema{
+			Title: "Require `dead_letter_file` when `oversized_records` is `dead_letter`",
+			If: &jsonschema.Schema{
+				Properties: func() *orderedmap.OrderedMap[string, *jsonschema.Schema] {
+					properties := orderedmap.New[string, *jsonschema.Schema]()
+					properties.Set("oversized_records", &jsonschema.Schema{Type: "string", Const: string(OversizedRecordsDeadLetter)})
+					return properties
+				}(),
+				Required: []string{"oversized_records"},
+			},
+			Then: &jsonschema.Schema{
+				Properties: func() *orderedmap.OrderedMap[string, *jsonschema.Schema] {
+					properties := orderedmap.New[string, *jsonschema.Schema]()
+					properties.Set("dead_letter_file", &jsonschema.Schema{Type: "string", MinLength: &([]uint64{1}[0])})
+					return properties
+				}(),
+				Required: []string{"dead_letter_file"},
+			},
+		},
+	)
+}
@@ -3,15 +3,45 @@ package spec
 import (
 	_ "embed"
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/cloudquery/filetypes/v4"
+	"github.com/cloudquery/plugin-sdk/v4/glob"
+)
+
+type OversizedRecords string
+
+const (
+	// OversizedRecordsSkip skips records larger than `max_record_size_bytes` with a warning
+	OversizedRecordsSkip OversizedRecords = "skip"
+	// OversizedRecordsError fails the sync when a record is larger than `max_record_size_bytes`
+	OversizedRecordsError OversizedRecords = "error"
+	// OversizedRecordsDeadLetter appends records larger than `max_record_size_bytes` to `dead_letter_file`
+	OversizedRecordsDeadLetter OversizedRecords = "dead_letter"
 )
 
 // Amazon Kinesis Firehose destination plugin spec.
 type Spec struct {
+	filetypes.FileSpec
+
 	// Kinesis Firehose delivery stream ARN where data will be sent to.
 	// Format: `arn:${Partition}:firehose:${Region}:${Account}:deliverystream/${DeliveryStreamName}`.
-	StreamARN string `json:"stream_arn" jsonschema:"required,minLength=1"`
+	//
+	// Required unless `streams` is set, in which case it's used for the tables that don't match any of the `streams` entries.
+	StreamARN string `json:"stream_arn,omitempty" jsonschema:"minLength=1"`
+
+	// Map of table names or glob patterns to the Kinesis Firehose delivery stream ARN the matching tables are sent to, for example:
+	//
+	// ```yaml
+	// streams:
+	//   aws_ec2_instances: "arn:aws:firehose:us-east-1:111122223333:deliverystream/instances"
+	//   "aws_s3_*": "arn:aws:firehose:us-east-1:111122223333:deliverystream/s3"
+	// ```
+	//
+	// Exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+	// Tables that don't match any entry are sent to `stream_arn`.
+	Streams map[string]string `json:"streams,omitempty"`
 
 	// Amount of retries to perform when writing a batch.
 	MaxRetries int `json:"max_retries,omitempty" jsonschema:"minimum=1,default=5"`
@@ -24,6 +54,19 @@ type Spec struct {
 
 	// Number of bytes allowed in a single batch.
 	MaxBatchSizeBytes int `json:"max_batch_size_bytes,omitempty" jsonschema:"minimum=1,default=4194000"`
+
+	// What to do with records larger than `max_record_size_bytes`.
+	//
+	// - `skip` skips the record and logs a warning
+	//
+	// - `error` fails the sync
+	//
+	// - `dead_letter` appends the record to `dead_letter_file`
+	OversizedRecords OversizedRecords `json:"oversized_records,omitempty" jsonschema:"enum=skip,enum=error,enum=dead_letter,default=skip"`
+
+	// Path of the local file that records larger than `max_record_size_bytes` are appended to when `oversized_records` is `dead_letter`.
+	// Each line of the file is a JSON object with the `table_name`, `stream_arn` and base64-encoded `data` of a record.
+	DeadLetterFile string `json:"dead_letter_file,omitempty"`
 }
 
 func (s *Spec) SetDefaults() {
@@ -42,13 +85,54 @@ func (s *Spec) SetDefaults() {
 		const defaultMaxBatchSizeBytes = 4194000
 		s.MaxBatchSizeBytes = defaultMaxBatchSizeBytes
 	}
+	if s.OversizedRecords == "" {
+		s.OversizedRecords = OversizedRecordsSkip
+	}
 }
 
 func (s *Spec) Validate() error {
-	if len(s.StreamARN) == 0 {
+	if len(s.StreamARN) == 0 && len(s.Streams) == 0 {
 		return fmt.Errorf("kinesis firehose Stream ARN is required")
 	}
-	parsedARN, err := arn.Parse(s.StreamARN)
+	if len(s.StreamARN) > 0 {
+		if err := validateStreamARN(s.StreamARN); err != nil {
+			return err
+		}
+	}
+	for pattern, streamARN := range s.Streams {
+		if err := validateStreamARN(streamARN); err != nil {
+			return fmt.Errorf("%w for %s", err, pattern)
+		}
+	}
+
+	switch s.OversizedRecords {
+	case "", OversizedRecordsSkip, OversizedRecordsError:
+	case OversizedRecordsDeadLetter:
+		if len(s.DeadLetterFile) == 0 {
+			return fmt.Errorf("dead_letter_file is required when oversized_records is %q", OversizedRecordsDeadLetter)
+		}
+	default:
+		return fmt.Errorf("invalid oversized_records: %q", s.OversizedRecords)
+	}
+
+	if s.Format == "" {
+		// rows are sent as JSON objects with the table name added
+		return nil
+	}
+	if s.Format == filetypes.FormatTypeParquet {
+		// each row would be a separate Parquet file, and the files concatenated by Firehose can't be read
+		return fmt.Errorf("parquet format is not supported, as Firehose concatenates the records into files")
+	}
+	// required for s.FileSpec.Validate call
+	if err := s.FileSpec.UnmarshalSpec(); err != nil {
+		return err
+	}
+	s.FileSpec.SetDefaults()
+	return s.FileSpec.Validate()
+}
+
+func validateStreamARN(streamARN string) error {
+	parsedARN, err := arn.Parse(streamARN)
 	if err != nil {
 		return fmt.Errorf("kinesis firehose Stream ARN is invalid")
 	}
@@ -58,5 +142,50 @@ func (s *Spec) Validate() error {
 	return nil
 }
 
+// StreamARNs returns all the distinct stream ARNs of the spec
+func (s *Spec) StreamARNs() []string {
+	var streamARNs []string
+	seen := make(map[string]bool, len(s.Streams)+1)
+	add := func(streamARN string) {
+		if len(streamARN) > 0 && !seen[streamARN] {
+			seen[streamARN] = true
+			streamARNs = append(streamARNs, streamARN)
+		}
+	}
+	add(s.StreamARN)
+	for _, pattern := range s.streamPatterns() {
+		add(s.Streams[pattern])
+	}
+	return streamARNs
+}
+
+func (s *Spec) streamPatterns() []string {
+	patterns := make([]string, 0, len(s.Streams))
+	for pattern := range s.Streams {
+		patterns = append(patterns, pattern)
+	}
+	// longer patterns are more specific, so they're matched first
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return patterns
+}
+
+// TableStreamARN returns the stream ARN the table is sent to, or an empty string if no stream is configured for the table
+func (s *Spec) TableStreamARN(table string) string {
+	if streamARN, ok := s.Streams[table]; ok {
+		return streamARN
+	}
+	for _, pattern := range s.streamPatterns() {
+		if glob.Glob(pattern, table) {
+			return s.Streams[pattern]
+		}
+	}
+	return s.StreamARN
+}
+
 //go:embed schema.json
 var JSONSchema string
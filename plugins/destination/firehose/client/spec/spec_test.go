@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/cloudquery/codegen/jsonschema"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSpec_JSONSchema(t *testing.T) {
@@ -148,6 +149,79 @@ func TestSpec_JSONSchema(t *testing.T) {
 			Name: "proper max_batch_size_bytes",
 			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "max_batch_size_bytes": 123}`,
 		},
+		{
+			Name: "streams only",
+			Spec: `{"streams": {"aws_*": "arn:aws:firehose:us-east-1:01234:deliverystream/name"}}`,
+		},
+		{
+			Name: "streams with stream_arn",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "streams": {"aws_*": "arn:aws:firehose:us-east-1:01234:deliverystream/aws"}}`,
+		},
+		{
+			Name: "empty streams",
+			Spec: `{"streams": {}}`,
+			Err:  true,
+		},
+		{
+			Name: "empty streams value",
+			Spec: `{"streams": {"aws_*": ""}}`,
+			Err:  true,
+		},
+		{
+			Name: "null streams",
+			Spec: `{"streams": null}`,
+			Err:  true,
+		},
+		{
+			Name: "csv format",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "format": "csv", "format_spec": {"skip_header": true}}`,
+		},
+		{
+			Name: "parquet format",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "format": "parquet"}`,
+			Err:  true,
+		},
+		{
+			Name: "json format with gzip compression",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "format": "json", "compression": "gzip"}`,
+		},
+		{
+			Name: "unknown format",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "format": "xml"}`,
+			Err:  true,
+		},
+		{
+			Name: "csv format_spec with json format",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "format": "json", "format_spec": {"skip_header": true}}`,
+			Err:  true,
+		},
+		{
+			Name: "skip oversized_records",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "oversized_records": "skip"}`,
+		},
+		{
+			Name: "error oversized_records",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "oversized_records": "error"}`,
+		},
+		{
+			Name: "invalid oversized_records",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "oversized_records": "drop"}`,
+			Err:  true,
+		},
+		{
+			Name: "dead_letter oversized_records without dead_letter_file",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "oversized_records": "dead_letter"}`,
+			Err:  true,
+		},
+		{
+			Name: "dead_letter oversized_records with empty dead_letter_file",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "oversized_records": "dead_letter", "dead_letter_file": ""}`,
+			Err:  true,
+		},
+		{
+			Name: "dead_letter oversized_records with dead_letter_file",
+			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "oversized_records": "dead_letter", "dead_letter_file": "./oversized.jsonl"}`,
+		},
 		{
 			Name: "extra key",
 			Spec: `{"stream_arn": "arn:aws:firehose:us-east-1:01234:deliverystream/name", "extra": true}`,
@@ -155,3 +229,43 @@ func TestSpec_JSONSchema(t *testing.T) {
 		},
 	})
 }
+
+func TestSpec_TableStreamARN(t *testing.T) {
+	s := Spec{
+		StreamARN: "arn:aws:firehose:us-east-1:01234:deliverystream/default",
+		Streams: map[string]string{
+			"aws_ec2_instances": "arn:aws:firehose:us-east-1:01234:deliverystream/instances",
+			"aws_ec2_*":         "arn:aws:firehose:us-east-1:01234:deliverystream/ec2",
+			"aws_*":             "arn:aws:firehose:us-east-1:01234:deliverystream/aws",
+		},
+	}
+	require.Equal(t, "arn:aws:firehose:us-east-1:01234:deliverystream/instances", s.TableStreamARN("aws_ec2_instances"))
+	require.Equal(t, "arn:aws:firehose:us-east-1:01234:deliverystream/ec2", s.TableStreamARN("aws_ec2_images"))
+	require.Equal(t, "arn:aws:firehose:us-east-1:01234:deliverystream/aws", s.TableStreamARN("aws_s3_buckets"))
+	require.Equal(t, "arn:aws:firehose:us-east-1:01234:deliverystream/default", s.TableStreamARN("gcp_projects"))
+
+	s.StreamARN = ""
+	require.Empty(t, s.TableStreamARN("gcp_projects"))
+	require.ElementsMatch(t, []string{
+		"arn:aws:firehose:us-east-1:01234:deliverystream/instances",
+		"arn:aws:firehose:us-east-1:01234:deliverystream/ec2",
+		"arn:aws:firehose:us-east-1:01234:deliverystream/aws",
+	}, s.StreamARNs())
+}
+
+func TestSpec_Validate(t *testing.T) {
+	s := Spec{Streams: map[string]string{"aws_*": "arn:aws:s3:::bucket"}}
+	require.ErrorContains(t, s.Validate(), "for aws_*")
+
+	s = Spec{StreamARN: "arn:aws:firehose:us-east-1:01234:deliverystream/name", OversizedRecords: OversizedRecordsDeadLetter}
+	require.ErrorContains(t, s.Validate(), "dead_letter_file is required")
+
+	s = Spec{StreamARN: "arn:aws:firehose:us-east-1:01234:deliverystream/name"}
+	s.Format = "csv"
+	s.FormatSpec = map[string]any{"skip_header": true}
+	require.NoError(t, s.Validate())
+
+	s = Spec{StreamARN: "arn:aws:firehose:us-east-1:01234:deliverystream/name"}
+	s.Format = "parquet"
+	require.ErrorContains(t, s.Validate(), "parquet format is not supported")
+}
@@ -5,31 +5,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
+	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/firehose"
 	"github.com/aws/aws-sdk-go-v2/service/firehose/types"
+	"github.com/cloudquery/cloudquery/plugins/destination/firehose/client/spec"
 	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
 )
 
+type batch struct {
+	stream *stream
+	input  *firehose.PutRecordBatchInput
+	// running total of the batch size
+	size int
+}
+
 func (c *Client) Write(ctx context.Context, messages <-chan message.WriteMessage) error {
-	parsedARN, err := arn.Parse(c.spec.StreamARN)
-	if err != nil {
-		c.logger.Error().Err(err).Msg("invalid firehose stream ARN")
-		return err
-	}
-	arnResource := strings.Split(parsedARN.Resource, "/")
-	if len(arnResource) != 2 {
-		c.logger.Error().Err(err).Msg("invalid firehose stream ARN")
-		return fmt.Errorf("invalid firehose stream ARN")
-	}
-	recordsBatchInput := &firehose.PutRecordBatchInput{
-		DeliveryStreamName: aws.String(arnResource[1]),
-	}
-	batchSize := 0
+	// batches by stream ARN
+	batches := make(map[string]*batch, len(c.streams))
+	tableStreams := make(map[string]*stream)
 
 	for m := range messages {
 		switch m := m.(type) {
@@ -50,61 +47,140 @@ func (c *Client) Write(ctx context.Context, messages <-chan message.WriteMessage
 		ins := m.(*message.WriteInsert)
 		table, rec := ins.GetTable(), ins.Record
 
-		for row := int64(0); row < rec.NumRows(); row++ {
-			jsonObj := make(map[string]any, rec.NumCols()+1)
-			for i := range rec.Columns() {
-				jsonObj[rec.ColumnName(i)] = rec.Column(i).GetOneForMarshal(int(row))
-			}
-			// Add table name to the json object
-			// TODO: This should be added to the SDK so that it can be used for other plugins as well
-			jsonObj["_cq_table_name"] = table.Name
-			b, err := json.Marshal(jsonObj)
-			if err != nil {
-				return err
-			}
-			dst := &bytes.Buffer{}
-			err = json.Compact(dst, b)
-			if err != nil {
-				return err
+		s, ok := tableStreams[table.Name]
+		if !ok {
+			s = c.streams[c.spec.TableStreamARN(table.Name)]
+			if s == nil {
+				return fmt.Errorf("no firehose stream configured for table %s", table.Name)
 			}
-			if len(dst.Bytes()) > c.spec.MaxRecordSizeBytes {
-				c.logger.Warn().Msgf("skipping record because it is too large: %s", string(b))
+			tableStreams[table.Name] = s
+		}
+		b, ok := batches[s.arn]
+		if !ok {
+			b = &batch{stream: s, input: &firehose.PutRecordBatchInput{DeliveryStreamName: aws.String(s.name)}}
+			batches[s.arn] = b
+		}
+
+		records, err := c.encode(table, rec)
+		if err != nil {
+			return err
+		}
+		for _, data := range records {
+			if len(data) > c.spec.MaxRecordSizeBytes {
+				if err := c.oversizedRecord(table.Name, s.arn, data); err != nil {
+					return err
+				}
 				continue
 			}
 
 			// If adding this record would exceed the batch size, send the batch
-			if len(dst.Bytes())+batchSize > c.spec.MaxBatchSizeBytes {
-				err := c.sendBatch(ctx, recordsBatchInput, 0)
-				if err != nil {
+			if len(data)+b.size > c.spec.MaxBatchSizeBytes {
+				if err := c.flush(ctx, b); err != nil {
 					return err
 				}
-				recordsBatchInput.Records = recordsBatchInput.Records[:0]
-				batchSize = 0
 			}
 
-			recordsBatchInput.Records = append(recordsBatchInput.Records, types.Record{
-				Data: dst.Bytes(),
-			})
-			// Store a running total of the batch size
-			batchSize += len(dst.Bytes())
+			b.input.Records = append(b.input.Records, types.Record{Data: data})
+			b.size += len(data)
 
 			// Send the batch if it is full
-			if len(recordsBatchInput.Records) >= c.spec.MaxBatchRecords {
-				err := c.sendBatch(ctx, recordsBatchInput, 0)
-				if err != nil {
+			if len(b.input.Records) >= c.spec.MaxBatchRecords {
+				if err := c.flush(ctx, b); err != nil {
 					return err
 				}
-				// Reset the batch
-				recordsBatchInput.Records = recordsBatchInput.Records[:0]
-				batchSize = 0
 			}
 		}
 	}
-	// Send the last batch
-	return c.sendBatch(ctx, recordsBatchInput, 0)
+	// Send the last batches
+	for _, b := range batches {
+		if err := c.flush(ctx, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) flush(ctx context.Context, b *batch) error {
+	if err := c.sendBatch(ctx, b.stream.firehoseClient, b.input, 0); err != nil {
+		return err
+	}
+	// Reset the batch
+	b.input.Records = b.input.Records[:0]
+	b.size = 0
+	return nil
+}
+
+// encode returns the data of the Firehose records of each row
+func (c *Client) encode(table *schema.Table, rec arrow.Record) ([][]byte, error) {
+	if c.Client == nil {
+		return encodeJSON(table, rec)
+	}
+	records := make([][]byte, rec.NumRows())
+	for row := range records {
+		slice := rec.NewSlice(int64(row), int64(row+1))
+		var b bytes.Buffer
+		err := c.Client.WriteTableBatchFile(&b, table, []arrow.Record{slice})
+		slice.Release()
+		if err != nil {
+			return nil, err
+		}
+		records[row] = b.Bytes()
+	}
+	return records, nil
+}
+
+// encodeJSON encodes each row as a compact JSON object, with the `_cq_table_name` key added
+func encodeJSON(table *schema.Table, rec arrow.Record) ([][]byte, error) {
+	records := make([][]byte, rec.NumRows())
+	for row := range records {
+		jsonObj := make(map[string]any, rec.NumCols()+1)
+		for i := range rec.Columns() {
+			jsonObj[rec.ColumnName(i)] = rec.Column(i).GetOneForMarshal(row)
+		}
+		// Add table name to the json object
+		// TODO: This should be added to the SDK so that it can be used for other plugins as well
+		jsonObj["_cq_table_name"] = table.Name
+		b, err := json.Marshal(jsonObj)
+		if err != nil {
+			return nil, err
+		}
+		dst := &bytes.Buffer{}
+		err = json.Compact(dst, b)
+		if err != nil {
+			return nil, err
+		}
+		records[row] = dst.Bytes()
+	}
+	return records, nil
+}
+
+type deadLetterRecord struct {
+	TableName string `json:"table_name"`
+	StreamARN string `json:"stream_arn"`
+	Data      []byte `json:"data"`
+}
+
+func (c *Client) oversizedRecord(tableName, streamARN string, data []byte) error {
+	switch c.spec.OversizedRecords {
+	case spec.OversizedRecordsError:
+		return fmt.Errorf("record of table %s is too large: %d bytes, max_record_size_bytes is %d", tableName, len(data), c.spec.MaxRecordSizeBytes)
+	case spec.OversizedRecordsDeadLetter:
+		c.logger.Warn().Str("table", tableName).Int("size", len(data)).Msg("writing record to dead letter file because it is too large")
+		b, err := json.Marshal(deadLetterRecord{TableName: tableName, StreamARN: streamARN, Data: data})
+		if err != nil {
+			return err
+		}
+		if _, err := c.deadLetterFile.Write(append(b, '\n')); err != nil {
+			return fmt.Errorf("failed to write to dead letter file: %w", err)
+		}
+		return nil
+	default:
+		c.logger.Warn().Str("table", tableName).Int("size", len(data)).Msg("skipping record because it is too large")
+		return nil
+	}
 }
 
-func (c *Client) sendBatch(ctx context.Context, recordsBatchInput *firehose.PutRecordBatchInput, count int) error {
+func (c *Client) sendBatch(ctx context.Context, firehoseClient *firehose.Client, recordsBatchInput *firehose.PutRecordBatchInput, count int) error {
 	if count >= c.spec.MaxRetries {
 		return fmt.Errorf("max retries reached")
 	}
@@ -112,13 +188,13 @@ func (c *Client) sendBatch(ctx context.Context, recordsBatchInput *firehose.PutR
 		return nil
 	}
 	time.Sleep(time.Duration(count) * time.Second)
-	resp, err := c.firehoseClient.PutRecordBatch(ctx, recordsBatchInput)
+	resp, err := firehoseClient.PutRecordBatch(ctx, recordsBatchInput)
 	if err != nil {
 		c.logger.Error().Err(err).Msg("failed to write to firehose")
 		return err
 	}
 	retryRecords := getFailedRecords(recordsBatchInput, resp)
-	return c.sendBatch(ctx, retryRecords, count+1)
+	return c.sendBatch(ctx, firehoseClient, retryRecords, count+1)
 }
 
 func getFailedRecords(recordsBatchInput *firehose.PutRecordBatchInput, resp *firehose.PutRecordBatchOutput) *firehose.PutRecordBatchInput {
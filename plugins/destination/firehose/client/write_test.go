@@ -0,0 +1,135 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/cloudquery/plugins/destination/firehose/client/spec"
+	"github.com/cloudquery/filetypes/v4"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func testRecord(t *testing.T) (*schema.Table, arrow.Record) {
+	t.Helper()
+	table := &schema.Table{
+		Name: "test_table",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "name", Type: arrow.BinaryTypes.String},
+		},
+	}
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2}, nil)
+	bldr.Field(1).(*array.StringBuilder).AppendValues([]string{"a", "b"}, nil)
+	rec := bldr.NewRecord()
+	t.Cleanup(rec.Release)
+	return table, rec
+}
+
+func TestEncode(t *testing.T) {
+	table, rec := testRecord(t)
+
+	c := &Client{}
+	records, err := c.encode(table, rec)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{
+		[]byte(`{"_cq_table_name":"test_table","id":1,"name":"a"}`),
+		[]byte(`{"_cq_table_name":"test_table","id":2,"name":"b"}`),
+	}, records)
+
+	c.Client, err = filetypes.NewClient(&filetypes.FileSpec{Format: filetypes.FormatTypeCSV, FormatSpec: map[string]any{"skip_header": true}})
+	require.NoError(t, err)
+	records, err = c.encode(table, rec)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("1,a\n"), []byte("2,b\n")}, records)
+
+	c.Client, err = filetypes.NewClient(&filetypes.FileSpec{Format: filetypes.FormatTypeJSON})
+	require.NoError(t, err)
+	records, err = c.encode(table, rec)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte(`{"id":1,"name":"a"}` + "\n"), []byte(`{"id":2,"name":"b"}` + "\n")}, records)
+}
+
+func TestEncodeConcatenated(t *testing.T) {
+	table, rec := testRecord(t)
+
+	for _, fileSpec := range []filetypes.FileSpec{
+		{Format: filetypes.FormatTypeCSV, FormatSpec: map[string]any{"skip_header": true}},
+		{Format: filetypes.FormatTypeCSV, FormatSpec: map[string]any{"skip_header": true}, Compression: filetypes.CompressionTypeGZip},
+		{Format: filetypes.FormatTypeJSON},
+		{Format: filetypes.FormatTypeJSON, Compression: filetypes.CompressionTypeGZip},
+	} {
+		fileSpec := fileSpec
+		t.Run(string(fileSpec.Format)+"_"+string(fileSpec.Compression), func(t *testing.T) {
+			cl, err := filetypes.NewClient(&fileSpec)
+			require.NoError(t, err)
+			c := &Client{Client: cl}
+			records, err := c.encode(table, rec)
+			require.NoError(t, err)
+
+			// Firehose concatenates the records into the delivered files
+			res := make(chan arrow.Record, rec.NumRows())
+			require.NoError(t, cl.Read(bytes.NewReader(bytes.Join(records, nil)), table, res))
+			close(res)
+			var rows int64
+			for r := range res {
+				require.Equal(t, []any{int64(1), int64(2)}[rows:rows+r.NumRows()], valuesOf(r.Column(0)))
+				rows += r.NumRows()
+				r.Release()
+			}
+			require.Equal(t, rec.NumRows(), rows)
+		})
+	}
+}
+
+func valuesOf(arr arrow.Array) []any {
+	values := make([]any, arr.Len())
+	for i := range values {
+		values[i] = arr.GetOneForMarshal(i)
+	}
+	return values
+}
+
+func TestOversizedRecord(t *testing.T) {
+	const streamARN = "arn:aws:firehose:us-east-1:01234:deliverystream/name"
+	c := &Client{logger: zerolog.Nop(), spec: spec.Spec{OversizedRecords: spec.OversizedRecordsSkip, MaxRecordSizeBytes: 1}}
+	require.NoError(t, c.oversizedRecord("test_table", streamARN, []byte("data")))
+
+	c.spec.OversizedRecords = spec.OversizedRecordsError
+	require.ErrorContains(t, c.oversizedRecord("test_table", streamARN, []byte("data")), "record of table test_table is too large: 4 bytes")
+
+	c.spec.OversizedRecords = spec.OversizedRecordsDeadLetter
+	f, err := os.Create(filepath.Join(t.TempDir(), "dead_letter.jsonl"))
+	require.NoError(t, err)
+	c.deadLetterFile = f
+	require.NoError(t, c.oversizedRecord("test_table", streamARN, []byte("data")))
+	require.NoError(t, c.oversizedRecord("other_table", streamARN, []byte("other")))
+	require.NoError(t, c.Close(context.Background()))
+
+	f, err = os.Open(f.Name())
+	require.NoError(t, err)
+	defer f.Close()
+	var got []deadLetterRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r deadLetterRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		got = append(got, r)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []deadLetterRecord{
+		{TableName: "test_table", StreamARN: streamARN, Data: []byte("data")},
+		{TableName: "other_table", StreamARN: streamARN, Data: []byte("other")},
+	}, got)
+}
@@ -28,13 +28,18 @@ type Client struct {
 	mu     sync.Mutex // protects client during session creation
 	client *gremlingo.DriverRemoteConnection
 	writer *batchwriter.BatchWriter
+
+	// child table names by parent table name
+	children     map[string][]string
+	childrenLock sync.RWMutex
 }
 
 var AnonT = gremlingo.T__
 
 func New(ctx context.Context, logger zerolog.Logger, spec []byte, _ plugin.NewClientOptions) (plugin.Client, error) {
 	c := &Client{
-		logger: logger.With().Str("module", "gremlin").Logger(),
+		logger:   logger.With().Str("module", "gremlin").Logger(),
+		children: make(map[string][]string),
 	}
 	if err := json.Unmarshal(spec, &c.spec); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal gremlin spec: %w", err)
@@ -16,6 +16,18 @@ func (c *Client) DeleteStale(_ context.Context, msgs message.WriteDeleteStales)
 	defer closer()
 
 	for _, msg := range msgs {
+		// edges that weren't created or updated during the sync, e.g. when the referenced vertex changed
+		edges := gremlingo.Traversal_().WithRemote(session).
+			V().
+			HasLabel(msg.GetTable().Name).
+			BothE().
+			Has(schema.CqSourceNameColumn.Name, msg.SourceName).
+			Has(schema.CqSyncTimeColumn.Name, gremlingo.P.Lt(msg.SyncTime)).
+			SideEffect(AnonT.Drop())
+		if err := <-edges.Iterate(); err != nil {
+			return err
+		}
+
 		g := gremlingo.Traversal_().WithRemote(session).
 			V().
 			HasLabel(msg.GetTable().Name).
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"sort"
+	"strings"
+
+	gremlingo "github.com/apache/tinkerpop/gremlin-go/v3/driver"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// edge connects the written vertex with the vertices having the `vertexLabel` label and the `key` property equal to `value`
+type edge struct {
+	label       string
+	vertexLabel string
+	key         string
+	value       any
+	// whether the edge goes out of the written vertex
+	out bool
+}
+
+// parentEdgeLabel returns the label of the edges from the vertices of the parent table to the vertices of the child table
+func parentEdgeLabel(child string) string {
+	return "HAS_" + strings.ToUpper(child)
+}
+
+// setChildren records the child tables of the tables being migrated or written
+func (c *Client) setChildren(tables schema.Tables) {
+	c.childrenLock.Lock()
+	defer c.childrenLock.Unlock()
+	for _, table := range tables {
+		if table.Parent == nil || table.Column(schema.CqParentIDColumn.Name) == nil {
+			continue
+		}
+		children := c.children[table.Parent.Name]
+		if idx := sort.SearchStrings(children, table.Name); idx < len(children) && children[idx] == table.Name {
+			continue
+		}
+		children = append(children, table.Name)
+		sort.Strings(children)
+		c.children[table.Parent.Name] = children
+	}
+}
+
+func (c *Client) getChildren(table string) []string {
+	c.childrenLock.RLock()
+	defer c.childrenLock.RUnlock()
+	return c.children[table]
+}
+
+// rowEdges returns the edges of the vertex written from the row
+func rowEdges(table *schema.Table, children []string, edges []Edge, row map[string]any) []edge {
+	var result []edge
+	add := func(e edge) {
+		// null values never match
+		if e.value != nil {
+			result = append(result, e)
+		}
+	}
+	if table.Parent != nil && table.Column(schema.CqParentIDColumn.Name) != nil {
+		add(edge{label: parentEdgeLabel(table.Name), vertexLabel: table.Parent.Name, key: schema.CqIDColumn.Name, value: row[schema.CqParentIDColumn.Name]})
+	}
+	for _, child := range children {
+		// child vertices may have been written before the parent ones
+		add(edge{label: parentEdgeLabel(child), vertexLabel: child, key: schema.CqParentIDColumn.Name, value: row[schema.CqIDColumn.Name], out: true})
+	}
+	for _, e := range edges {
+		fromTable, fromColumn := e.FromColumn()
+		toTable, toColumn := e.ToColumn()
+		if fromTable == table.Name && table.Column(fromColumn) != nil {
+			add(edge{label: e.Label, vertexLabel: toTable, key: toColumn, value: row[fromColumn], out: true})
+		}
+		if toTable == table.Name && table.Column(toColumn) != nil {
+			add(edge{label: e.Label, vertexLabel: fromTable, key: fromColumn, value: row[toColumn]})
+		}
+	}
+	return result
+}
+
+// upsertEdge adds a side effect creating the edge between the current vertex and the matching vertices, unless it exists already.
+// The `_cq_source_name` and `_cq_sync_time` properties of the edge are set from the row, to remove stale edges.
+func upsertEdge(g *gremlingo.GraphTraversal, e edge, row map[string]any) *gremlingo.GraphTraversal {
+	var existing, add *gremlingo.GraphTraversal
+	if e.out {
+		existing = AnonT.InE(e.label).Where(AnonT.OutV().As("v"))
+		add = AnonT.AddE(e.label).From("v")
+	} else {
+		existing = AnonT.OutE(e.label).Where(AnonT.InV().As("v"))
+		add = AnonT.AddE(e.label).To("v")
+	}
+	upsert := AnonT.As("v").V().HasLabel(e.vertexLabel).Has(e.key, e.value).
+		Coalesce(existing, add)
+	for _, colName := range []string{schema.CqSourceNameColumn.Name, schema.CqSyncTimeColumn.Name} {
+		if value, ok := row[colName]; ok && value != nil {
+			upsert = upsert.Property(colName, value)
+		}
+	}
+	return g.SideEffect(upsert)
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowEdges(t *testing.T) {
+	instances := &schema.Table{
+		Name: "aws_ec2_instances",
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			{Name: "arn", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+			{Name: "subnet_id", Type: arrow.BinaryTypes.String},
+		},
+	}
+	subnets := &schema.Table{
+		Name: "aws_ec2_subnets",
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			{Name: "subnet_id", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+		},
+	}
+	tags := &schema.Table{
+		Name:    "aws_ec2_instance_tags",
+		Parent:  instances,
+		Columns: schema.ColumnList{schema.CqIDColumn, schema.CqParentIDColumn},
+	}
+	edges := []Edge{{From: "aws_ec2_instances.subnet_id", To: "aws_ec2_subnets.subnet_id", Label: "IN_SUBNET"}}
+
+	c := &Client{children: make(map[string][]string)}
+	c.setChildren(schema.Tables{instances, subnets, tags, tags})
+	require.Equal(t, map[string][]string{"aws_ec2_instances": {"aws_ec2_instance_tags"}}, c.children)
+
+	require.Equal(t, []edge{
+		{label: "HAS_AWS_EC2_INSTANCE_TAGS", vertexLabel: "aws_ec2_instance_tags", key: "_cq_parent_id", value: "instance-id", out: true},
+		{label: "IN_SUBNET", vertexLabel: "aws_ec2_subnets", key: "subnet_id", value: "subnet-1", out: true},
+	}, rowEdges(instances, c.getChildren(instances.Name), edges, map[string]any{"_cq_id": "instance-id", "arn": "arn", "subnet_id": "subnet-1"}))
+
+	// null values never match
+	require.Equal(t, []edge{
+		{label: "HAS_AWS_EC2_INSTANCE_TAGS", vertexLabel: "aws_ec2_instance_tags", key: "_cq_parent_id", value: "instance-id", out: true},
+	}, rowEdges(instances, c.getChildren(instances.Name), edges, map[string]any{"_cq_id": "instance-id", "arn": "arn", "subnet_id": nil}))
+
+	require.Equal(t, []edge{
+		{label: "IN_SUBNET", vertexLabel: "aws_ec2_instances", key: "subnet_id", value: "subnet-1"},
+	}, rowEdges(subnets, c.getChildren(subnets.Name), edges, map[string]any{"_cq_id": "subnet-id", "subnet_id": "subnet-1"}))
+
+	require.Equal(t, []edge{
+		{label: "HAS_AWS_EC2_INSTANCE_TAGS", vertexLabel: "aws_ec2_instances", key: "_cq_id", value: "instance-id"},
+	}, rowEdges(tags, c.getChildren(tags.Name), edges, map[string]any{"_cq_id": "tag-id", "_cq_parent_id": "instance-id"}))
+
+	// the tables of the written records keep their parent, so the child tables are recorded without migrations too
+	written, err := schema.NewTableFromArrowSchema(tags.ToArrowSchema())
+	require.NoError(t, err)
+	c = &Client{children: make(map[string][]string)}
+	c.setChildren(schema.Tables{written})
+	require.Equal(t, []string{"aws_ec2_instance_tags"}, c.getChildren(instances.Name))
+}
@@ -4,9 +4,15 @@ import (
 	"context"
 
 	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
 )
 
-// Migrate tables. Like neo4j, gremlin does not have a schema, so this is a no-op.
-func (*Client) MigrateTables(context.Context, message.WriteMigrateTables) error {
+// Migrate tables. Like neo4j, gremlin does not have a schema, so only the parent/child table relations are recorded, to create the edges.
+func (c *Client) MigrateTables(_ context.Context, messages message.WriteMigrateTables) error {
+	tables := make(schema.Tables, len(messages))
+	for i, m := range messages {
+		tables[i] = m.Table
+	}
+	c.setChildren(tables)
 	return nil
 }
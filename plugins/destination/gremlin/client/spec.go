@@ -49,6 +49,48 @@ type Spec struct {
 
 	// Number of bytes (as Arrow buffer size) to batch together before sending to the database.
 	BatchSizeBytes int `json:"batch_size_bytes" jsonschema:"minimum=1,default=4194304"`
+
+	// Edges to create between vertices by matching property values, in addition to the `HAS_<CHILD_TABLE>` edges
+	// created between the vertices of parent and child tables.
+	Edges []Edge `json:"edges,omitempty"`
+}
+
+// Edge maps a pair of columns to an edge label.
+// An edge is created from each vertex of the `from` table to each vertex of the `to` table with the same property value.
+type Edge struct {
+	// Table and column of the outgoing vertices of the edge, in `table.column` format, for example `aws_ec2_instances.subnet_id`.
+	From string `json:"from" jsonschema:"required,pattern=^[^.]+\\.[^.]+$"`
+
+	// Table and column of the incoming vertices of the edge, in `table.column` format, for example `aws_ec2_subnets.subnet_id`.
+	To string `json:"to" jsonschema:"required,pattern=^[^.]+\\.[^.]+$"`
+
+	// Label of the edge, for example `IN_SUBNET`.
+	Label string `json:"label" jsonschema:"required,minLength=1"`
+}
+
+// FromColumn returns the table and column of the outgoing vertices
+func (e Edge) FromColumn() (table, column string) {
+	table, column, _ = strings.Cut(e.From, ".")
+	return table, column
+}
+
+// ToColumn returns the table and column of the incoming vertices
+func (e Edge) ToColumn() (table, column string) {
+	table, column, _ = strings.Cut(e.To, ".")
+	return table, column
+}
+
+func (e Edge) Validate() error {
+	for _, tableColumn := range []string{e.From, e.To} {
+		table, column, ok := strings.Cut(tableColumn, ".")
+		if !ok || table == "" || column == "" || strings.Contains(column, ".") {
+			return fmt.Errorf("invalid edge column %q, expected \"table.column\" format", tableColumn)
+		}
+	}
+	if e.Label == "" {
+		return fmt.Errorf("edge label is required")
+	}
+	return nil
 }
 
 type authMode string
@@ -111,6 +153,11 @@ func (s *Spec) Validate() error {
 	if s.AuthMode == authModeNone && (s.Username != "" || s.Password != "") {
 		return fmt.Errorf("username or password specified with auth_mode %q. Set auth mode to %q or remove username and password", authModeNone, authModeBasic)
 	}
+	for _, e := range s.Edges {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/cloudquery/codegen/jsonschema"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJSONSchema(t *testing.T) {
@@ -127,5 +128,40 @@ func TestJSONSchema(t *testing.T) {
 			Spec: `{"endpoint": "ws://localhost:8182", "unknown": "test"}`,
 			Err:  true,
 		},
+		{
+			Name: "spec with edges",
+			Spec: `{"endpoint": "ws://localhost:8182", "edges": [{"from": "aws_ec2_instances.subnet_id", "to": "aws_ec2_subnets.subnet_id", "label": "IN_SUBNET"}]}`,
+		},
+		{
+			Name: "spec with null edges",
+			Spec: `{"endpoint": "ws://localhost:8182", "edges": null}`,
+		},
+		{
+			Name: "spec with edge missing label",
+			Spec: `{"endpoint": "ws://localhost:8182", "edges": [{"from": "aws_ec2_instances.subnet_id", "to": "aws_ec2_subnets.subnet_id"}]}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with edge empty label",
+			Spec: `{"endpoint": "ws://localhost:8182", "edges": [{"from": "aws_ec2_instances.subnet_id", "to": "aws_ec2_subnets.subnet_id", "label": ""}]}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with edge from without column",
+			Spec: `{"endpoint": "ws://localhost:8182", "edges": [{"from": "aws_ec2_instances", "to": "aws_ec2_subnets.subnet_id", "label": "IN_SUBNET"}]}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with edge unknown field",
+			Spec: `{"endpoint": "ws://localhost:8182", "edges": [{"from": "aws_ec2_instances.subnet_id", "to": "aws_ec2_subnets.subnet_id", "label": "IN_SUBNET", "type": "x"}]}`,
+			Err:  true,
+		},
 	})
 }
+
+func TestEdgeValidate(t *testing.T) {
+	require.NoError(t, Edge{From: "a.b", To: "c.d", Label: "IN"}.Validate())
+	require.ErrorContains(t, Edge{From: "a", To: "c.d", Label: "IN"}.Validate(), `invalid edge column "a"`)
+	require.ErrorContains(t, Edge{From: "a.b", To: "c.d.e", Label: "IN"}.Validate(), `invalid edge column "c.d.e"`)
+	require.ErrorContains(t, Edge{From: "a.b", To: "c.d"}.Validate(), "edge label is required")
+}
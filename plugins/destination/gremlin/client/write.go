@@ -20,6 +20,8 @@ func (c *Client) WriteTableBatch(ctx context.Context, tableName string, msgs mes
 	if err != nil {
 		return err
 	}
+	// tables aren't migrated with `--no-migrate`, so child tables are also recorded when their vertices are written
+	c.setChildren(schema.Tables{table})
 
 	session, closer, err := c.newSession()
 	if err != nil {
@@ -55,6 +57,7 @@ func (c *Client) WriteTableBatch(ctx context.Context, tableName string, msgs mes
 		}
 	}
 
+	children := c.getChildren(tableName)
 	g := gremlingo.Traversal_().WithRemote(session).V().HasLabel(tableName)
 	for i := range rows {
 		g = g.V().HasLabel(tableName)
@@ -75,6 +78,10 @@ func (c *Client) WriteTableBatch(ctx context.Context, tableName string, msgs mes
 		for _, colName := range valueColumns {
 			g = g.Property(gremlingo.Cardinality.Single, colName, rows[i][colName])
 		}
+
+		for _, e := range rowEdges(table, children, c.spec.Edges, rows[i]) {
+			g = upsertEdge(g, e, rows[i])
+		}
 	}
 
 	bo := backoff.WithContext(
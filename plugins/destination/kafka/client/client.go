@@ -2,12 +2,12 @@ package client
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"time"
+	"sync"
 
 	"github.com/Shopify/sarama"
+	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/schemaregistry"
 	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/spec"
 	"github.com/cloudquery/filetypes/v4"
 	"github.com/cloudquery/plugin-sdk/v4/plugin"
@@ -23,6 +23,10 @@ type Client struct {
 	logger zerolog.Logger
 	spec   *spec.Spec
 
+	registry    *schemaregistry.Client
+	schemas     map[string]registeredSchema
+	schemasLock sync.RWMutex
+
 	*filetypes.Client
 }
 
@@ -46,26 +50,12 @@ func New(_ context.Context, logger zerolog.Logger, s []byte, opts plugin.NewClie
 		sarama.Logger = NewSaramaLoggerAdapter(logger)
 	}
 
-	c.conf = sarama.NewConfig()
-	c.conf.Metadata.Retry.Backoff = time.Millisecond * 500
-	c.conf.Producer.Retry.Max = 1
-	c.conf.Producer.RequiredAcks = sarama.WaitForAll
-	c.conf.Producer.Return.Successes = true
-	c.conf.Metadata.Full = true
-	c.conf.Version = sarama.V1_0_0_0
-	c.conf.Metadata.Full = true
-	c.conf.ClientID = c.spec.ClientID
-
-	if c.spec.SASLUsername != "" {
-		c.conf.Net.SASL.Enable = true
-		c.conf.Net.SASL.User = c.spec.SASLUsername
-		c.conf.Net.SASL.Password = c.spec.SASLPassword
-		c.conf.Net.TLS.Enable = true
-		c.conf.Net.TLS.Config = &tls.Config{InsecureSkipVerify: true}
-		c.conf.Net.SASL.Handshake = true
+	var err error
+	c.conf, err = newSaramaConfig(c.spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client config: %w", err)
 	}
 
-	var err error
 	c.producer, err = sarama.NewSyncProducer(c.spec.Brokers, c.conf)
 	if err != nil {
 		return nil, err
@@ -77,6 +67,11 @@ func New(_ context.Context, logger zerolog.Logger, s []byte, opts plugin.NewClie
 	}
 	c.Client = filetypesClient
 
+	if c.spec.SchemaRegistry != nil {
+		c.registry = schemaregistry.NewClient(c.spec.SchemaRegistry.URL, c.spec.SchemaRegistry.Username, c.spec.SchemaRegistry.Password)
+		c.schemas = make(map[string]registeredSchema)
+	}
+
 	return c, nil
 }
 
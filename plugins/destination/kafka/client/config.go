@@ -0,0 +1,117 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/spec"
+)
+
+func newSaramaConfig(s *spec.Spec) (*sarama.Config, error) {
+	conf := sarama.NewConfig()
+	conf.Metadata.Retry.Backoff = time.Millisecond * 500
+	conf.Producer.Retry.Max = 1
+	conf.Producer.Return.Successes = true
+	conf.Metadata.Full = true
+	conf.Version = sarama.V1_0_0_0
+	conf.ClientID = s.ClientID
+
+	switch s.RequiredAcks {
+	case spec.RequiredAcksNone:
+		conf.Producer.RequiredAcks = sarama.NoResponse
+	case spec.RequiredAcksLeader:
+		conf.Producer.RequiredAcks = sarama.WaitForLocal
+	default:
+		conf.Producer.RequiredAcks = sarama.WaitForAll
+	}
+
+	switch s.CompressionCodec {
+	case spec.CompressionCodecGzip:
+		conf.Producer.Compression = sarama.CompressionGZIP
+	case spec.CompressionCodecSnappy:
+		conf.Producer.Compression = sarama.CompressionSnappy
+	case spec.CompressionCodecLZ4:
+		conf.Producer.Compression = sarama.CompressionLZ4
+	case spec.CompressionCodecZSTD:
+		conf.Producer.Compression = sarama.CompressionZSTD
+		// zstd requires Kafka 2.1
+		conf.Version = sarama.V2_1_0_0
+	default:
+		conf.Producer.Compression = sarama.CompressionNone
+	}
+
+	if s.Idempotent {
+		conf.Producer.Idempotent = true
+		conf.Producer.Retry.Max = 5
+		conf.Net.MaxOpenRequests = 1
+		if !conf.Version.IsAtLeast(sarama.V0_11_0_0) {
+			conf.Version = sarama.V0_11_0_0
+		}
+	}
+
+	if s.SASLUsername != "" {
+		conf.Net.SASL.Enable = true
+		conf.Net.SASL.User = s.SASLUsername
+		conf.Net.SASL.Password = s.SASLPassword
+		conf.Net.SASL.Handshake = true
+		switch s.SASLMechanism {
+		case spec.SASLMechanismSCRAMSHA256:
+			conf.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			conf.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{HashGeneratorFcn: sha256HashGenerator}
+			}
+		case spec.SASLMechanismSCRAMSHA512:
+			conf.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+			conf.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{HashGeneratorFcn: sha512HashGenerator}
+			}
+		default:
+			conf.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		}
+	}
+
+	switch {
+	case s.TLS != nil && s.TLS.IsEnabled():
+		tlsConfig, err := newTLSConfig(s.TLS)
+		if err != nil {
+			return nil, err
+		}
+		conf.Net.TLS.Enable = true
+		conf.Net.TLS.Config = tlsConfig
+	case s.TLS == nil && s.SASLUsername != "":
+		// SASL is used with TLS by default, verified with the system certificate authorities
+		conf.Net.TLS.Enable = true
+		conf.Net.TLS.Config = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return conf, conf.Validate()
+}
+
+func newTLSConfig(t *spec.TLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CACert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, err
+		}
+		if ok := pool.AppendCertsFromPEM([]byte(t.CACert)); !ok {
+			return nil, fmt.Errorf("failed to append \"tls.ca_cert\" value")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if t.ClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(t.ClientCert), []byte(t.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load \"tls.client_cert\" and \"tls.client_key\": %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/spec"
+	"github.com/cloudquery/filetypes/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func testCertificate(t *testing.T) (certPEM string, keyPEM string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kafka-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func newTestSaramaConfig(t *testing.T, s *spec.Spec) (*sarama.Config, error) {
+	t.Helper()
+	s.Brokers = []string{"localhost:9092"}
+	s.FileSpec = filetypes.FileSpec{Format: filetypes.FormatTypeJSON}
+	s.SetDefaults()
+	require.NoError(t, s.Validate())
+	return newSaramaConfig(s)
+}
+
+func TestNewSaramaConfigDefaults(t *testing.T) {
+	conf, err := newTestSaramaConfig(t, &spec.Spec{})
+	require.NoError(t, err)
+	require.False(t, conf.Net.TLS.Enable)
+	require.False(t, conf.Net.SASL.Enable)
+	require.Equal(t, sarama.WaitForAll, conf.Producer.RequiredAcks)
+	require.Equal(t, sarama.CompressionNone, conf.Producer.Compression)
+	require.False(t, conf.Producer.Idempotent)
+}
+
+func TestNewSaramaConfigSASL(t *testing.T) {
+	conf, err := newTestSaramaConfig(t, &spec.Spec{SASLUsername: "user", SASLPassword: "pass"})
+	require.NoError(t, err)
+	require.True(t, conf.Net.SASL.Enable)
+	require.Equal(t, sarama.SASLMechanism(sarama.SASLTypePlaintext), conf.Net.SASL.Mechanism)
+	// TLS is enabled by default with SASL, and certificates are verified
+	require.True(t, conf.Net.TLS.Enable)
+	require.False(t, conf.Net.TLS.Config.InsecureSkipVerify)
+
+	disabled := false
+	conf, err = newTestSaramaConfig(t, &spec.Spec{
+		SASLUsername:  "user",
+		SASLPassword:  "pass",
+		SASLMechanism: spec.SASLMechanismSCRAMSHA512,
+		TLS:           &spec.TLS{Enabled: &disabled},
+	})
+	require.NoError(t, err)
+	require.False(t, conf.Net.TLS.Enable)
+	require.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA512), conf.Net.SASL.Mechanism)
+	scram := conf.Net.SASL.SCRAMClientGeneratorFunc()
+	require.NoError(t, scram.Begin("user", "pass", ""))
+	first, err := scram.Step("")
+	require.NoError(t, err)
+	require.Contains(t, first, "n=user")
+	require.False(t, scram.Done())
+
+	conf, err = newTestSaramaConfig(t, &spec.Spec{SASLUsername: "user", SASLPassword: "pass", SASLMechanism: spec.SASLMechanismSCRAMSHA256})
+	require.NoError(t, err)
+	require.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA256), conf.Net.SASL.Mechanism)
+	require.NotNil(t, conf.Net.SASL.SCRAMClientGeneratorFunc)
+}
+
+func TestNewSaramaConfigTLS(t *testing.T) {
+	certPEM, keyPEM := testCertificate(t)
+
+	conf, err := newTestSaramaConfig(t, &spec.Spec{TLS: &spec.TLS{
+		CACert:     certPEM,
+		ClientCert: certPEM,
+		ClientKey:  keyPEM,
+		ServerName: "kafka.internal",
+	}})
+	require.NoError(t, err)
+	require.True(t, conf.Net.TLS.Enable)
+	require.False(t, conf.Net.SASL.Enable)
+	require.Equal(t, "kafka.internal", conf.Net.TLS.Config.ServerName)
+	require.NotNil(t, conf.Net.TLS.Config.RootCAs)
+	require.Len(t, conf.Net.TLS.Config.Certificates, 1)
+	require.False(t, conf.Net.TLS.Config.InsecureSkipVerify)
+
+	_, err = newTestSaramaConfig(t, &spec.Spec{TLS: &spec.TLS{CACert: "not a certificate"}})
+	require.EqualError(t, err, `failed to append "tls.ca_cert" value`)
+
+	_, err = newTestSaramaConfig(t, &spec.Spec{TLS: &spec.TLS{ClientCert: certPEM, ClientKey: "not a key"}})
+	require.ErrorContains(t, err, `failed to load "tls.client_cert" and "tls.client_key"`)
+}
+
+func TestNewSaramaConfigProducer(t *testing.T) {
+	conf, err := newTestSaramaConfig(t, &spec.Spec{Idempotent: true, CompressionCodec: spec.CompressionCodecZSTD})
+	require.NoError(t, err)
+	require.True(t, conf.Producer.Idempotent)
+	require.Equal(t, 1, conf.Net.MaxOpenRequests)
+	require.Equal(t, sarama.CompressionZSTD, conf.Producer.Compression)
+	require.True(t, conf.Version.IsAtLeast(sarama.V2_1_0_0))
+
+	conf, err = newTestSaramaConfig(t, &spec.Spec{RequiredAcks: spec.RequiredAcksLeader, CompressionCodec: spec.CompressionCodecSnappy})
+	require.NoError(t, err)
+	require.Equal(t, sarama.WaitForLocal, conf.Producer.RequiredAcks)
+	require.Equal(t, sarama.CompressionSnappy, conf.Producer.Compression)
+
+	s := &spec.Spec{FileSpec: filetypes.FileSpec{Format: filetypes.FormatTypeJSON}, Brokers: []string{"localhost:9092"}, Idempotent: true, RequiredAcks: spec.RequiredAcksNone}
+	require.EqualError(t, s.Validate(), `idempotent requires required_acks to be "all"`)
+}
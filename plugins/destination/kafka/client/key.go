@@ -0,0 +1,98 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/spec"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// keyColumns returns the names of the columns used as the message key of the table
+func (c *Client) keyColumns(table *schema.Table) []string {
+	if c.spec.MessageKey == spec.MessageKeyPrimaryKey {
+		if pks := table.PrimaryKeys(); len(pks) > 0 {
+			return pks
+		}
+	}
+	return []string{schema.CqIDColumn.Name}
+}
+
+// messageKeys returns the key of each row of the record.
+// Single column keys are the plain column value, while composite keys are JSON objects of the key columns.
+func messageKeys(record arrow.Record, keyColumns []string) ([][]byte, error) {
+	columns := make([]arrow.Array, len(keyColumns))
+	for i, name := range keyColumns {
+		indices := record.Schema().FieldIndices(name)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("key column %s not found", name)
+		}
+		columns[i] = record.Column(indices[0])
+	}
+	return keysFromColumns(keyColumns, columns, int(record.NumRows()))
+}
+
+func keysFromColumns(keyColumns []string, columns []arrow.Array, rows int) ([][]byte, error) {
+	keys := make([][]byte, rows)
+	for row := range keys {
+		if len(columns) == 1 {
+			if columns[0].IsNull(row) {
+				return nil, fmt.Errorf("key column %s is null", keyColumns[0])
+			}
+			keys[row] = []byte(columns[0].ValueStr(row))
+			continue
+		}
+		values := make(map[string]any, len(columns))
+		for i, col := range columns {
+			values[keyColumns[i]] = col.GetOneForMarshal(row)
+		}
+		key, err := json.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal key: %w", err)
+		}
+		keys[row] = key
+	}
+	return keys, nil
+}
+
+// deleteRecordKeys returns the keys of the rows matched by the where clause of a DeleteRecord message.
+// Only where clauses made of predicate groups that set all the key columns can be resolved to keys,
+// false is returned for any other where clause.
+func deleteRecordKeys(where message.PredicateGroups, keyColumns []string) ([][]byte, bool, error) {
+	if len(where) == 0 {
+		return nil, false, nil
+	}
+	keys := make([][]byte, 0, len(where))
+	for _, group := range where {
+		if len(group.Predicates) > 1 && !strings.EqualFold(group.GroupingType, "AND") {
+			return nil, false, nil
+		}
+		values := make(map[string]arrow.Array, len(group.Predicates))
+		for _, predicate := range group.Predicates {
+			if !strings.EqualFold(predicate.Operator, "eq") || predicate.Record == nil || predicate.Record.NumCols() == 0 || predicate.Record.NumRows() != 1 {
+				return nil, false, nil
+			}
+			values[predicate.Column] = predicate.Record.Column(0)
+		}
+		if len(values) != len(keyColumns) {
+			return nil, false, nil
+		}
+		columns := make([]arrow.Array, len(keyColumns))
+		for i, name := range keyColumns {
+			col, ok := values[name]
+			if !ok {
+				return nil, false, nil
+			}
+			columns[i] = col
+		}
+		groupKeys, err := keysFromColumns(keyColumns, columns, 1)
+		if err != nil {
+			return nil, false, err
+		}
+		keys = append(keys, groupKeys...)
+	}
+	return keys, true, nil
+}
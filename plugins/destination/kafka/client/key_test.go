@@ -0,0 +1,135 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/spec"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyColumns(t *testing.T) {
+	table := &schema.Table{
+		Name: "test_table",
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			{Name: "account_id", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true},
+		},
+	}
+	noPKs := &schema.Table{Name: "no_pks", Columns: schema.ColumnList{schema.CqIDColumn}}
+
+	c := &Client{spec: &spec.Spec{MessageKey: spec.MessageKeyPrimaryKey}}
+	require.Equal(t, []string{"account_id", "id"}, c.keyColumns(table))
+	require.Equal(t, []string{"_cq_id"}, c.keyColumns(noPKs))
+
+	c = &Client{spec: &spec.Spec{MessageKey: spec.MessageKeyCQID}}
+	require.Equal(t, []string{"_cq_id"}, c.keyColumns(table))
+}
+
+func TestMessageKeys(t *testing.T) {
+	sc := arrow.NewSchema([]arrow.Field{
+		{Name: "account_id", Type: arrow.BinaryTypes.String},
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	}, nil)
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+	defer bldr.Release()
+	bldr.Field(0).(*array.StringBuilder).AppendValues([]string{"a", "b"}, nil)
+	bldr.Field(1).(*array.Int64Builder).AppendValues([]int64{1, 0}, []bool{true, false})
+	record := bldr.NewRecord()
+	defer record.Release()
+
+	keys, err := messageKeys(record, []string{"account_id"})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("a"), []byte("b")}, keys)
+
+	keys, err = messageKeys(record, []string{"account_id", "id"})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte(`{"account_id":"a","id":1}`), []byte(`{"account_id":"b","id":null}`)}, keys)
+
+	_, err = messageKeys(record, []string{"id"})
+	require.EqualError(t, err, "key column id is null")
+
+	_, err = messageKeys(record, []string{"missing"})
+	require.EqualError(t, err, "key column missing not found")
+}
+
+func TestDeleteRecordKeys(t *testing.T) {
+	value := func(v string) arrow.Record {
+		sc := arrow.NewSchema([]arrow.Field{{Name: "value", Type: arrow.BinaryTypes.String}}, nil)
+		bldr := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+		defer bldr.Release()
+		bldr.Field(0).(*array.StringBuilder).Append(v)
+		return bldr.NewRecord()
+	}
+	cases := []struct {
+		name       string
+		where      message.PredicateGroups
+		keyColumns []string
+		keys       [][]byte
+		ok         bool
+	}{
+		{
+			name: "single_key",
+			where: message.PredicateGroups{
+				{GroupingType: "AND", Predicates: message.Predicates{{Operator: "eq", Column: "id", Record: value("1")}}},
+				{GroupingType: "AND", Predicates: message.Predicates{{Operator: "eq", Column: "id", Record: value("2")}}},
+			},
+			keyColumns: []string{"id"},
+			keys:       [][]byte{[]byte("1"), []byte("2")},
+			ok:         true,
+		},
+		{
+			name: "composite_key",
+			where: message.PredicateGroups{
+				{GroupingType: "AND", Predicates: message.Predicates{
+					{Operator: "EQ", Column: "id", Record: value("1")},
+					{Operator: "EQ", Column: "account_id", Record: value("a")},
+				}},
+			},
+			keyColumns: []string{"account_id", "id"},
+			keys:       [][]byte{[]byte(`{"account_id":"a","id":"1"}`)},
+			ok:         true,
+		},
+		{
+			name: "not_key_column",
+			where: message.PredicateGroups{
+				{GroupingType: "AND", Predicates: message.Predicates{{Operator: "eq", Column: "name", Record: value("1")}}},
+			},
+			keyColumns: []string{"id"},
+		},
+		{
+			name: "partial_key",
+			where: message.PredicateGroups{
+				{GroupingType: "AND", Predicates: message.Predicates{{Operator: "eq", Column: "id", Record: value("1")}}},
+			},
+			keyColumns: []string{"account_id", "id"},
+		},
+		{
+			name: "or_grouping",
+			where: message.PredicateGroups{
+				{GroupingType: "OR", Predicates: message.Predicates{
+					{Operator: "eq", Column: "id", Record: value("1")},
+					{Operator: "eq", Column: "account_id", Record: value("a")},
+				}},
+			},
+			keyColumns: []string{"account_id", "id"},
+		},
+		{
+			name:       "no_where_clause",
+			keyColumns: []string{"id"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, ok, err := deleteRecordKeys(tc.where, tc.keyColumns)
+			require.NoError(t, err)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.keys, keys)
+		})
+	}
+}
@@ -20,7 +20,7 @@ func (c *Client) Read(ctx context.Context, table *schema.Table, res chan<- arrow
 		return err
 	}
 	defer consumer.Close()
-	partitionConsumer, err := consumer.ConsumePartition(table.Name, 0, sarama.OffsetOldest)
+	partitionConsumer, err := consumer.ConsumePartition(c.spec.TopicName(table.Name), 0, sarama.OffsetOldest)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/schemaregistry"
+	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/spec"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+type registeredSchema struct {
+	id         int
+	serializer schemaregistry.Serializer
+}
+
+func registrySchemaType(t spec.SchemaType) schemaregistry.SchemaType {
+	if t == spec.SchemaTypeJSON {
+		return schemaregistry.SchemaTypeJSON
+	}
+	return schemaregistry.SchemaTypeAvro
+}
+
+// subject returns the schema registry subject of the table values, following the default topic name strategy
+func (c *Client) subject(table *schema.Table) string {
+	return c.spec.TopicName(table.Name) + "-value"
+}
+
+// registerSchema registers the schema of the table, caching its ID.
+// If the table changed since the last registration, the registry creates a new version of the subject.
+func (c *Client) registerSchema(ctx context.Context, table *schema.Table) (registeredSchema, error) {
+	serializer, err := schemaregistry.NewSerializer(registrySchemaType(c.spec.SchemaRegistry.SchemaType), table)
+	if err != nil {
+		return registeredSchema{}, fmt.Errorf("failed to create schema for table %s: %w", table.Name, err)
+	}
+	id, err := c.registry.Register(ctx, c.subject(table), serializer.Schema(), serializer.SchemaType())
+	if err != nil {
+		return registeredSchema{}, err
+	}
+	registered := registeredSchema{id: id, serializer: serializer}
+	c.schemasLock.Lock()
+	defer c.schemasLock.Unlock()
+	c.schemas[table.Name] = registered
+	return registered, nil
+}
+
+// registryValues returns the value of each row of the record in the Confluent wire format.
+// The schema of a table that wasn't migrated (such as with `--no-migrate`) is registered on its first insert.
+func (c *Client) registryValues(ctx context.Context, table *schema.Table, record arrow.Record) ([][]byte, error) {
+	c.schemasLock.RLock()
+	registered, ok := c.schemas[table.Name]
+	c.schemasLock.RUnlock()
+	if !ok {
+		var err error
+		if registered, err = c.registerSchema(ctx, table); err != nil {
+			return nil, err
+		}
+	}
+	values, err := registered.serializer.Serialize(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize record for table %s: %w", table.Name, err)
+	}
+	for i := range values {
+		values[i] = schemaregistry.WireFormat(registered.id, values[i])
+	}
+	return values, nil
+}
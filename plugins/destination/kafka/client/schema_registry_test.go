@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/schemaregistry"
+	"github.com/cloudquery/cloudquery/plugins/destination/kafka/client/spec"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterSchema(t *testing.T) {
+	// the mock registry assigns a new ID to each distinct schema per subject
+	subjects := make(map[string][]string)
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req struct {
+			Schema string `json:"schema"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		versions := subjects[r.URL.Path]
+		if len(versions) == 0 || versions[len(versions)-1] != req.Schema {
+			subjects[r.URL.Path] = append(versions, req.Schema)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": len(subjects[r.URL.Path])})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	s := &spec.Spec{Topic: "cq_{{TABLE}}", SchemaRegistry: &spec.SchemaRegistry{URL: srv.URL, SchemaType: spec.SchemaTypeAvro}}
+	c := &Client{
+		spec:     s,
+		registry: schemaregistry.NewClient(srv.URL, "", ""),
+		schemas:  make(map[string]registeredSchema),
+	}
+
+	table := &schema.Table{
+		Name:    "test_table",
+		Columns: schema.ColumnList{{Name: "id", Type: arrow.PrimitiveTypes.Int64}},
+	}
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2}, nil)
+	record := bldr.NewRecord()
+	defer record.Release()
+
+	// the schema of a table that wasn't migrated is registered on the first insert, and its ID is cached
+	for i := 0; i < 2; i++ {
+		values, err := c.registryValues(ctx, table, record)
+		require.NoError(t, err)
+		require.Len(t, values, 2)
+		for _, value := range values {
+			require.Equal(t, byte(0), value[0])
+			require.Equal(t, uint32(1), binary.BigEndian.Uint32(value[1:5]))
+		}
+	}
+	require.Equal(t, 1, requests)
+	require.Len(t, subjects["/subjects/cq_test_table-value/versions"], 1)
+
+	// migrating the same table again keeps the version of the subject
+	_, err := c.registerSchema(ctx, table)
+	require.NoError(t, err)
+	require.Len(t, subjects["/subjects/cq_test_table-value/versions"], 1)
+
+	// adding a column registers a new version of the subject
+	evolved := table.Copy(nil)
+	evolved.Columns = append(evolved.Columns, schema.Column{Name: "name", Type: arrow.BinaryTypes.String})
+	_, err = c.registerSchema(ctx, evolved)
+	require.NoError(t, err)
+	require.Len(t, subjects["/subjects/cq_test_table-value/versions"], 2)
+
+	bldr = array.NewRecordBuilder(memory.DefaultAllocator, evolved.ToArrowSchema())
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).Append(3)
+	bldr.Field(1).(*array.StringBuilder).Append("three")
+	record = bldr.NewRecord()
+	defer record.Release()
+
+	values, err := c.registryValues(ctx, evolved, record)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), binary.BigEndian.Uint32(values[0][1:5]))
+}
@@ -0,0 +1,208 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+	"github.com/hamba/avro/v2"
+)
+
+// avroValue returns the value of a non-null element in the form expected by github.com/hamba/avro/v2
+type avroValue func(arr arrow.Array, i int) any
+
+type avroType struct {
+	schema any
+	value  avroValue
+}
+
+type avroField struct {
+	name   string
+	column string
+	typ    avroType
+}
+
+type avroSerializer struct {
+	schema     string
+	avroSchema avro.Schema
+	fields     []avroField
+}
+
+func newAvroSerializer(table *schema.Table) (*avroSerializer, error) {
+	s := &avroSerializer{fields: make([]avroField, len(table.Columns))}
+	fieldSchemas := make([]map[string]any, len(table.Columns))
+	for i, col := range table.Columns {
+		f := avroField{name: avroName(col.Name), column: col.Name, typ: avroTypeFor(col.Type)}
+		s.fields[i] = f
+		// all fields are nullable with a null default, so that adding columns keeps the schema backward compatible
+		fieldSchemas[i] = map[string]any{
+			"name":    f.name,
+			"type":    []any{"null", f.typ.schema},
+			"default": nil,
+		}
+	}
+	b, err := json.Marshal(map[string]any{
+		"type":   "record",
+		"name":   avroName(table.Name),
+		"fields": fieldSchemas,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.schema = string(b)
+	s.avroSchema, err = avro.Parse(s.schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Avro schema for table %s: %w", table.Name, err)
+	}
+	return s, nil
+}
+
+func (s *avroSerializer) Schema() string {
+	return s.schema
+}
+
+func (*avroSerializer) SchemaType() SchemaType {
+	return SchemaTypeAvro
+}
+
+func (s *avroSerializer) Serialize(record arrow.Record) ([][]byte, error) {
+	columns := make([]arrow.Array, len(s.fields))
+	for i, f := range s.fields {
+		indices := record.Schema().FieldIndices(f.column)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("column %s not found", f.column)
+		}
+		columns[i] = record.Column(indices[0])
+	}
+	values := make([][]byte, record.NumRows())
+	row := make(map[string]any, len(s.fields))
+	for i := range values {
+		for j, f := range s.fields {
+			row[f.name] = avroNullable(f.typ.value, columns[j], i)
+		}
+		value, err := avro.Marshal(s.avroSchema, row)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// avroNullable returns the value of a ["null", T] union.
+// Arrays are wrapped with their type name, as the union type of []any can't be resolved otherwise.
+func avroNullable(value avroValue, arr arrow.Array, i int) any {
+	if arr.IsNull(i) {
+		return nil
+	}
+	v := value(arr, i)
+	if v, ok := v.([]any); ok {
+		return map[string]any{"array": v}
+	}
+	return v
+}
+
+func avroTypeFor(dt arrow.DataType) avroType {
+	switch dt := dt.(type) {
+	case *arrow.BooleanType:
+		return avroType{schema: "boolean", value: func(arr arrow.Array, i int) any { return arr.(*array.Boolean).Value(i) }}
+	case *arrow.Int8Type:
+		return avroType{schema: "int", value: func(arr arrow.Array, i int) any { return int32(arr.(*array.Int8).Value(i)) }}
+	case *arrow.Int16Type:
+		return avroType{schema: "int", value: func(arr arrow.Array, i int) any { return int32(arr.(*array.Int16).Value(i)) }}
+	case *arrow.Int32Type:
+		return avroType{schema: "int", value: func(arr arrow.Array, i int) any { return arr.(*array.Int32).Value(i) }}
+	case *arrow.Uint8Type:
+		return avroType{schema: "int", value: func(arr arrow.Array, i int) any { return int32(arr.(*array.Uint8).Value(i)) }}
+	case *arrow.Uint16Type:
+		return avroType{schema: "int", value: func(arr arrow.Array, i int) any { return int32(arr.(*array.Uint16).Value(i)) }}
+	case *arrow.Int64Type:
+		return avroType{schema: "long", value: func(arr arrow.Array, i int) any { return arr.(*array.Int64).Value(i) }}
+	case *arrow.Uint32Type:
+		return avroType{schema: "long", value: func(arr arrow.Array, i int) any { return int64(arr.(*array.Uint32).Value(i)) }}
+	case *arrow.Uint64Type:
+		// Avro has no unsigned types, values above math.MaxInt64 wrap around
+		return avroType{schema: "long", value: func(arr arrow.Array, i int) any { return int64(arr.(*array.Uint64).Value(i)) }}
+	case *arrow.Float16Type:
+		return avroType{schema: "float", value: func(arr arrow.Array, i int) any { return arr.(*array.Float16).Value(i).Float32() }}
+	case *arrow.Float32Type:
+		return avroType{schema: "float", value: func(arr arrow.Array, i int) any { return arr.(*array.Float32).Value(i) }}
+	case *arrow.Float64Type:
+		return avroType{schema: "double", value: func(arr arrow.Array, i int) any { return arr.(*array.Float64).Value(i) }}
+	case *arrow.StringType, *arrow.LargeStringType:
+		return avroString(nil)
+	case *arrow.BinaryType, *arrow.LargeBinaryType, *arrow.FixedSizeBinaryType:
+		return avroType{schema: "bytes", value: func(arr arrow.Array, i int) any {
+			return arr.(interface{ Value(int) []byte }).Value(i)
+		}}
+	case *arrow.TimestampType:
+		return avroType{
+			schema: map[string]any{"type": "long", "logicalType": "timestamp-micros"},
+			value:  func(arr arrow.Array, i int) any { return arr.(*array.Timestamp).Value(i).ToTime(dt.Unit) },
+		}
+	case *arrow.Date32Type:
+		return avroType{
+			schema: map[string]any{"type": "int", "logicalType": "date"},
+			value:  func(arr arrow.Array, i int) any { return arr.(*array.Date32).Value(i).ToTime() },
+		}
+	case *arrow.Date64Type:
+		return avroType{
+			schema: map[string]any{"type": "int", "logicalType": "date"},
+			value:  func(arr arrow.Array, i int) any { return arr.(*array.Date64).Value(i).ToTime() },
+		}
+	case *types.UUIDType:
+		return avroString(map[string]any{"type": "string", "logicalType": "uuid"})
+	case *arrow.MapType:
+		// maps are list-like types in Arrow, but are sent as their string representation
+		return avroString(nil)
+	case arrow.ListLikeType:
+		elem := avroTypeFor(dt.Elem())
+		return avroType{
+			schema: map[string]any{"type": "array", "items": []any{"null", elem.schema}},
+			value: func(arr arrow.Array, i int) any {
+				list := arr.(array.ListLike)
+				start, end := list.ValueOffsets(i)
+				values := list.ListValues()
+				items := make([]any, 0, end-start)
+				for j := start; j < end; j++ {
+					items = append(items, avroNullable(elem.value, values, int(j)))
+				}
+				return items
+			},
+		}
+	default:
+		// structs, maps, JSON and any other type are sent as their string representation
+		return avroString(nil)
+	}
+}
+
+func avroString(sc any) avroType {
+	if sc == nil {
+		sc = "string"
+	}
+	return avroType{schema: sc, value: func(arr arrow.Array, i int) any {
+		switch arr := arr.(type) {
+		case *array.String:
+			return arr.Value(i)
+		case *array.LargeString:
+			return arr.Value(i)
+		default:
+			return arr.ValueStr(i)
+		}
+	}}
+}
+
+// avroName replaces the characters that aren't allowed in Avro names with underscores
+func avroName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		b[i] = '_'
+	}
+	return string(b)
+}
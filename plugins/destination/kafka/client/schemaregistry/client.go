@@ -0,0 +1,99 @@
+// Package schemaregistry registers table schemas with a Confluent Schema Registry and encodes rows in the Confluent wire format.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	contentType = "application/vnd.schemaregistry.v1+json"
+	// requestTimeout limits the time a registry request can take, so that an unresponsive registry doesn't hang the sync
+	requestTimeout = 30 * time.Second
+)
+
+type Client struct {
+	url      string
+	username string
+	password string
+
+	httpClient *http.Client
+}
+
+func NewClient(registryURL, username, password string) *Client {
+	return &Client{
+		url:        strings.TrimSuffix(registryURL, "/"),
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type registerRequest struct {
+	Schema     string     `json:"schema"`
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+}
+
+type registerResponse struct {
+	ID int `json:"id"`
+}
+
+type errorResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// Register registers the schema under the subject and returns its ID.
+// The registry returns the ID of the existing version if the schema is already registered, and creates a new version otherwise,
+// as long as it passes the compatibility checks configured for the subject.
+func (c *Client) Register(ctx context.Context, subject string, schema string, schemaType SchemaType) (int, error) {
+	req := registerRequest{Schema: schema}
+	if schemaType != SchemaTypeAvro {
+		// AVRO is the default and isn't understood by older registries
+		req.SchemaType = schemaType
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return 0, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/subjects/"+url.PathEscape(subject)+"/versions", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("Accept", contentType)
+	if c.username != "" {
+		httpReq.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register schema for subject %s: %w", subject, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema registry response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Message != "" {
+			return 0, fmt.Errorf("failed to register schema for subject %s: %s (error code %d)", subject, errResp.Message, errResp.ErrorCode)
+		}
+		return 0, fmt.Errorf("failed to register schema for subject %s: unexpected status %s", subject, resp.Status)
+	}
+
+	var registered registerResponse
+	if err := json.Unmarshal(respBody, &registered); err != nil {
+		return 0, fmt.Errorf("failed to decode schema registry response: %w", err)
+	}
+	return registered.ID, nil
+}
@@ -0,0 +1,107 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mockRegistry implements the subset of the Confluent Schema Registry API used by the client
+type mockRegistry struct {
+	mu       sync.Mutex
+	ids      map[string]int // schema -> ID
+	subjects map[string][]registerRequest
+	reject   string
+}
+
+func newMockRegistry(t *testing.T) (*mockRegistry, *httptest.Server) {
+	m := &mockRegistry{ids: make(map[string]int), subjects: make(map[string][]registerRequest)}
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+	return m, srv
+}
+
+func (m *mockRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	subject, ok := strings.CutPrefix(r.URL.Path, "/subjects/")
+	subject, found := strings.CutSuffix(subject, "/versions")
+	if !ok || !found || r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if subject == m.reject {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(errorResponse{ErrorCode: 409, Message: "Schema being registered is incompatible with an earlier schema"})
+		return
+	}
+	id, ok := m.ids[req.Schema]
+	if !ok {
+		id = len(m.ids) + 1
+		m.ids[req.Schema] = id
+	}
+	versions := m.subjects[subject]
+	if len(versions) == 0 || versions[len(versions)-1].Schema != req.Schema {
+		m.subjects[subject] = append(versions, req)
+	}
+	_ = json.NewEncoder(w).Encode(registerResponse{ID: id})
+}
+
+func TestClientRegister(t *testing.T) {
+	ctx := context.Background()
+	registry, srv := newMockRegistry(t)
+	c := NewClient(srv.URL+"/", "", "")
+
+	id, err := c.Register(ctx, "table-value", `{"type":"string"}`, SchemaTypeAvro)
+	require.NoError(t, err)
+	require.Equal(t, 1, id)
+
+	// registering the same schema again returns the same ID
+	id, err = c.Register(ctx, "table-value", `{"type":"string"}`, SchemaTypeAvro)
+	require.NoError(t, err)
+	require.Equal(t, 1, id)
+
+	id, err = c.Register(ctx, "table-value", `{"type":"object"}`, SchemaTypeJSON)
+	require.NoError(t, err)
+	require.Equal(t, 2, id)
+
+	require.Equal(t, []registerRequest{
+		{Schema: `{"type":"string"}`},
+		{Schema: `{"type":"object"}`, SchemaType: SchemaTypeJSON},
+	}, registry.subjects["table-value"])
+
+	registry.reject = "other-value"
+	_, err = c.Register(ctx, "other-value", `{"type":"string"}`, SchemaTypeAvro)
+	require.EqualError(t, err, "failed to register schema for subject other-value: Schema being registered is incompatible with an earlier schema (error code 409)")
+}
+
+func TestClientRegisterBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(registerResponse{ID: 7})
+	}))
+	defer srv.Close()
+
+	id, err := NewClient(srv.URL, "user", "pass").Register(context.Background(), "table-value", `"string"`, SchemaTypeAvro)
+	require.NoError(t, err)
+	require.Equal(t, 7, id)
+
+	_, err = NewClient(srv.URL, "", "").Register(context.Background(), "table-value", `"string"`, SchemaTypeAvro)
+	require.EqualError(t, err, "failed to register schema for subject table-value: unexpected status 401 Unauthorized")
+}
@@ -0,0 +1,91 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+type jsonSerializer struct {
+	schema  string
+	columns []string
+}
+
+func newJSONSerializer(table *schema.Table) (*jsonSerializer, error) {
+	s := &jsonSerializer{columns: table.Columns.Names()}
+	properties := make(map[string]any, len(table.Columns))
+	for _, col := range table.Columns {
+		properties[col.Name] = jsonSchemaFor(col.Type)
+	}
+	b, err := json.Marshal(map[string]any{
+		"$schema":    "http://json-schema.org/draft-07/schema#",
+		"title":      table.Name,
+		"type":       "object",
+		"properties": properties,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.schema = string(b)
+	return s, nil
+}
+
+func (s *jsonSerializer) Schema() string {
+	return s.schema
+}
+
+func (*jsonSerializer) SchemaType() SchemaType {
+	return SchemaTypeJSON
+}
+
+func (s *jsonSerializer) Serialize(record arrow.Record) ([][]byte, error) {
+	columns := make([]arrow.Array, len(s.columns))
+	for i, name := range s.columns {
+		indices := record.Schema().FieldIndices(name)
+		if len(indices) == 0 {
+			return nil, fmt.Errorf("column %s not found", name)
+		}
+		columns[i] = record.Column(indices[0])
+	}
+	values := make([][]byte, record.NumRows())
+	for row := range values {
+		obj := make(map[string]any, len(columns))
+		for i, col := range columns {
+			obj[s.columns[i]] = col.GetOneForMarshal(row)
+		}
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal row: %w", err)
+		}
+		values[row] = b
+	}
+	return values, nil
+}
+
+// jsonSchemaFor returns the JSON schema of values of the given type, as they are marshaled by Arrow.
+// All values are nullable.
+func jsonSchemaFor(dt arrow.DataType) map[string]any {
+	switch dt := dt.(type) {
+	case *arrow.BooleanType:
+		return map[string]any{"type": []string{"boolean", "null"}}
+	case *arrow.Int8Type, *arrow.Int16Type, *arrow.Int32Type, *arrow.Int64Type,
+		*arrow.Uint8Type, *arrow.Uint16Type, *arrow.Uint32Type, *arrow.Uint64Type:
+		return map[string]any{"type": []string{"integer", "null"}}
+	case *arrow.Float16Type, *arrow.Float32Type, *arrow.Float64Type:
+		return map[string]any{"type": []string{"number", "null"}}
+	case *arrow.StringType, *arrow.LargeStringType, *arrow.BinaryType, *arrow.LargeBinaryType, *arrow.FixedSizeBinaryType,
+		*arrow.TimestampType, *arrow.Date32Type, *arrow.Date64Type:
+		return map[string]any{"type": []string{"string", "null"}}
+	case *arrow.MapType:
+		return map[string]any{}
+	case arrow.ListLikeType:
+		return map[string]any{"type": []string{"array", "null"}, "items": jsonSchemaFor(dt.Elem())}
+	case *arrow.StructType:
+		return map[string]any{"type": []string{"object", "null"}}
+	default:
+		// JSON, UUID and other extension types have no constraints
+		return map[string]any{}
+	}
+}
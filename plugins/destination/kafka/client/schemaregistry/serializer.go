@@ -0,0 +1,46 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+const magicByte = 0
+
+type SchemaType string
+
+const (
+	SchemaTypeAvro SchemaType = "AVRO"
+	SchemaTypeJSON SchemaType = "JSON"
+)
+
+// Serializer encodes the rows of a table according to the schema it derived from the table
+type Serializer interface {
+	// Schema returns the schema to register
+	Schema() string
+	SchemaType() SchemaType
+	// Serialize returns the encoded value of each row of the record, without the wire format header
+	Serialize(record arrow.Record) ([][]byte, error)
+}
+
+func NewSerializer(schemaType SchemaType, table *schema.Table) (Serializer, error) {
+	switch schemaType {
+	case SchemaTypeAvro:
+		return newAvroSerializer(table)
+	case SchemaTypeJSON:
+		return newJSONSerializer(table)
+	default:
+		return nil, fmt.Errorf("unsupported schema type %s", schemaType)
+	}
+}
+
+// WireFormat prepends the Confluent wire format header, a magic byte followed by the big-endian schema ID, to the value
+func WireFormat(schemaID int, value []byte) []byte {
+	b := make([]byte, 5, 5+len(value))
+	b[0] = magicByte
+	binary.BigEndian.PutUint32(b[1:], uint32(schemaID))
+	return append(b, value...)
+}
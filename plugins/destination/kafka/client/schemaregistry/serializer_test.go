@@ -0,0 +1,146 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+	"github.com/google/uuid"
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var testTable = &schema.Table{
+	Name: "test_table",
+	Columns: schema.ColumnList{
+		{Name: "id", Type: types.ExtensionTypes.UUID},
+		{Name: "name", Type: arrow.BinaryTypes.String},
+		{Name: "count", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "small", Type: arrow.PrimitiveTypes.Int16},
+		{Name: "ratio", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "enabled", Type: arrow.FixedWidthTypes.Boolean},
+		{Name: "created_at", Type: arrow.FixedWidthTypes.Timestamp_us},
+		{Name: "data", Type: arrow.BinaryTypes.Binary},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+		{Name: "extra", Type: types.ExtensionTypes.JSON},
+	},
+}
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+func testRecord(t *testing.T) arrow.Record {
+	t.Helper()
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, testTable.ToArrowSchema())
+	t.Cleanup(bldr.Release)
+	bldr.Field(0).(*types.UUIDBuilder).AppendValues([]uuid.UUID{uuid.MustParse("e9e5f1a6-3b8e-4f0c-9c39-0f3b1d8b4c3d"), {}}, []bool{true, false})
+	bldr.Field(1).(*array.StringBuilder).AppendValues([]string{"first", ""}, []bool{true, false})
+	bldr.Field(2).(*array.Int64Builder).AppendValues([]int64{-42, 0}, []bool{true, false})
+	bldr.Field(3).(*array.Int16Builder).AppendValues([]int16{7, 0}, []bool{true, false})
+	bldr.Field(4).(*array.Float64Builder).AppendValues([]float64{0.5, 0}, []bool{true, false})
+	bldr.Field(5).(*array.BooleanBuilder).AppendValues([]bool{true, false}, []bool{true, false})
+	bldr.Field(6).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(testTime.UnixMicro()), 0}, []bool{true, false})
+	bldr.Field(7).(*array.BinaryBuilder).AppendValues([][]byte{[]byte("abc"), nil}, []bool{true, false})
+	tags := bldr.Field(8).(*array.ListBuilder)
+	tags.Append(true)
+	tags.ValueBuilder().(*array.StringBuilder).AppendValues([]string{"a", ""}, []bool{true, false})
+	tags.AppendNull()
+	bldr.Field(9).(*types.JSONBuilder).AppendBytes([]byte(`{"key":"value"}`))
+	bldr.Field(9).(*types.JSONBuilder).AppendNull()
+	record := bldr.NewRecord()
+	t.Cleanup(record.Release)
+	return record
+}
+
+func TestAvroSerializer(t *testing.T) {
+	s, err := NewSerializer(SchemaTypeAvro, testTable)
+	require.NoError(t, err)
+	require.Equal(t, SchemaTypeAvro, s.SchemaType())
+
+	avroSchema, err := avro.Parse(s.Schema())
+	require.NoError(t, err)
+
+	values, err := s.Serialize(testRecord(t))
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+
+	var first map[string]any
+	require.NoError(t, avro.Unmarshal(avroSchema, values[0], &first))
+	for k, v := range first {
+		first[k] = unwrapAvroUnion(v)
+	}
+	require.Equal(t, "e9e5f1a6-3b8e-4f0c-9c39-0f3b1d8b4c3d", first["id"])
+	require.Equal(t, "first", first["name"])
+	require.Equal(t, int64(-42), first["count"])
+	require.Equal(t, 7, first["small"])
+	require.Equal(t, 0.5, first["ratio"])
+	require.Equal(t, true, first["enabled"])
+	require.Equal(t, testTime, first["created_at"].(time.Time).UTC())
+	require.Equal(t, []byte("abc"), first["data"])
+	require.Equal(t, []any{"a", nil}, first["tags"])
+	require.Equal(t, `{"key":"value"}`, first["extra"])
+
+	var second map[string]any
+	require.NoError(t, avro.Unmarshal(avroSchema, values[1], &second))
+	for _, col := range testTable.Columns {
+		require.Nil(t, second[col.Name], col.Name)
+	}
+}
+
+// unwrapAvroUnion replaces the `{"type": value}` maps unions are decoded into with the values
+func unwrapAvroUnion(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, value := range v {
+			return unwrapAvroUnion(value)
+		}
+	case []any:
+		for i := range v {
+			v[i] = unwrapAvroUnion(v[i])
+		}
+	}
+	return v
+}
+
+func TestJSONSerializer(t *testing.T) {
+	s, err := NewSerializer(SchemaTypeJSON, testTable)
+	require.NoError(t, err)
+	require.Equal(t, SchemaTypeJSON, s.SchemaType())
+
+	var jsonSchema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(s.Schema()), &jsonSchema))
+	require.Equal(t, "test_table", jsonSchema["title"])
+	properties := jsonSchema["properties"].(map[string]any)
+	require.Len(t, properties, len(testTable.Columns))
+	require.Equal(t, map[string]any{"type": []any{"integer", "null"}}, properties["count"])
+	require.Equal(t, map[string]any{"type": []any{"array", "null"}, "items": map[string]any{"type": []any{"string", "null"}}}, properties["tags"])
+
+	values, err := s.Serialize(testRecord(t))
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+
+	var first map[string]any
+	require.NoError(t, json.Unmarshal(values[0], &first))
+	require.Equal(t, "first", first["name"])
+	require.Equal(t, float64(-42), first["count"])
+	require.Equal(t, []any{"a", nil}, first["tags"])
+	require.Equal(t, map[string]any{"key": "value"}, first["extra"])
+}
+
+func TestSerializerMissingColumn(t *testing.T) {
+	table := &schema.Table{Name: "test_table", Columns: schema.ColumnList{{Name: "missing", Type: arrow.BinaryTypes.String}}}
+	for _, schemaType := range []SchemaType{SchemaTypeAvro, SchemaTypeJSON} {
+		s, err := NewSerializer(schemaType, table)
+		require.NoError(t, err)
+		_, err = s.Serialize(testRecord(t))
+		require.EqualError(t, err, "column missing not found")
+	}
+}
+
+func TestWireFormat(t *testing.T) {
+	require.Equal(t, []byte{0, 0, 0, 1, 2, 'a', 'b'}, WireFormat(258, []byte("ab")))
+}
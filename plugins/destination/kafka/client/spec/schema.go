@@ -39,6 +39,25 @@ func (s Spec) JSONSchemaExtend(sc *jsonschema.Schema) {
 			If:    passwordPresent,
 			Then:  usernamePresent,
 		},
+		&jsonschema.Schema{
+			Title: "Require `message_per_row` when `schema_registry` is set",
+			If: &jsonschema.Schema{
+				Properties: func() *orderedmap.OrderedMap[string, *jsonschema.Schema] {
+					properties := orderedmap.New[string, *jsonschema.Schema]()
+					properties.Set("schema_registry", &jsonschema.Schema{Type: "object"})
+					return properties
+				}(),
+				Required: []string{"schema_registry"},
+			},
+			Then: &jsonschema.Schema{
+				Properties: func() *orderedmap.OrderedMap[string, *jsonschema.Schema] {
+					properties := orderedmap.New[string, *jsonschema.Schema]()
+					properties.Set("message_per_row", &jsonschema.Schema{Type: "boolean", Const: true})
+					return properties
+				}(),
+				Required: []string{"message_per_row"},
+			},
+		},
 	)
 }
 
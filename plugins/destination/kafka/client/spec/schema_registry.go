@@ -0,0 +1,49 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+)
+
+type SchemaType string
+
+const (
+	SchemaTypeAvro SchemaType = "avro"
+	SchemaTypeJSON SchemaType = "json"
+)
+
+type SchemaRegistry struct {
+	// URL of the Confluent Schema Registry, for example `http://localhost:8081`.
+	URL string `json:"url" jsonschema:"required,minLength=1"`
+
+	// Username for the basic authentication with the schema registry.
+	Username string `json:"username,omitempty"`
+
+	// Password for the basic authentication with the schema registry.
+	Password string `json:"password,omitempty"`
+
+	// Type of the schemas registered for the tables, and the encoding of the message values.
+	//
+	// - `avro` registers Avro schemas and encodes the values in the Avro binary format
+	//
+	// - `json` registers JSON schemas and encodes the values as JSON objects
+	SchemaType SchemaType `json:"schema_type,omitempty" jsonschema:"enum=avro,enum=json,default=avro"`
+}
+
+func (s *SchemaRegistry) SetDefaults() {
+	if s.SchemaType == "" {
+		s.SchemaType = SchemaTypeAvro
+	}
+}
+
+func (s *SchemaRegistry) Validate() error {
+	if s.URL == "" {
+		return errors.New("schema_registry.url is required")
+	}
+	switch s.SchemaType {
+	case "", SchemaTypeAvro, SchemaTypeJSON:
+	default:
+		return fmt.Errorf("invalid schema_registry.schema_type: %q", s.SchemaType)
+	}
+	return nil
+}
@@ -194,5 +194,95 @@ func TestSpecJSONSchema(t *testing.T) {
 			Name: "proper replication_factor and num_partitions",
 			Spec: `{"format": "csv", "brokers": ["abc"], "topic_details": {"num_partitions": 10, "replication_factor": 10}}`,
 		},
+		{
+			Name: "bad sasl_mechanism",
+			Spec: `{"format": "csv", "brokers": ["abc"], "sasl_mechanism": "GSSAPI"}`,
+			Err:  true,
+		},
+		{
+			Name: "proper sasl_mechanism",
+			Spec: `{"format": "csv", "brokers": ["abc"], "sasl_username": "user", "sasl_password": "password", "sasl_mechanism": "SCRAM-SHA-512"}`,
+		},
+		{
+			Name: "empty tls",
+			Spec: `{"format": "csv", "brokers": ["abc"], "tls": {}}`,
+		},
+		{
+			Name: "proper tls",
+			Spec: `{"format": "csv", "brokers": ["abc"], "tls": {"enabled": true, "ca_cert": "-----BEGIN CERTIFICATE-----", "client_cert": "cert", "client_key": "key", "server_name": "kafka", "insecure_skip_verify": false}}`,
+		},
+		{
+			Name: "tls disabled",
+			Spec: `{"format": "csv", "brokers": ["abc"], "sasl_username": "user", "sasl_password": "password", "tls": {"enabled": false}}`,
+		},
+		{
+			Name: "unknown tls field",
+			Spec: `{"format": "csv", "brokers": ["abc"], "tls": {"cert": "abc"}}`,
+			Err:  true,
+		},
+		{
+			Name: "bad compression_codec",
+			Spec: `{"format": "csv", "brokers": ["abc"], "compression_codec": "brotli"}`,
+			Err:  true,
+		},
+		{
+			Name: "proper compression_codec",
+			Spec: `{"format": "csv", "brokers": ["abc"], "compression_codec": "zstd"}`,
+		},
+		{
+			Name: "bad required_acks",
+			Spec: `{"format": "csv", "brokers": ["abc"], "required_acks": 1}`,
+			Err:  true,
+		},
+		{
+			Name: "proper idempotent and required_acks",
+			Spec: `{"format": "csv", "brokers": ["abc"], "idempotent": true, "required_acks": "all"}`,
+		},
+		{
+			Name: "empty topic",
+			Spec: `{"format": "csv", "brokers": ["abc"], "topic": ""}`,
+			Err:  true,
+		},
+		{
+			Name: "proper topic",
+			Spec: `{"format": "csv", "brokers": ["abc"], "topic": "cloudquery_{{TABLE}}"}`,
+		},
+		{
+			Name: "message_per_row:true",
+			Spec: `{"format": "csv", "brokers": ["abc"], "message_per_row": true}`,
+		},
+		{
+			Name: "bad message_key",
+			Spec: `{"format": "csv", "brokers": ["abc"], "message_key": "id"}`,
+			Err:  true,
+		},
+		{
+			Name: "schema_registry without message_per_row",
+			Spec: `{"format": "csv", "brokers": ["abc"], "schema_registry": {"url": "http://localhost:8081"}}`,
+			Err:  true,
+		},
+		{
+			Name: "schema_registry without url",
+			Spec: `{"format": "csv", "brokers": ["abc"], "message_per_row": true, "schema_registry": {"schema_type": "avro"}}`,
+			Err:  true,
+		},
+		{
+			Name: "bad schema_registry schema_type",
+			Spec: `{"format": "csv", "brokers": ["abc"], "message_per_row": true, "schema_registry": {"url": "http://localhost:8081", "schema_type": "protobuf"}}`,
+			Err:  true,
+		},
+		{
+			Name: "null schema_registry",
+			Spec: `{"format": "csv", "brokers": ["abc"], "schema_registry": null}`,
+			Err:  true,
+		},
+		{
+			Name: "proper schema_registry",
+			Spec: `{"format": "csv", "brokers": ["abc"], "message_per_row": true, "schema_registry": {"url": "http://localhost:8081", "username": "user", "password": "pass", "schema_type": "json"}}`,
+		},
+		{
+			Name: "proper message_key",
+			Spec: `{"format": "csv", "brokers": ["abc"], "message_per_row": true, "message_key": "cq_id"}`,
+		},
 	})
 }
@@ -0,0 +1,68 @@
+package spec
+
+import (
+	"fmt"
+)
+
+type SASLMechanism string
+
+const (
+	SASLMechanismPlain       SASLMechanism = "PLAIN"
+	SASLMechanismSCRAMSHA256 SASLMechanism = "SCRAM-SHA-256"
+	SASLMechanismSCRAMSHA512 SASLMechanism = "SCRAM-SHA-512"
+)
+
+type RequiredAcks string
+
+const (
+	RequiredAcksNone   RequiredAcks = "none"
+	RequiredAcksLeader RequiredAcks = "leader"
+	RequiredAcksAll    RequiredAcks = "all"
+)
+
+type CompressionCodec string
+
+const (
+	CompressionCodecNone   CompressionCodec = "none"
+	CompressionCodecGzip   CompressionCodec = "gzip"
+	CompressionCodecSnappy CompressionCodec = "snappy"
+	CompressionCodecLZ4    CompressionCodec = "lz4"
+	CompressionCodecZSTD   CompressionCodec = "zstd"
+)
+
+type TLS struct {
+	// If `true`, TLS is used to connect to the brokers.
+	// Defaults to `true` when the `tls` section is present.
+	Enabled *bool `json:"enabled,omitempty" jsonschema:"default=true"`
+
+	// PEM-encoded certificate authorities used to verify the brokers' certificates.
+	// When set, a certificate pool will be created by appending the certificates to the system pool.
+	//
+	// See [file variable substitution](/docs/advanced-topics/environment-variable-substitution#file-variable-substitution-example)
+	// for how to read this value from a file.
+	CACert string `json:"ca_cert,omitempty"`
+
+	// PEM-encoded client certificate, used for mutual TLS authentication together with `client_key`.
+	ClientCert string `json:"client_cert,omitempty"`
+
+	// PEM-encoded client private key, used for mutual TLS authentication together with `client_cert`.
+	ClientKey string `json:"client_key,omitempty"`
+
+	// Server name used to verify the brokers' certificates, if it differs from the broker host names.
+	ServerName string `json:"server_name,omitempty"`
+
+	// If `true`, the brokers' certificates are not verified.
+	// This is insecure and should only be used for testing.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+}
+
+func (t *TLS) IsEnabled() bool {
+	return t.Enabled == nil || *t.Enabled
+}
+
+func (t *TLS) Validate() error {
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		return fmt.Errorf("tls.client_cert and tls.client_key must be set together")
+	}
+	return nil
+}
@@ -2,10 +2,24 @@ package spec
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cloudquery/filetypes/v4"
 )
 
+const (
+	varTable = "{{TABLE}}"
+)
+
+type MessageKey string
+
+const (
+	// MessageKeyPrimaryKey uses the primary key columns of the table as the message key, or `_cq_id` if the table has no primary keys
+	MessageKeyPrimaryKey MessageKey = "primary_key"
+	// MessageKeyCQID uses the `_cq_id` column as the message key
+	MessageKeyCQID MessageKey = "cq_id"
+)
+
 type topicDetails struct {
 	// Number of partitions to create for the topic.
 	NumPartitions int `json:"num_partitions,omitempty" jsonschema:"minimum=1,default=1"`
@@ -26,22 +40,75 @@ type Spec struct {
 	// If `true`, the plugin will log all underlying Kafka client messages to the log.
 	Verbose bool `json:"verbose,omitempty"`
 
-	// If connecting via SASL/PLAIN, the username to use.
+	// If connecting via SASL, the username to use.
 	SASLUsername string `json:"sasl_username,omitempty"`
 
-	// If connecting via SASL/PLAIN, the password to use.
+	// If connecting via SASL, the password to use.
 	SASLPassword string `json:"sasl_password,omitempty"`
 
+	// SASL mechanism to use when `sasl_username` is set.
+	SASLMechanism SASLMechanism `json:"sasl_mechanism,omitempty" jsonschema:"enum=PLAIN,enum=SCRAM-SHA-256,enum=SCRAM-SHA-512,default=PLAIN"`
+
+	// TLS settings.
+	// If not set, TLS is enabled with the system certificate authorities when `sasl_username` is set, and disabled otherwise.
+	TLS *TLS `json:"tls,omitempty"`
+
 	// Client ID to be set for Kafka API calls.
 	ClientID string `json:"client_id,omitempty" jsonschema:"default=cq-destination-kafka"`
 
+	// If `true`, the idempotent producer is used, so that retries don't write duplicate messages.
+	// Requires `required_acks` to be `all`.
+	Idempotent bool `json:"idempotent,omitempty"`
+
+	// Compression codec of the produced messages.
+	// Unlike `compression`, the compression is done by the Kafka client and is transparent to the consumers.
+	CompressionCodec CompressionCodec `json:"compression_codec,omitempty" jsonschema:"enum=none,enum=gzip,enum=snappy,enum=lz4,enum=zstd,default=none"`
+
+	// Acknowledgements required from the brokers before a message is considered written.
+	//
+	// - `none` doesn't wait for any acknowledgement
+	//
+	// - `leader` waits for the partition leader to write the message
+	//
+	// - `all` waits for all in-sync replicas to write the message
+	RequiredAcks RequiredAcks `json:"required_acks,omitempty" jsonschema:"enum=none,enum=leader,enum=all,default=all"`
+
 	// Number of records to write before starting a new object.
 	BatchSize int `json:"batch_size" jsonschema:"minimum=1,default=1000"`
 
 	// Topic details, such as number of partitions and replication factor.
 	TopicDetails topicDetails `json:"topic_details"`
+
+	// Topic name template string, for example `cloudquery_{{TABLE}}`.
+	//
+	// `{{TABLE}}` will be replaced with the table name.
+	Topic string `json:"topic,omitempty" jsonschema:"minLength=1,default={{TABLE}}"`
+
+	// If `true`, each row is sent as a separate message keyed by `message_key`, instead of sending each batch of rows as a single message without a key.
+	MessagePerRow bool `json:"message_per_row,omitempty"`
+
+	// Key of the messages when `message_per_row` is `true`.
+	//
+	// - `primary_key` uses the values of the primary key columns of the table, or the `_cq_id` column if the table has no primary keys
+	//
+	// - `cq_id` uses the value of the `_cq_id` column
+	//
+	// Keys are sent as the plain column value for single column keys, and as a JSON object for composite keys.
+	MessageKey MessageKey `json:"message_key,omitempty" jsonschema:"enum=primary_key,enum=cq_id,default=primary_key"`
+
+	// Compacted topic recording the keys each source wrote to each topic during its last sync.
+	// With `message_per_row` and `write_mode: overwrite-delete-stale`, it's used to find the stale keys without reading the topics.
+	// The topic is created with a single partition if it doesn't exist.
+	KeyIndexTopic string `json:"key_index_topic,omitempty" jsonschema:"minLength=1,default=_cloudquery_key_index"`
+
+	// If set, a schema is registered for each table with the Confluent Schema Registry, under the `<topic>-value` subject,
+	// and the message values are encoded in the Confluent wire format instead of `format`.
+	// Requires `message_per_row` to be `true`.
+	SchemaRegistry *SchemaRegistry `json:"schema_registry,omitempty"`
 }
 
+const defaultKeyIndexTopic = "_cloudquery_key_index"
+
 func (s *Spec) SetDefaults() {
 	s.FileSpec.SetDefaults()
 
@@ -58,6 +125,27 @@ func (s *Spec) SetDefaults() {
 	if s.TopicDetails.ReplicationFactor < 1 {
 		s.TopicDetails.ReplicationFactor = 1
 	}
+	if s.Topic == "" {
+		s.Topic = varTable
+	}
+	if s.SASLMechanism == "" {
+		s.SASLMechanism = SASLMechanismPlain
+	}
+	if s.CompressionCodec == "" {
+		s.CompressionCodec = CompressionCodecNone
+	}
+	if s.RequiredAcks == "" {
+		s.RequiredAcks = RequiredAcksAll
+	}
+	if s.MessageKey == "" {
+		s.MessageKey = MessageKeyPrimaryKey
+	}
+	if s.KeyIndexTopic == "" {
+		s.KeyIndexTopic = defaultKeyIndexTopic
+	}
+	if s.SchemaRegistry != nil {
+		s.SchemaRegistry.SetDefaults()
+	}
 }
 
 func (s *Spec) Validate() error {
@@ -65,6 +153,46 @@ func (s *Spec) Validate() error {
 		return fmt.Errorf("at least one broker is required")
 	}
 
+	switch s.MessageKey {
+	case "", MessageKeyPrimaryKey, MessageKeyCQID:
+	default:
+		return fmt.Errorf("invalid message_key: %q", s.MessageKey)
+	}
+
+	switch s.SASLMechanism {
+	case "", SASLMechanismPlain, SASLMechanismSCRAMSHA256, SASLMechanismSCRAMSHA512:
+	default:
+		return fmt.Errorf("invalid sasl_mechanism: %q", s.SASLMechanism)
+	}
+	if s.TLS != nil {
+		if err := s.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+	switch s.CompressionCodec {
+	case "", CompressionCodecNone, CompressionCodecGzip, CompressionCodecSnappy, CompressionCodecLZ4, CompressionCodecZSTD:
+	default:
+		return fmt.Errorf("invalid compression_codec: %q", s.CompressionCodec)
+	}
+	switch s.RequiredAcks {
+	case "", RequiredAcksAll:
+	case RequiredAcksNone, RequiredAcksLeader:
+		if s.Idempotent {
+			return fmt.Errorf("idempotent requires required_acks to be %q", RequiredAcksAll)
+		}
+	default:
+		return fmt.Errorf("invalid required_acks: %q", s.RequiredAcks)
+	}
+
+	if s.SchemaRegistry != nil {
+		if err := s.SchemaRegistry.Validate(); err != nil {
+			return err
+		}
+		if !s.MessagePerRow {
+			return fmt.Errorf("schema_registry requires message_per_row to be true")
+		}
+	}
+
 	// required for s.FileSpec.Validate call
 	err := s.FileSpec.UnmarshalSpec()
 	if err != nil {
@@ -74,3 +202,8 @@ func (s *Spec) Validate() error {
 
 	return s.FileSpec.Validate()
 }
+
+// TopicName returns the name of the topic the table is written to
+func (s *Spec) TopicName(table string) string {
+	return strings.ReplaceAll(s.Topic, varTable, table)
+}
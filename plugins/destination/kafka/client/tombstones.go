@@ -0,0 +1,263 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// headerSourceName is the header holding the source name of per-row messages
+var headerSourceName = []byte(schema.CqSourceNameColumn.Name)
+
+// keyIndexChunkBytes limits the size of the keys of a key index message, so that it stays under the default 1MB message size limit
+// once the keys are base64 encoded
+const keyIndexChunkBytes = 512 * 1024
+
+// keyIndex holds the keys each source wrote to each topic during its last sync, as read from the key index topic.
+// An entry is stored as a message per chunk of keys, keyed by `<topic>\x00<source>\x00<chunk>`,
+// and a manifest message keyed by `<topic>\x00<source>` holding the number of chunks, written after the chunks.
+type keyIndex struct {
+	// values holds the latest value of each message key of the key index topic
+	values map[string][]byte
+}
+
+type keyIndexManifest struct {
+	Chunks int `json:"chunks"`
+}
+
+type keyIndexChunk struct {
+	Keys [][]byte `json:"keys"`
+}
+
+func keyIndexEntryKey(topic string, sourceName string) string {
+	return topic + "\x00" + sourceName
+}
+
+func keyIndexChunkKey(topic string, sourceName string, chunk int) string {
+	return keyIndexEntryKey(topic, sourceName) + "\x00" + strconv.Itoa(chunk)
+}
+
+func newKeyIndex() *keyIndex {
+	return &keyIndex{values: make(map[string][]byte)}
+}
+
+func (idx *keyIndex) add(msg *sarama.ConsumerMessage) {
+	if msg.Key == nil {
+		return
+	}
+	if msg.Value == nil {
+		delete(idx.values, string(msg.Key))
+		return
+	}
+	idx.values[string(msg.Key)] = msg.Value
+}
+
+// keys returns the keys the source wrote to the topic during its last sync, or false if the index has no entry for them
+func (idx *keyIndex) keys(topic string, sourceName string) ([][]byte, bool, error) {
+	value, ok := idx.values[keyIndexEntryKey(topic, sourceName)]
+	if !ok {
+		return nil, false, nil
+	}
+	var manifest keyIndexManifest
+	if err := json.Unmarshal(value, &manifest); err != nil {
+		return nil, false, fmt.Errorf("failed to decode key index manifest: %w", err)
+	}
+	var keys [][]byte
+	for i := 0; i < manifest.Chunks; i++ {
+		value, ok := idx.values[keyIndexChunkKey(topic, sourceName, i)]
+		if !ok {
+			return nil, false, fmt.Errorf("key index chunk %d of %d is missing", i, manifest.Chunks)
+		}
+		var chunk keyIndexChunk
+		if err := json.Unmarshal(value, &chunk); err != nil {
+			return nil, false, fmt.Errorf("failed to decode key index chunk %d: %w", i, err)
+		}
+		keys = append(keys, chunk.Keys...)
+	}
+	return keys, true, nil
+}
+
+// update returns the messages replacing the entry of the source and topic with the emitted keys,
+// and deleting the chunks of the previous entry that aren't overwritten.
+func (idx *keyIndex) update(indexTopic string, topic string, sourceName string, emitted map[string]struct{}) ([]*sarama.ProducerMessage, error) {
+	keys := sortedKeys(emitted)
+	var messages []*sarama.ProducerMessage
+	chunks := 0
+	for start := 0; start < len(keys); chunks++ {
+		end, size := start, 0
+		for end < len(keys) && (end == start || size+len(keys[end]) <= keyIndexChunkBytes) {
+			size += len(keys[end])
+			end++
+		}
+		value, err := json.Marshal(keyIndexChunk{Keys: keys[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &sarama.ProducerMessage{
+			Topic: indexTopic,
+			Key:   sarama.StringEncoder(keyIndexChunkKey(topic, sourceName, chunks)),
+			Value: sarama.ByteEncoder(value),
+		})
+		start = end
+	}
+
+	if previous, ok := idx.values[keyIndexEntryKey(topic, sourceName)]; ok {
+		var manifest keyIndexManifest
+		if err := json.Unmarshal(previous, &manifest); err == nil {
+			for i := chunks; i < manifest.Chunks; i++ {
+				messages = append(messages, &sarama.ProducerMessage{
+					Topic: indexTopic,
+					Key:   sarama.StringEncoder(keyIndexChunkKey(topic, sourceName, i)),
+					Value: nil,
+				})
+			}
+		}
+	}
+
+	// the manifest is written last, so that a partially written entry is never read
+	manifest, err := json.Marshal(keyIndexManifest{Chunks: chunks})
+	if err != nil {
+		return nil, err
+	}
+	messages = append(messages, &sarama.ProducerMessage{
+		Topic: indexTopic,
+		Key:   sarama.StringEncoder(keyIndexEntryKey(topic, sourceName)),
+		Value: sarama.ByteEncoder(manifest),
+	})
+	return messages, nil
+}
+
+// staleKeys returns the keys written to the topic during the previous sync of the source that weren't emitted during the current sync
+func staleKeys(previous [][]byte, emitted map[string]struct{}) [][]byte {
+	stale := make(map[string]struct{})
+	for _, key := range previous {
+		if _, ok := emitted[string(key)]; !ok {
+			stale[string(key)] = struct{}{}
+		}
+	}
+	return sortedKeys(stale)
+}
+
+func sortedKeys(keys map[string]struct{}) [][]byte {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	res := make([][]byte, len(sorted))
+	for i, key := range sorted {
+		res[i] = []byte(key)
+	}
+	return res
+}
+
+// readKeyIndex reads the key index topic up to its current end
+func (c *Client) readKeyIndex(ctx context.Context) (*keyIndex, error) {
+	client, err := sarama.NewClient(c.spec.Brokers, c.conf)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	index := newKeyIndex()
+	topic := c.spec.KeyIndexTopic
+	partitions, err := client.Partitions(topic)
+	if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions of topic %s: %w", topic, err)
+	}
+	for _, partition := range partitions {
+		if err := readPartition(ctx, client, consumer, topic, partition, index.add); err != nil {
+			return nil, fmt.Errorf("failed to read partition %d of topic %s: %w", partition, topic, err)
+		}
+	}
+	return index, nil
+}
+
+// readPartition reads the partition up to its current end
+func readPartition(ctx context.Context, client sarama.Client, consumer sarama.Consumer, topic string, partition int32, handle func(*sarama.ConsumerMessage)) error {
+	oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return err
+	}
+	newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return err
+	}
+	if oldest >= newest {
+		return nil
+	}
+	partitionConsumer, err := consumer.ConsumePartition(topic, partition, oldest)
+	if err != nil {
+		return err
+	}
+	defer partitionConsumer.Close()
+	offset := oldest - 1
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg := <-partitionConsumer.Messages():
+			handle(msg)
+			offset = msg.Offset
+			if offset >= newest-1 {
+				return nil
+			}
+		case err := <-partitionConsumer.Errors():
+			return err.Err
+		case <-time.After(maxWaitTime):
+			// compaction never removes the last message of a partition, so the end offset is always reached
+			return fmt.Errorf("timed out after %s waiting for messages at offset %d, before reaching the end offset %d", maxWaitTime, offset+1, newest)
+		}
+	}
+}
+
+// createKeyIndexTopic creates the compacted key index topic, if it doesn't exist
+func (c *Client) createKeyIndexTopic() error {
+	if !c.conf.Version.IsAtLeast(sarama.V2_0_0_0) {
+		c.conf.Version = sarama.V2_0_0_0
+	}
+	admin, err := sarama.NewClusterAdmin(c.spec.Brokers, c.conf)
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+	compact := "compact"
+	err = admin.CreateTopic(c.spec.KeyIndexTopic, &sarama.TopicDetail{
+		// a single partition keeps the chunks of an entry ordered before its manifest
+		NumPartitions:     1,
+		ReplicationFactor: int16(c.spec.TopicDetails.ReplicationFactor),
+		ConfigEntries:     map[string]*string{"cleanup.policy": &compact},
+	}, false)
+	if err != nil && !strings.Contains(err.Error(), "Topic with this name already exists") {
+		return err
+	}
+	return nil
+}
+
+func tombstones(topic string, keys [][]byte) []*sarama.ProducerMessage {
+	messages := make([]*sarama.ProducerMessage, len(keys))
+	for i, key := range keys {
+		messages[i] = &sarama.ProducerMessage{
+			Topic: topic,
+			Key:   sarama.ByteEncoder(key),
+			Value: nil,
+		}
+	}
+	return messages
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyIndex(t *testing.T) {
+	index := newKeyIndex()
+	_, ok, err := index.keys("topic", "source")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	emitted := map[string]struct{}{"1": {}, "2": {}}
+	updates, err := index.update("index", "topic", "source", emitted)
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+	addMessages(index, updates)
+	previous, ok, err := index.keys("topic", "source")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, [][]byte{[]byte("1"), []byte("2")}, previous)
+
+	// other sources & topics have their own entries
+	_, ok, err = index.keys("topic", "other")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.Equal(t, [][]byte{[]byte("1")}, staleKeys(previous, map[string]struct{}{"2": {}, "3": {}}))
+}
+
+func TestKeyIndexChunks(t *testing.T) {
+	index := newKeyIndex()
+	emitted := make(map[string]struct{})
+	for i := 0; i < 3; i++ {
+		emitted[strings.Repeat(strconv.Itoa(i), keyIndexChunkBytes/2)] = struct{}{}
+	}
+	updates, err := index.update("index", "topic", "source", emitted)
+	require.NoError(t, err)
+	// 2 chunks & the manifest
+	require.Len(t, updates, 3)
+	require.Equal(t, sarama.StringEncoder("topic\x00source"), updates[2].Key)
+	addMessages(index, updates)
+	previous, ok, err := index.keys("topic", "source")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, previous, 3)
+
+	// the chunks that aren't overwritten are deleted
+	updates, err = index.update("index", "topic", "source", map[string]struct{}{"1": {}})
+	require.NoError(t, err)
+	require.Len(t, updates, 3)
+	require.Equal(t, sarama.StringEncoder("topic\x00source\x001"), updates[1].Key)
+	require.Nil(t, updates[1].Value)
+	addMessages(index, updates)
+	previous, ok, err = index.keys("topic", "source")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, [][]byte{[]byte("1")}, previous)
+}
+
+func addMessages(index *keyIndex, messages []*sarama.ProducerMessage) {
+	for _, msg := range messages {
+		key, _ := msg.Key.Encode()
+		var value []byte
+		if msg.Value != nil {
+			value, _ = msg.Value.Encode()
+		}
+		index.add(&sarama.ConsumerMessage{Key: key, Value: value})
+	}
+}
+
+func TestTrackKeys(t *testing.T) {
+	emitted := make(map[string]map[string]struct{})
+	trackKeys(emitted, []*sarama.ProducerMessage{
+		{Topic: "a", Key: sarama.ByteEncoder("1")},
+		{Topic: "a", Key: sarama.ByteEncoder("2")},
+		{Topic: "b", Key: sarama.ByteEncoder("1")},
+	})
+	require.Equal(t, map[string]map[string]struct{}{
+		"a": {"1": {}, "2": {}},
+		"b": {"1": {}},
+	}, emitted)
+}
+
+func TestTombstones(t *testing.T) {
+	messages := tombstones("topic", [][]byte{[]byte("1")})
+	require.Len(t, messages, 1)
+	require.Equal(t, "topic", messages[0].Topic)
+	require.Equal(t, sarama.ByteEncoder("1"), messages[0].Key)
+	require.Nil(t, messages[0].Value)
+}
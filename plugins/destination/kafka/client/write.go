@@ -15,67 +15,218 @@ import (
 
 func (c *Client) Write(ctx context.Context, res <-chan message.WriteMessage) error {
 	var tables schema.Tables
+	keyColumns := make(map[string][]string)
+	// keys emitted during this sync by topic, used to find the stale keys when deleting stale rows
+	emitted := make(map[string]map[string]struct{})
+	// the key index is read on the first DeleteStale message
+	var index *keyIndex
 
 	messages := make([]*sarama.ProducerMessage, 0, c.spec.BatchSize)
+	flush := func() error {
+		if len(messages) == 0 {
+			return nil
+		}
+		if err := c.producer.SendMessages(messages); err != nil {
+			return err
+		}
+		// TODO(v4): Increment metrics
+		messages = messages[:0]
+		return nil
+	}
 	for r := range res {
 		switch m := r.(type) {
 		case *message.WriteMigrateTable:
 			tables = append(tables, m.Table)
+			keyColumns[m.Table.Name] = c.keyColumns(m.Table)
+			if c.registry != nil {
+				if _, err := c.registerSchema(ctx, m.Table); err != nil {
+					return err
+				}
+			}
 		case *message.WriteDeleteStale:
-			continue
+			if !c.spec.MessagePerRow {
+				continue
+			}
+			if index == nil {
+				if err := c.createKeyIndexTopic(); err != nil {
+					return fmt.Errorf("failed to create key index topic: %w", err)
+				}
+				var err error
+				if index, err = c.readKeyIndex(ctx); err != nil {
+					return fmt.Errorf("failed to read key index topic: %w", err)
+				}
+			}
+			topic := c.spec.TopicName(m.TableName)
+			previous, ok, err := index.keys(topic, m.SourceName)
+			if err != nil {
+				return fmt.Errorf("failed to get the keys of the previous sync of table %s: %w", m.TableName, err)
+			}
+			if ok {
+				keys := staleKeys(previous, emitted[topic])
+				c.logger.Debug().Str("table", m.TableName).Int("keys", len(keys)).Msg("Sending tombstones for stale keys")
+				messages = append(messages, tombstones(topic, keys)...)
+			} else {
+				c.logger.Info().Str("table", m.TableName).Msg("No keys recorded for the previous sync, stale keys will be deleted from the next sync")
+			}
+			updates, err := index.update(c.spec.KeyIndexTopic, topic, m.SourceName, emitted[topic])
+			if err != nil {
+				return fmt.Errorf("failed to update the key index of table %s: %w", m.TableName, err)
+			}
+			messages = append(messages, updates...)
+		case *message.WriteDeleteRecord:
+			if !c.spec.MessagePerRow {
+				c.logger.Warn().Str("table", m.TableName).Msg("DeleteRecord requires message_per_row, skipping")
+				continue
+			}
+			columns, ok := keyColumns[m.TableName]
+			if !ok {
+				c.logger.Warn().Str("table", m.TableName).Msg("DeleteRecord for a table that wasn't migrated, skipping")
+				continue
+			}
+			keys, ok, err := deleteRecordKeys(m.WhereClause, columns)
+			if err != nil {
+				return fmt.Errorf("failed to get keys to delete from table %s: %w", m.TableName, err)
+			}
+			if !ok {
+				c.logger.Warn().Str("table", m.TableName).Strs("key_columns", columns).Msg("DeleteRecord where clause doesn't match the key columns, skipping")
+				continue
+			}
+			if len(m.TableRelations) > 0 {
+				c.logger.Warn().Str("table", m.TableName).Msg("Tombstones are not sent for the rows of relations of deleted records")
+			}
+			messages = append(messages, tombstones(c.spec.TopicName(m.TableName), keys)...)
 		case *message.WriteInsert:
 			if err := c.createTopics(ctx, tables); err != nil {
 				return fmt.Errorf("failed to create topics: %w", err)
 			}
 			tables = nil
 
-			table := m.GetTable()
-			var b bytes.Buffer
-			w := bufio.NewWriter(&b)
-			if err := c.Client.WriteTableBatchFile(w, table, []arrow.Record{m.Record}); err != nil {
+			msgs, err := c.insertMessages(ctx, m.GetTable(), m.Record)
+			if err != nil {
 				return err
 			}
-			if err := w.Flush(); err != nil {
-				return fmt.Errorf("failed to flush buffer: %w", err)
+			if c.spec.MessagePerRow {
+				trackKeys(emitted, msgs)
 			}
-
-			messages = append(messages, &sarama.ProducerMessage{
-				Topic: table.Name,
-				Key:   nil,
-				Value: sarama.ByteEncoder(b.Bytes()),
-			})
-			if len(messages) >= c.spec.BatchSize {
-				if err := c.producer.SendMessages(messages); err != nil {
-					return err
-				}
-				// TODO(v4): Increment metrics
-				messages = messages[:0]
-			}
-
+			messages = append(messages, msgs...)
 		default:
 			return fmt.Errorf("unhandled message type: %T", m)
 		}
+		if len(messages) >= c.spec.BatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
 	}
 
-	if len(messages) > 0 {
-		if err := c.producer.SendMessages(messages); err != nil {
-			return err
+	return flush()
+}
+
+func trackKeys(emitted map[string]map[string]struct{}, messages []*sarama.ProducerMessage) {
+	for _, msg := range messages {
+		keys, ok := emitted[msg.Topic]
+		if !ok {
+			keys = make(map[string]struct{})
+			emitted[msg.Topic] = keys
 		}
-		// TODO(v4): Increment metrics
+		keys[string(msg.Key.(sarama.ByteEncoder))] = struct{}{}
 	}
+}
 
-	return nil
+// insertMessages returns the messages for the inserted record, either a single message for the whole record
+// or a message per row when `message_per_row` is enabled
+func (c *Client) insertMessages(ctx context.Context, table *schema.Table, record arrow.Record) ([]*sarama.ProducerMessage, error) {
+	topic := c.spec.TopicName(table.Name)
+	if !c.spec.MessagePerRow {
+		b, err := c.encode(table, record)
+		if err != nil {
+			return nil, err
+		}
+		return []*sarama.ProducerMessage{{
+			Topic: topic,
+			Key:   nil,
+			Value: sarama.ByteEncoder(b),
+		}}, nil
+	}
+
+	keys, err := messageKeys(record, c.keyColumns(table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message keys for table %s: %w", table.Name, err)
+	}
+	headers := sourceNameHeaders(record)
+	messages := make([]*sarama.ProducerMessage, len(keys))
+	if c.registry != nil {
+		values, err := c.registryValues(ctx, table, record)
+		if err != nil {
+			return nil, err
+		}
+		for i := range keys {
+			messages[i] = &sarama.ProducerMessage{
+				Topic:   topic,
+				Key:     sarama.ByteEncoder(keys[i]),
+				Value:   sarama.ByteEncoder(values[i]),
+				Headers: headers[i],
+			}
+		}
+		return messages, nil
+	}
+	for i := range keys {
+		row := record.NewSlice(int64(i), int64(i+1))
+		b, err := c.encode(table, row)
+		row.Release()
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = &sarama.ProducerMessage{
+			Topic:   topic,
+			Key:     sarama.ByteEncoder(keys[i]),
+			Value:   sarama.ByteEncoder(b),
+			Headers: headers[i],
+		}
+	}
+	return messages, nil
+}
+
+// sourceNameHeaders returns the headers with the source name of each row, or nil headers if the record has no source name column
+func sourceNameHeaders(record arrow.Record) [][]sarama.RecordHeader {
+	headers := make([][]sarama.RecordHeader, record.NumRows())
+	indices := record.Schema().FieldIndices(schema.CqSourceNameColumn.Name)
+	if len(indices) == 0 {
+		return headers
+	}
+	col := record.Column(indices[0])
+	for i := range headers {
+		if col.IsNull(i) {
+			continue
+		}
+		headers[i] = []sarama.RecordHeader{{Key: headerSourceName, Value: []byte(col.ValueStr(i))}}
+	}
+	return headers
+}
+
+func (c *Client) encode(table *schema.Table, record arrow.Record) ([]byte, error) {
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	if err := c.Client.WriteTableBatchFile(w, table, []arrow.Record{record}); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to flush buffer: %w", err)
+	}
+	return b.Bytes(), nil
 }
 
 func (c *Client) createTopics(_ context.Context, tables schema.Tables) error {
-	c.conf.Version = sarama.V2_0_0_0
+	if !c.conf.Version.IsAtLeast(sarama.V2_0_0_0) {
+		c.conf.Version = sarama.V2_0_0_0
+	}
 	admin, err := sarama.NewClusterAdmin(c.spec.Brokers, c.conf)
 	if err != nil {
 		return err
 	}
 	defer admin.Close()
 	for _, table := range tables {
-		err := admin.CreateTopic(table.Name, &sarama.TopicDetail{
+		err := admin.CreateTopic(c.spec.TopicName(table.Name), &sarama.TopicDetail{
 			NumPartitions:     int32(c.spec.TopicDetails.NumPartitions),
 			ReplicationFactor: int16(c.spec.TopicDetails.ReplicationFactor),
 		}, false)
@@ -3,7 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
-
+	"maps"
 	"slices"
 
 	"github.com/cloudquery/plugin-sdk/v4/schema"
@@ -13,41 +13,130 @@ import (
 type indexSchema struct {
 	UID        string
 	PrimaryKey string
-	Attributes []string
-	Index      int
+	// Settings holds the settings managed by the plugin:
+	// the searchable, filterable & sortable attributes, the synonyms and the ranking rules.
+	Settings meilisearch.Settings
+	// defaultFilterable & defaultSortable are set when the filterable & sortable attributes default to the table columns,
+	// in which case the attributes of the removed columns are kept.
+	defaultFilterable, defaultSortable bool
+	Index                              int
 }
 
 func (i *indexSchema) init(index *meilisearch.Index) (*indexSchema, error) {
 	i.UID = index.UID
 	i.PrimaryKey = index.PrimaryKey
 
-	attrs, err := index.GetFilterableAttributes()
+	settings, err := index.GetSettings()
 	if err != nil {
 		return nil, err
 	}
-	if attrs != nil {
-		i.Attributes = *attrs
+	if settings != nil {
+		i.Settings = meilisearch.Settings{
+			SearchableAttributes: settings.SearchableAttributes,
+			FilterableAttributes: settings.FilterableAttributes,
+			SortableAttributes:   settings.SortableAttributes,
+			Synonyms:             settings.Synonyms,
+			RankingRules:         settings.RankingRules,
+		}
 	}
 
 	return i, nil
 }
 
+// canMigrate reports whether the index can be migrated in place to the schema o.
+// The settings are updated in place, so only the primary key requires recreating the index.
 func (i *indexSchema) canMigrate(o *indexSchema) bool {
 	return i.UID == o.UID && i.PrimaryKey == o.PrimaryKey
 }
 
-func tableIndexSchema(table *schema.Table) *indexSchema {
-	return &indexSchema{
+// settingsUpdate returns the settings to update for the index with the current settings to have the settings of the schema,
+// or nil if the index is up to date.
+// The settings not set in the schema are left as is.
+func (i *indexSchema) settingsUpdate(current *indexSchema) *meilisearch.Settings {
+	var update meilisearch.Settings
+	changed := false
+
+	filterable, sortable := i.Settings.FilterableAttributes, i.Settings.SortableAttributes
+	if i.defaultFilterable {
+		filterable = mergeAttributes(filterable, current.Settings.FilterableAttributes)
+	}
+	if i.defaultSortable {
+		sortable = mergeAttributes(sortable, current.Settings.SortableAttributes)
+	}
+	if !sameAttributes(filterable, current.Settings.FilterableAttributes) {
+		update.FilterableAttributes = filterable
+		changed = true
+	}
+	if !sameAttributes(sortable, current.Settings.SortableAttributes) {
+		update.SortableAttributes = sortable
+		changed = true
+	}
+
+	// the order of the searchable attributes & ranking rules matters
+	if len(i.Settings.SearchableAttributes) > 0 && !slices.Equal(i.Settings.SearchableAttributes, current.Settings.SearchableAttributes) {
+		update.SearchableAttributes = i.Settings.SearchableAttributes
+		changed = true
+	}
+	if len(i.Settings.RankingRules) > 0 && !slices.Equal(i.Settings.RankingRules, current.Settings.RankingRules) {
+		update.RankingRules = i.Settings.RankingRules
+		changed = true
+	}
+	if len(i.Settings.Synonyms) > 0 && !maps.EqualFunc(i.Settings.Synonyms, current.Settings.Synonyms, sameAttributes) {
+		update.Synonyms = i.Settings.Synonyms
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return &update
+}
+
+// mergeAttributes returns the sorted union of the attributes.
+func mergeAttributes(attributes []string, more []string) []string {
+	merged := append(slices.Clone(attributes), more...)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// sameAttributes reports whether the attributes are the same, regardless of their order.
+func sameAttributes(a []string, b []string) bool {
+	return slices.Equal(mergeAttributes(a, nil), mergeAttributes(b, nil))
+}
+
+func tableIndexSchema(table *schema.Table, settings *IndexSettings) *indexSchema {
+	s := &indexSchema{
 		UID:        table.Name,
 		PrimaryKey: hashColumnName,
-		Attributes: table.Columns.Names(),
-	}
+		Settings: meilisearch.Settings{
+			FilterableAttributes: table.Columns.Names(),
+			SortableAttributes:   table.Columns.Names(),
+		},
+		defaultFilterable: true,
+		defaultSortable:   true,
+	}
+	if settings == nil {
+		return s
+	}
+	// the attributes set explicitly replace the current ones
+	if len(settings.FilterableAttributes) > 0 {
+		s.Settings.FilterableAttributes = settings.FilterableAttributes
+		s.defaultFilterable = false
+	}
+	if len(settings.SortableAttributes) > 0 {
+		s.Settings.SortableAttributes = settings.SortableAttributes
+		s.defaultSortable = false
+	}
+	s.Settings.SearchableAttributes = settings.SearchableAttributes
+	s.Settings.Synonyms = settings.Synonyms
+	s.Settings.RankingRules = settings.RankingRules
+	return s
 }
 
-func tablesIndexSchemas(tables schema.Tables) map[string]*indexSchema {
+func (c *Client) tablesIndexSchemas(tables schema.Tables) map[string]*indexSchema {
 	res := make(map[string]*indexSchema)
 	for i, table := range tables {
-		s := tableIndexSchema(table)
+		s := tableIndexSchema(table, c.spec.tableSettings(table.Name))
 		s.Index = i
 		res[s.UID] = s
 	}
@@ -82,53 +171,23 @@ func (c *Client) indexes() (map[string]*indexSchema, error) {
 	return result, nil
 }
 
-func (c *Client) configureIndex(ctx context.Context, s *indexSchema) error {
+// configureIndex updates the settings of the index with the current settings to the settings of the schema.
+func (c *Client) configureIndex(ctx context.Context, s *indexSchema, current *indexSchema) error {
 	c.logger.Debug().Str("index", s.UID).Msg("configuring index")
 
-	index, err := c.Meilisearch.GetIndex(s.UID)
-	if err != nil {
-		return err
-	}
-
-	attributes := slices.Clone(s.Attributes)
-
-	attrs, err := index.GetFilterableAttributes()
-	if err != nil {
-		return err
-	}
-
-	var current []string
-	if attrs != nil {
-		current = *attrs
-	}
-
-	if len(current) > 0 {
-		attributes = append(attributes, current...)
-		slices.Sort(attributes)
-		attributes = slices.Compact(attributes)
-		if len(attributes) == len(s.Attributes) {
-			// already the same filtered attributes, skip
-			c.logger.Info().Str("index", index.UID).Msg("index is already properly configured, skip")
-			return nil
-		}
-	}
-
-	taskInfo, err := index.UpdateFilterableAttributes(&attributes)
-	if err != nil {
-		return err
-	}
-
-	if err := c.waitTask(ctx, taskInfo); err != nil {
-		return fmt.Errorf("failed to update filterable attributes for index %q: %w", index.UID, err)
+	update := s.settingsUpdate(current)
+	if update == nil {
+		c.logger.Info().Str("index", s.UID).Msg("index is already properly configured, skip")
+		return nil
 	}
 
-	taskInfo, err = index.UpdateSortableAttributes(&attributes)
+	taskInfo, err := c.Meilisearch.Index(s.UID).UpdateSettings(update)
 	if err != nil {
 		return err
 	}
 
 	if err := c.waitTask(ctx, taskInfo); err != nil {
-		return fmt.Errorf("failed to update sortable attributes for index %q: %w", index.UID, err)
+		return fmt.Errorf("failed to update settings for index %q: %w", s.UID, err)
 	}
 
 	return nil
@@ -149,7 +208,8 @@ func (c *Client) createIndex(ctx context.Context, s *indexSchema) error {
 		return fmt.Errorf("failed to create index %q: %w", s.UID, err)
 	}
 
-	return c.configureIndex(ctx, s)
+	// the settings not set in the schema are left with the defaults of the new index
+	return c.configureIndex(ctx, s, &indexSchema{UID: s.UID})
 }
 
 func (c *Client) recreateIndex(ctx context.Context, s *indexSchema) error {
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/cloudquery/plugin-sdk/v4/glob"
+)
+
+var customRankingRuleRegexp = regexp.MustCompile(`^[^:]+:(asc|desc)$`)
+
+// IndexSettings overrides the settings of the indexes of the matching tables.
+type IndexSettings struct {
+	// [Attributes](https://www.meilisearch.com/docs/learn/relevancy/displayed_searchable_attributes#searchable-fields)
+	// whose values are searched, in order of importance.
+	// If not set, the searchable attributes of the index aren't changed.
+	SearchableAttributes []string `json:"searchable_attributes,omitempty" jsonschema:"minLength=1"`
+
+	// [Attributes](https://www.meilisearch.com/docs/learn/filtering_and_sorting/filter_search_results) usable in filters and facets.
+	// If not set, all the table columns are filterable.
+	FilterableAttributes []string `json:"filterable_attributes,omitempty" jsonschema:"minLength=1"`
+
+	// [Attributes](https://www.meilisearch.com/docs/learn/filtering_and_sorting/sort_search_results) usable for sorting.
+	// If not set, all the table columns are sortable.
+	SortableAttributes []string `json:"sortable_attributes,omitempty" jsonschema:"minLength=1"`
+
+	// [Synonyms](https://www.meilisearch.com/docs/learn/relevancy/synonyms): the words considered equivalent to each word.
+	// If not set, the synonyms of the index aren't changed.
+	Synonyms map[string][]string `json:"synonyms,omitempty"`
+
+	// [Ranking rules](https://www.meilisearch.com/docs/learn/relevancy/ranking_rules), in order of importance.
+	// Either a built-in rule (`words`, `typo`, `proximity`, `attribute`, `sort`, `exactness`)
+	// or a custom rule on an attribute (`<attribute>:asc` or `<attribute>:desc`).
+	// If not set, the ranking rules of the index aren't changed.
+	RankingRules []string `json:"ranking_rules,omitempty" jsonschema:"pattern=^(words|typo|proximity|attribute|sort|exactness|[^:]+:(asc|desc))$"`
+}
+
+func (s *IndexSettings) validate() error {
+	for name, attributes := range map[string][]string{
+		"searchable_attributes": s.SearchableAttributes,
+		"filterable_attributes": s.FilterableAttributes,
+		"sortable_attributes":   s.SortableAttributes,
+	} {
+		for _, attribute := range attributes {
+			if len(attribute) == 0 {
+				return fmt.Errorf("empty %q entry", name)
+			}
+		}
+	}
+	for word := range s.Synonyms {
+		if len(word) == 0 {
+			return fmt.Errorf("empty \"synonyms\" word")
+		}
+	}
+	for _, rule := range s.RankingRules {
+		switch rule {
+		case "words", "typo", "proximity", "attribute", "sort", "exactness":
+		default:
+			if !customRankingRuleRegexp.MatchString(rule) {
+				return fmt.Errorf("invalid \"ranking_rules\" entry %q", rule)
+			}
+		}
+	}
+	return nil
+}
+
+// tableSettings returns the `tables` entry matching the table, or nil if there's none.
+// Exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+func (s *Spec) tableSettings(table string) *IndexSettings {
+	if settings, ok := s.Tables[table]; ok {
+		return settings
+	}
+	patterns := make([]string, 0, len(s.Tables))
+	for pattern := range s.Tables {
+		patterns = append(patterns, pattern)
+	}
+	// longer patterns are more specific, so they're matched first
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if glob.Glob(pattern, table) {
+			return s.Tables[pattern]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+func testIndexTable() *schema.Table {
+	return &schema.Table{
+		Name: "aws_ec2_instances",
+		Columns: schema.ColumnList{
+			{Name: "account_id", Type: arrow.BinaryTypes.String},
+			{Name: "region", Type: arrow.BinaryTypes.String},
+			{Name: "arn", Type: arrow.BinaryTypes.String},
+		},
+	}
+}
+
+func requireEqual(t *testing.T, expected any, actual any) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
+
+func requireUpdate(t *testing.T, update *meilisearch.Settings) {
+	t.Helper()
+	if update == nil {
+		t.Fatal("expected settings update")
+	}
+}
+
+func requireNoUpdate(t *testing.T, update *meilisearch.Settings) {
+	t.Helper()
+	if update != nil {
+		t.Fatalf("expected no settings update, got %+v", *update)
+	}
+}
+
+func TestTableSettings(t *testing.T) {
+	spec := Spec{Tables: map[string]*IndexSettings{
+		"*":                 {RankingRules: []string{"words"}},
+		"aws_*":             {RankingRules: []string{"typo"}},
+		"aws_ec2_*":         {RankingRules: []string{"proximity"}},
+		"aws_ec2_instances": {RankingRules: []string{"exactness"}},
+	}}
+	requireEqual(t, []string{"exactness"}, spec.tableSettings("aws_ec2_instances").RankingRules)
+	requireEqual(t, []string{"proximity"}, spec.tableSettings("aws_ec2_vpcs").RankingRules)
+	requireEqual(t, []string{"typo"}, spec.tableSettings("aws_s3_buckets").RankingRules)
+	requireEqual(t, []string{"words"}, spec.tableSettings("gcp_projects").RankingRules)
+	if settings := (&Spec{}).tableSettings("gcp_projects"); settings != nil {
+		t.Fatalf("expected no settings, got %+v", *settings)
+	}
+}
+
+func TestSettingsUpdateDefaults(t *testing.T) {
+	want := tableIndexSchema(testIndexTable(), nil)
+
+	// new index
+	update := want.settingsUpdate(&indexSchema{})
+	requireUpdate(t, update)
+	requireEqual(t, []string{"account_id", "arn", "region"}, update.FilterableAttributes)
+	requireEqual(t, []string{"account_id", "arn", "region"}, update.SortableAttributes)
+	if update.SearchableAttributes != nil {
+		t.Fatalf("expected no change, got %v", update.SearchableAttributes)
+	}
+	if update.RankingRules != nil {
+		t.Fatalf("expected no change, got %v", update.RankingRules)
+	}
+	if update.Synonyms != nil {
+		t.Fatalf("expected no change, got %v", update.Synonyms)
+	}
+
+	// up to date, the attributes of the removed columns are kept
+	current := &indexSchema{Settings: meilisearch.Settings{
+		FilterableAttributes: []string{"account_id", "arn", "region", "removed"},
+		SortableAttributes:   []string{"removed", "region", "arn", "account_id"},
+		SearchableAttributes: []string{"*"},
+		RankingRules:         []string{"words", "typo"},
+	}}
+	requireNoUpdate(t, want.settingsUpdate(current))
+
+	// added column
+	current.Settings.SortableAttributes = []string{"account_id", "region"}
+	update = want.settingsUpdate(current)
+	requireUpdate(t, update)
+	if update.FilterableAttributes != nil {
+		t.Fatalf("expected no change, got %v", update.FilterableAttributes)
+	}
+	requireEqual(t, []string{"account_id", "arn", "region"}, update.SortableAttributes)
+}
+
+func TestSettingsUpdate(t *testing.T) {
+	want := tableIndexSchema(testIndexTable(), &IndexSettings{
+		SearchableAttributes: []string{"arn", "account_id"},
+		FilterableAttributes: []string{"account_id", "region"},
+		Synonyms:             map[string][]string{"vm": {"instance"}},
+		RankingRules:         []string{"words", "sort", "region:asc"},
+	})
+
+	current := &indexSchema{Settings: meilisearch.Settings{
+		SearchableAttributes: []string{"*"},
+		FilterableAttributes: []string{"account_id", "arn", "region"},
+		SortableAttributes:   []string{"account_id", "arn", "region"},
+		RankingRules:         []string{"words", "typo"},
+	}}
+	update := want.settingsUpdate(current)
+	requireUpdate(t, update)
+	requireEqual(t, &meilisearch.Settings{
+		SearchableAttributes: []string{"arn", "account_id"},
+		FilterableAttributes: []string{"account_id", "region"},
+		Synonyms:             map[string][]string{"vm": {"instance"}},
+		RankingRules:         []string{"words", "sort", "region:asc"},
+	}, update)
+
+	current.Settings = meilisearch.Settings{
+		SearchableAttributes: []string{"arn", "account_id"},
+		FilterableAttributes: []string{"region", "account_id"},
+		SortableAttributes:   []string{"account_id", "arn", "region"},
+		Synonyms:             map[string][]string{"vm": {"instance"}},
+		RankingRules:         []string{"words", "sort", "region:asc"},
+	}
+	requireNoUpdate(t, want.settingsUpdate(current))
+
+	// the order of the searchable attributes matters
+	current.Settings.SearchableAttributes = []string{"account_id", "arn"}
+	update = want.settingsUpdate(current)
+	requireUpdate(t, update)
+	requireEqual(t, []string{"arn", "account_id"}, update.SearchableAttributes)
+}
+
+func TestIndexSettingsValidate(t *testing.T) {
+	if err := (&IndexSettings{RankingRules: []string{"words", "typo", "region:desc"}}).validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, settings := range []IndexSettings{
+		{RankingRules: []string{"region"}},
+		{SortableAttributes: []string{""}},
+		{Synonyms: map[string][]string{"": {"word"}}},
+	} {
+		if err := settings.validate(); err == nil {
+			t.Errorf("expected error for %+v", settings)
+		}
+	}
+}
@@ -20,7 +20,7 @@ func (c *Client) MigrateTables(ctx context.Context, messages message.WriteMigrat
 		tables[i] = msg.Table
 	}
 
-	want := tablesIndexSchemas(tables)
+	want := c.tablesIndexSchemas(tables)
 
 	var recreate, create, update []*indexSchema
 	for uid, need := range want {
@@ -29,7 +29,9 @@ func (c *Client) MigrateTables(ctx context.Context, messages message.WriteMigrat
 		case got == nil:
 			create = append(create, need)
 		case got.canMigrate(need):
-			update = append(update, need)
+			if need.settingsUpdate(got) != nil {
+				update = append(update, need)
+			}
 		default:
 			recreate = append(recreate, need)
 			if !messages[need.Index].MigrateForce {
@@ -45,7 +47,7 @@ func (c *Client) MigrateTables(ctx context.Context, messages message.WriteMigrat
 	}
 
 	for _, index := range update {
-		if err := c.configureIndex(ctx, index); err != nil {
+		if err := c.configureIndex(ctx, index, have[index.UID]); err != nil {
 			return err
 		}
 	}
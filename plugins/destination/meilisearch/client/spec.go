@@ -46,6 +46,13 @@ type Spec struct {
 
 	// Timeout for writing a single batch.
 	BatchTimeout *configtype.Duration `json:"batch_timeout,omitempty"`
+
+	// Settings of the indexes, by table name (or glob pattern).
+	// If several entries match the table, the most specific one is used:
+	// exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+	//
+	// The settings are applied when the tables are migrated.
+	Tables map[string]*IndexSettings `json:"tables,omitempty"`
 }
 
 func (s *Spec) validate() error {
@@ -54,9 +61,16 @@ func (s *Spec) validate() error {
 		return fmt.Errorf("empty \"host\" value")
 	case len(s.APIKey) == 0:
 		return fmt.Errorf("empty \"api_key\" value")
-	default:
-		return nil
 	}
+	for pattern, settings := range s.Tables {
+		if settings == nil {
+			continue
+		}
+		if err := settings.validate(); err != nil {
+			return fmt.Errorf("invalid settings for tables %q: %w", pattern, err)
+		}
+	}
+	return nil
 }
 
 func (s *Spec) setDefaults() {
@@ -77,5 +77,24 @@ func TestJSONSchema(t *testing.T) {
 			Spec: `{"host": "abc", "api_key": "foo", "unknown": "test"}`,
 			Err:  true,
 		},
+		{
+			Name: "spec with tables",
+			Spec: `{"host": "abc", "api_key": "foo", "tables": {"aws_*": {"searchable_attributes": ["arn", "tags"], "filterable_attributes": ["account_id", "region"], "sortable_attributes": ["region"], "synonyms": {"vm": ["instance"]}, "ranking_rules": ["words", "sort", "region:asc"]}}}`,
+		},
+		{
+			Name: "spec with empty filterable attribute",
+			Spec: `{"host": "abc", "api_key": "foo", "tables": {"aws_*": {"filterable_attributes": [""]}}}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with invalid ranking rule",
+			Spec: `{"host": "abc", "api_key": "foo", "tables": {"aws_*": {"ranking_rules": ["region:up"]}}}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with unknown table setting",
+			Spec: `{"host": "abc", "api_key": "foo", "tables": {"aws_*": {"displayed_attributes": ["arn"]}}}`,
+			Err:  true,
+		},
 	})
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/cloudquery/plugin-sdk/v4/plugin"
 	"github.com/cloudquery/plugin-sdk/v4/writers/batchwriter"
@@ -18,11 +19,16 @@ type Client struct {
 	spec   *Spec
 	client neo4j.DriverWithContext
 	writer *batchwriter.BatchWriter
+
+	// child table names by parent table name
+	children     map[string][]string
+	childrenLock sync.RWMutex
 }
 
 func New(ctx context.Context, logger zerolog.Logger, spec []byte, _ plugin.NewClientOptions) (plugin.Client, error) {
 	c := &Client{
-		logger: logger.With().Str("module", "neo4j").Logger(),
+		logger:   logger.With().Str("module", "neo4j").Logger(),
+		children: make(map[string][]string),
 	}
 	if err := json.Unmarshal(spec, &c.spec); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal neo4j spec: %w", err)
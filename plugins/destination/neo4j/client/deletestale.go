@@ -9,15 +9,21 @@ import (
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
-const deleteCypher = "MATCH (n:%s) WHERE n._cq_source_name = $cq_source_name AND n._cq_sync_time < $cq_sync_time DETACH DELETE n"
+const (
+	deleteCypher = "MATCH (n:%s) WHERE n._cq_source_name = $cq_source_name AND n._cq_sync_time < $cq_sync_time DETACH DELETE n"
+	// relationships that weren't created or updated during the sync, e.g. when the referenced node changed
+	deleteRelationshipsCypher = "MATCH (:%s)-[r]-() WHERE r._cq_source_name = $cq_source_name AND r._cq_sync_time < $cq_sync_time DELETE r"
+)
 
 func (c *Client) DeleteStale(ctx context.Context, msgs message.WriteDeleteStales) error {
 	session := c.Session(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close(ctx)
 	for _, msg := range msgs {
-		stmt := fmt.Sprintf(deleteCypher, msg.TableName)
-		if _, err := session.Run(ctx, stmt, map[string]any{"cq_source_name": msg.SourceName, "cq_sync_time": msg.SyncTime.Truncate(time.Microsecond)}); err != nil {
-			return err
+		params := map[string]any{"cq_source_name": msg.SourceName, "cq_sync_time": msg.SyncTime.Truncate(time.Microsecond)}
+		for _, cypher := range []string{deleteCypher, deleteRelationshipsCypher} {
+			if _, err := session.Run(ctx, fmt.Sprintf(cypher, quoteName(msg.TableName)), params); err != nil {
+				return err
+			}
 		}
 	}
 	return session.Close(ctx)
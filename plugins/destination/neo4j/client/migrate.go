@@ -15,11 +15,18 @@ import (
 // If the error occurs & indicates that the issue is caused by conflicting schema, 2 scenarios can happen:
 // 1. Force mode is selected for migration: drop & recreate index (without checking for error this time)
 // 2. No forced migration is requested - return error.
+// Indexes on the properties used to create relationships are created as well.
 func (c *Client) MigrateTables(ctx context.Context, messages message.WriteMigrateTables) error {
 	if len(messages) == 0 {
 		return nil
 	}
 
+	tables := make(schema.Tables, len(messages))
+	for i, m := range messages {
+		tables[i] = m.Table
+	}
+	c.setChildren(tables)
+
 	sess := c.Session(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer sess.Close(ctx)
 
@@ -27,6 +34,24 @@ func (c *Client) MigrateTables(ctx context.Context, messages message.WriteMigrat
 		if err := c.tryCreateIndex(ctx, sess, m); err != nil {
 			return err
 		}
+		if err := c.createRelationshipIndexes(ctx, sess, m.Table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createRelationshipIndexes creates the indexes used to match the nodes of relationships.
+// The index definitions never change, so no forced migration is required.
+func (c *Client) createRelationshipIndexes(ctx context.Context, sess neo4j.SessionWithContext, table *schema.Table) error {
+	for _, index := range relationshipIndexes(table, c.spec.Relationships) {
+		if _, err := sess.Run(ctx, index.query(), map[string]any{}); err != nil {
+			c.logger.Err(err).
+				Str("table", table.Name).
+				Str("index", index.name()).
+				Msg("failed to create relationship index")
+			return fmt.Errorf("failed to create relationship index for %q: %w", table.Name, err)
+		}
 	}
 	return nil
 }
@@ -60,7 +85,7 @@ func (c *Client) tryCreateIndex(ctx context.Context, sess neo4j.SessionWithConte
 		Err(err).
 		Msg("failed to create index, recreating")
 
-	_, err = sess.Run(ctx, `DROP INDEX `+index+` IF EXISTS;`, map[string]any{})
+	_, err = sess.Run(ctx, `DROP INDEX `+quoteName(index)+` IF EXISTS;`, map[string]any{})
 	if err != nil {
 		c.logger.Err(err).
 			Str("table", migrate.Table.Name).
@@ -82,16 +107,16 @@ func createIndexQuery(table *schema.Table) string {
 
 	var sb strings.Builder
 	sb.WriteString(`CREATE INDEX `)
-	sb.WriteString(indexName(table))
+	sb.WriteString(quoteName(indexName(table)))
 	sb.WriteString(` IF NOT EXISTS FOR (n:`)
-	sb.WriteString(table.Name)
+	sb.WriteString(quoteName(table.Name))
 	sb.WriteString(`) ON (`)
 	for i, name := range pks {
 		if i != 0 {
 			sb.WriteString(`, `)
 		}
 		sb.WriteString(`n.`)
-		sb.WriteString(name)
+		sb.WriteString(quoteName(name))
 	}
 	sb.WriteString(`);`)
 
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+// relationship properties, used to remove stale relationships
+const relationshipProperties = "r._cq_source_name = row._cq_source_name, r._cq_sync_time = row._cq_sync_time"
+
+// parentRelationshipType returns the type of the relationships from the nodes of the parent table to the nodes of the child table
+func parentRelationshipType(child string) string {
+	return "HAS_" + strings.ToUpper(child)
+}
+
+// setChildren records the child tables of the tables being migrated or written
+func (c *Client) setChildren(tables schema.Tables) {
+	c.childrenLock.Lock()
+	defer c.childrenLock.Unlock()
+	for _, table := range tables {
+		if table.Parent == nil || table.Column(schema.CqParentIDColumn.Name) == nil {
+			continue
+		}
+		children := c.children[table.Parent.Name]
+		if idx := sort.SearchStrings(children, table.Name); idx < len(children) && children[idx] == table.Name {
+			continue
+		}
+		children = append(children, table.Name)
+		sort.Strings(children)
+		c.children[table.Parent.Name] = children
+	}
+}
+
+func (c *Client) getChildren(table string) []string {
+	c.childrenLock.RLock()
+	defer c.childrenLock.RUnlock()
+	return c.children[table]
+}
+
+// relationshipClauses returns the subqueries creating the relationships of the node `t` written from `row`
+func relationshipClauses(table *schema.Table, children []string, relationships []Relationship) []string {
+	var clauses []string
+	if table.Parent != nil && table.Column(schema.CqParentIDColumn.Name) != nil {
+		clauses = append(clauses, relationshipClause(
+			"MATCH (p:"+quoteName(table.Parent.Name)+" {_cq_id: row._cq_parent_id})",
+			"(p)-[r:"+quoteName(parentRelationshipType(table.Name))+"]->(t)",
+		))
+	}
+	for _, child := range children {
+		// child nodes may have been written before the parent ones
+		clauses = append(clauses, relationshipClause(
+			"MATCH (c:"+quoteName(child)+" {_cq_parent_id: row._cq_id})",
+			"(t)-[r:"+quoteName(parentRelationshipType(child))+"]->(c)",
+		))
+	}
+	for _, rel := range relationships {
+		fromTable, fromColumn := rel.FromColumn()
+		toTable, toColumn := rel.ToColumn()
+		if fromTable == table.Name && table.Column(fromColumn) != nil {
+			clauses = append(clauses, relationshipClause(
+				"MATCH (e:"+quoteName(toTable)+" {"+quoteName(toColumn)+": row."+quoteName(fromColumn)+"})",
+				"(t)-[r:"+quoteName(rel.Type)+"]->(e)",
+			))
+		}
+		if toTable == table.Name && table.Column(toColumn) != nil {
+			clauses = append(clauses, relationshipClause(
+				"MATCH (s:"+quoteName(fromTable)+" {"+quoteName(fromColumn)+": row."+quoteName(toColumn)+"})",
+				"(s)-[r:"+quoteName(rel.Type)+"]->(t)",
+			))
+		}
+	}
+	return clauses
+}
+
+// relationshipClause returns a unit subquery, so that rows without a matching node aren't discarded for the following clauses
+func relationshipClause(match, pattern string) string {
+	return "CALL { WITH t, row " + match + " MERGE " + pattern + " SET " + relationshipProperties + " }"
+}
+
+type relationshipIndex struct {
+	table  string
+	column string
+}
+
+func (i relationshipIndex) name() string {
+	return "_cq_rel_index_" + i.table + "_" + i.column
+}
+
+func (i relationshipIndex) query() string {
+	return "CREATE INDEX " + quoteName(i.name()) + " IF NOT EXISTS FOR (n:" + quoteName(i.table) + ") ON (n." + quoteName(i.column) + ");"
+}
+
+// relationshipIndexes returns the indexes on the properties matched when creating the relationships of the table nodes
+func relationshipIndexes(table *schema.Table, relationships []Relationship) []relationshipIndex {
+	var indexes []relationshipIndex
+	if table.Parent != nil && table.Column(schema.CqParentIDColumn.Name) != nil {
+		indexes = append(indexes,
+			relationshipIndex{table: table.Parent.Name, column: schema.CqIDColumn.Name},
+			relationshipIndex{table: table.Name, column: schema.CqParentIDColumn.Name},
+		)
+	}
+	for _, rel := range relationships {
+		fromTable, fromColumn := rel.FromColumn()
+		toTable, toColumn := rel.ToColumn()
+		if fromTable == table.Name && table.Column(fromColumn) != nil {
+			indexes = append(indexes, relationshipIndex{table: fromTable, column: fromColumn})
+		}
+		if toTable == table.Name && table.Column(toColumn) != nil {
+			indexes = append(indexes, relationshipIndex{table: toTable, column: toColumn})
+		}
+	}
+	return indexes
+}
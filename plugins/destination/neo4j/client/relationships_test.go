@@ -0,0 +1,95 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCypher(t *testing.T) {
+	nodes := &schema.Table{
+		Name: "k8s_core_nodes",
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			{Name: "name", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+		},
+	}
+	pods := &schema.Table{
+		Name: "k8s_core_pods",
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			{Name: "uid", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+			{Name: "node_name", Type: arrow.BinaryTypes.String},
+		},
+	}
+	containers := &schema.Table{
+		Name:   "k8s_core_pod_containers",
+		Parent: pods,
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			schema.CqParentIDColumn,
+			{Name: "name", Type: arrow.BinaryTypes.String},
+		},
+	}
+	relationships := []Relationship{{From: "k8s_core_pods.node_name", To: "k8s_core_nodes.name", Type: "RUNS_ON"}}
+
+	c := &Client{children: make(map[string][]string)}
+	c.setChildren(schema.Tables{nodes, pods, containers, containers})
+	require.Equal(t, map[string][]string{"k8s_core_pods": {"k8s_core_pod_containers"}}, c.children)
+
+	require.Equal(t,
+		"UNWIND $rows AS row MERGE (t:`k8s_core_nodes` {`name`: row.`name`}) SET t = row"+
+			" CALL { WITH t, row MATCH (s:`k8s_core_pods` {`node_name`: row.`name`}) MERGE (s)-[r:`RUNS_ON`]->(t) SET r._cq_source_name = row._cq_source_name, r._cq_sync_time = row._cq_sync_time }",
+		writeCypher(nodes, c.getChildren(nodes.Name), relationships),
+	)
+	require.Equal(t,
+		"UNWIND $rows AS row MERGE (t:`k8s_core_pods` {`uid`: row.`uid`}) SET t = row"+
+			" CALL { WITH t, row MATCH (c:`k8s_core_pod_containers` {_cq_parent_id: row._cq_id}) MERGE (t)-[r:`HAS_K8S_CORE_POD_CONTAINERS`]->(c) SET r._cq_source_name = row._cq_source_name, r._cq_sync_time = row._cq_sync_time }"+
+			" CALL { WITH t, row MATCH (e:`k8s_core_nodes` {`name`: row.`node_name`}) MERGE (t)-[r:`RUNS_ON`]->(e) SET r._cq_source_name = row._cq_source_name, r._cq_sync_time = row._cq_sync_time }",
+		writeCypher(pods, c.getChildren(pods.Name), relationships),
+	)
+	require.Equal(t,
+		"UNWIND $rows AS row CREATE (t:`k8s_core_pod_containers`) SET t = row"+
+			" CALL { WITH t, row MATCH (p:`k8s_core_pods` {_cq_id: row._cq_parent_id}) MERGE (p)-[r:`HAS_K8S_CORE_POD_CONTAINERS`]->(t) SET r._cq_source_name = row._cq_source_name, r._cq_sync_time = row._cq_sync_time }",
+		writeCypher(containers, c.getChildren(containers.Name), relationships),
+	)
+
+	// the tables of the written records keep their parent, so the child tables are recorded without migrations too
+	written, err := schema.NewTableFromArrowSchema(containers.ToArrowSchema())
+	require.NoError(t, err)
+	c = &Client{children: make(map[string][]string)}
+	c.setChildren(schema.Tables{written})
+	require.Equal(t, []string{"k8s_core_pod_containers"}, c.getChildren(pods.Name))
+}
+
+func TestRelationshipIndexes(t *testing.T) {
+	pods := &schema.Table{
+		Name: "k8s_core_pods",
+		Columns: schema.ColumnList{
+			schema.CqIDColumn,
+			{Name: "node_name", Type: arrow.BinaryTypes.String},
+		},
+	}
+	containers := &schema.Table{
+		Name:    "k8s_core_pod_containers",
+		Parent:  pods,
+		Columns: schema.ColumnList{schema.CqIDColumn, schema.CqParentIDColumn},
+	}
+	relationships := []Relationship{{From: "k8s_core_pods.node_name", To: "k8s_core_nodes.name", Type: "RUNS_ON"}}
+
+	require.Equal(t, []relationshipIndex{{table: "k8s_core_pods", column: "node_name"}}, relationshipIndexes(pods, relationships))
+	indexes := relationshipIndexes(containers, relationships)
+	require.Equal(t, []relationshipIndex{
+		{table: "k8s_core_pods", column: "_cq_id"},
+		{table: "k8s_core_pod_containers", column: "_cq_parent_id"},
+	}, indexes)
+	require.Equal(t, "CREATE INDEX `_cq_rel_index_k8s_core_pods__cq_id` IF NOT EXISTS FOR (n:`k8s_core_pods`) ON (n.`_cq_id`);", indexes[0].query())
+}
+
+func TestQuoteName(t *testing.T) {
+	require.Equal(t, "`k8s_core_pods`", quoteName("k8s_core_pods"))
+	require.Equal(t, "`my-table`", quoteName("my-table"))
+	require.Equal(t, "`a``b`", quoteName("a`b"))
+}
@@ -3,6 +3,8 @@ package client
 import (
 	_ "embed"
 	"fmt"
+	"regexp"
+	"strings"
 )
 
 const (
@@ -25,6 +27,50 @@ type Spec struct {
 
 	// Number of bytes (as Arrow buffer size) to batch together before sending to the database.
 	BatchSizeBytes int `json:"batch_size_bytes" jsonschema:"minimum=1,default=4194304"`
+
+	// Relationships to create between nodes by matching column values, in addition to the `HAS_<CHILD_TABLE>` relationships
+	// created between the nodes of parent and child tables.
+	Relationships []Relationship `json:"relationships,omitempty"`
+}
+
+// Relationship maps a pair of columns to a relationship type.
+// A relationship is created from each node of the `from` table to each node of the `to` table with the same column value.
+type Relationship struct {
+	// Table and column of the start nodes of the relationship, in `table.column` format, for example `k8s_core_pods.node_name`.
+	From string `json:"from" jsonschema:"required,pattern=^[^.]+\\.[^.]+$"`
+
+	// Table and column of the end nodes of the relationship, in `table.column` format, for example `k8s_core_nodes.name`.
+	To string `json:"to" jsonschema:"required,pattern=^[^.]+\\.[^.]+$"`
+
+	// Type of the relationship, for example `RUNS_ON`.
+	Type string `json:"type" jsonschema:"required,pattern=^[A-Za-z_][A-Za-z0-9_]*$"`
+}
+
+var relationshipTypeRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// FromColumn returns the table and column of the start nodes
+func (r Relationship) FromColumn() (table, column string) {
+	table, column, _ = strings.Cut(r.From, ".")
+	return table, column
+}
+
+// ToColumn returns the table and column of the end nodes
+func (r Relationship) ToColumn() (table, column string) {
+	table, column, _ = strings.Cut(r.To, ".")
+	return table, column
+}
+
+func (r Relationship) Validate() error {
+	for _, tableColumn := range []string{r.From, r.To} {
+		table, column, ok := strings.Cut(tableColumn, ".")
+		if !ok || table == "" || column == "" || strings.Contains(column, ".") {
+			return fmt.Errorf("invalid relationship column %q, expected \"table.column\" format", tableColumn)
+		}
+	}
+	if !relationshipTypeRegex.MatchString(r.Type) {
+		return fmt.Errorf("invalid relationship type %q", r.Type)
+	}
+	return nil
 }
 
 //go:embed schema.json
@@ -43,5 +89,10 @@ func (s *Spec) Validate() error {
 	if s.ConnectionString == "" {
 		return fmt.Errorf("connection_string is required")
 	}
+	for _, r := range s.Relationships {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
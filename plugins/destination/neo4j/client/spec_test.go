@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/cloudquery/codegen/jsonschema"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJSONSchema(t *testing.T) {
@@ -57,6 +58,39 @@ func TestJSONSchema(t *testing.T) {
 			Spec: `{"connection_string": "abc", "unknown": "test", "username": "user", "password": "pass"}`,
 			Err:  true,
 		},
+		{
+			Name: "spec with relationships",
+			Spec: `{"connection_string": "abc", "username": "user", "password": "pass", "relationships": [{"from": "k8s_core_pods.node_name", "to": "k8s_core_nodes.name", "type": "RUNS_ON"}]}`,
+		},
+		{
+			Name: "spec with null relationships",
+			Spec: `{"connection_string": "abc", "username": "user", "password": "pass", "relationships": null}`,
+		},
+		{
+			Name: "spec with relationship missing type",
+			Spec: `{"connection_string": "abc", "username": "user", "password": "pass", "relationships": [{"from": "k8s_core_pods.node_name", "to": "k8s_core_nodes.name"}]}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with relationship from without column",
+			Spec: `{"connection_string": "abc", "username": "user", "password": "pass", "relationships": [{"from": "k8s_core_pods", "to": "k8s_core_nodes.name", "type": "RUNS_ON"}]}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with relationship to with nested column",
+			Spec: `{"connection_string": "abc", "username": "user", "password": "pass", "relationships": [{"from": "k8s_core_pods.node_name", "to": "k8s_core_nodes.name.first", "type": "RUNS_ON"}]}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with invalid relationship type",
+			Spec: `{"connection_string": "abc", "username": "user", "password": "pass", "relationships": [{"from": "k8s_core_pods.node_name", "to": "k8s_core_nodes.name", "type": "RUNS ON"}]}`,
+			Err:  true,
+		},
+		{
+			Name: "spec with unknown relationship field",
+			Spec: `{"connection_string": "abc", "username": "user", "password": "pass", "relationships": [{"from": "k8s_core_pods.node_name", "to": "k8s_core_nodes.name", "type": "RUNS_ON", "unknown": true}]}`,
+			Err:  true,
+		},
 		{
 			Name: "spec with missing username",
 			Spec: `{"connection_string": "abc", "password": "pass"}`,
@@ -69,3 +103,17 @@ func TestJSONSchema(t *testing.T) {
 		},
 	})
 }
+
+func TestRelationshipValidate(t *testing.T) {
+	require.NoError(t, Relationship{From: "a.b", To: "c.d", Type: "HAS"}.Validate())
+	require.ErrorContains(t, Relationship{From: "a", To: "c.d", Type: "HAS"}.Validate(), `invalid relationship column "a"`)
+	require.ErrorContains(t, Relationship{From: "a.b", To: ".d", Type: "HAS"}.Validate(), `invalid relationship column ".d"`)
+	require.ErrorContains(t, Relationship{From: "a.b", To: "c.d", Type: "1HAS"}.Validate(), `invalid relationship type "1HAS"`)
+}
+
+func TestSpecValidateRelationships(t *testing.T) {
+	s := Spec{ConnectionString: "abc", Relationships: []Relationship{{From: "a.b", To: "c.d", Type: "HAS"}}}
+	require.NoError(t, s.Validate())
+	s.Relationships = append(s.Relationships, Relationship{From: "a.b", To: "c.d", Type: "HAS`]->(x) DETACH DELETE x //"})
+	require.ErrorContains(t, s.Validate(), "invalid relationship type")
+}
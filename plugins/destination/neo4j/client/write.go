@@ -19,6 +19,8 @@ func (c *Client) WriteTableBatch(ctx context.Context, tableName string, msgs mes
 	if err != nil {
 		return err
 	}
+	// tables aren't migrated with `--no-migrate`, so child tables are also recorded when their nodes are written
+	c.setChildren(schema.Tables{table})
 
 	session := c.Session(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
 	defer session.Close(ctx)
@@ -28,36 +30,50 @@ func (c *Client) WriteTableBatch(ctx context.Context, tableName string, msgs mes
 		rows = append(rows, transformValues(msgs[i].Record)...)
 	}
 
+	stmt := writeCypher(table, c.getChildren(tableName), c.spec.Relationships)
+	c.logger.Debug().Str("stmt", stmt).Any("rows", rows).Msg("Executing statement")
+	if _, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		_, err := tx.Run(ctx, stmt, map[string]any{"rows": rows})
+		return nil, err
+	}); err != nil {
+		return err
+	}
+
+	return session.Close(ctx)
+}
+
+func writeCypher(table *schema.Table, children []string, relationships []Relationship) string {
 	var sb strings.Builder
 	pks := table.PrimaryKeys()
 	if len(pks) == 0 {
 		sb.WriteString("UNWIND $rows AS row CREATE (t:")
-		sb.WriteString(tableName)
+		sb.WriteString(quoteName(table.Name))
 		sb.WriteString(") SET t = row")
 	} else {
 		sb.WriteString("UNWIND $rows AS row MERGE (t:")
-		sb.WriteString(tableName)
+		sb.WriteString(quoteName(table.Name))
 		sb.WriteString(" {")
 		for i, column := range pks {
 			if i != 0 {
 				sb.WriteString(", ")
 			}
-			sb.WriteString(column)
+			sb.WriteString(quoteName(column))
 			sb.WriteString(": row.")
-			sb.WriteString(column)
+			sb.WriteString(quoteName(column))
 		}
 		sb.WriteString("}) SET t = row")
 	}
-	stmt := sb.String()
-	c.logger.Debug().Str("stmt", stmt).Any("rows", rows).Msg("Executing statement")
-	if _, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		_, err := tx.Run(ctx, stmt, map[string]any{"rows": rows})
-		return nil, err
-	}); err != nil {
-		return err
+	for _, clause := range relationshipClauses(table, children, relationships) {
+		sb.WriteString(" ")
+		sb.WriteString(clause)
 	}
+	return sb.String()
+}
 
-	return session.Close(ctx)
+// quoteName escapes a label, relationship type, property or index name with backticks,
+// so that names with characters other than letters, digits and underscores can be used.
+func quoteName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
 }
 
 func (c *Client) Write(ctx context.Context, msgs <-chan message.WriteMessage) error {
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/jackc/pgx/v5"
+)
+
+// inPlaceTypeChanges lists the PostgreSQL column types that can be changed in place with `ALTER COLUMN ... TYPE ... USING`,
+// and the types they can be changed to. Any type can also be changed to `text`.
+var inPlaceTypeChanges = map[string][]string{
+	"smallint": {"int", "bigint", "numeric(20,0)"},
+	"int":      {"bigint", "numeric(20,0)"},
+	"bigint":   {"numeric(20,0)"},
+	"real":     {"double precision"},
+	"date":     {"timestamp without time zone"},
+	// the values have to be valid JSON, otherwise the migration fails
+	"text": {"jsonb"},
+}
+
+// canAlterColumn reports whether the column update can be done in place, without recreating the table:
+// either a type change listed in inPlaceTypeChanges, or adding or removing the NOT NULL constraint.
+// Changes of the primary key are not supported.
+func (c *Client) canAlterColumn(change schema.TableColumnChange) bool {
+	if change.Type != schema.TableColumnChangeTypeUpdate || c.pgType == pgTypeCrateDB {
+		return false
+	}
+	if change.Current.PrimaryKey != change.Previous.PrimaryKey {
+		return false
+	}
+	previousType, currentType := c.SchemaTypeToPg(change.Previous.Type), c.SchemaTypeToPg(change.Current.Type)
+	if previousType == currentType {
+		return true
+	}
+	// CockroachDB only supports changing column types with experimental features enabled
+	if c.pgType != pgTypePostgreSQL {
+		return false
+	}
+	return currentType == "text" || slices.Contains(inPlaceTypeChanges[previousType], currentType)
+}
+
+// alterColumnSQL returns the statement applying the column update checked with canAlterColumn.
+func (c *Client) alterColumnSQL(tableName string, change schema.TableColumnChange) string {
+	columnName := pgx.Identifier{change.ColumnName}.Sanitize()
+	sql := "alter table " + pgx.Identifier{tableName}.Sanitize()
+	var actions []string
+	if previousType, currentType := c.SchemaTypeToPg(change.Previous.Type), c.SchemaTypeToPg(change.Current.Type); previousType != currentType {
+		actions = append(actions, " alter column "+columnName+" type "+currentType+" using "+columnName+"::"+currentType)
+	}
+	switch {
+	case change.Current.NotNull && !change.Previous.NotNull:
+		actions = append(actions, " alter column "+columnName+" set not null")
+	case !change.Current.NotNull && change.Previous.NotNull:
+		actions = append(actions, " alter column "+columnName+" drop not null")
+	}
+	for i, action := range actions {
+		if i > 0 {
+			sql += ","
+		}
+		sql += action
+	}
+	return sql
+}
+
+func (c *Client) alterColumn(ctx context.Context, tableName string, change schema.TableColumnChange) error {
+	c.logger.Info().Str("table", tableName).Str("column", change.ColumnName).
+		Str("from", c.SchemaTypeToPg(change.Previous.Type)).Str("to", c.SchemaTypeToPg(change.Current.Type)).
+		Bool("not_null", change.Current.NotNull).Msg("Column changed, altering")
+	sql := c.alterColumnSQL(tableName, change)
+	if _, err := c.conn.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("failed to alter column %s on table %s: %w", change.ColumnName, tableName, err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+)
+
+func updateChange(previous, current schema.Column) schema.TableColumnChange {
+	return schema.TableColumnChange{
+		Type:       schema.TableColumnChangeTypeUpdate,
+		ColumnName: current.Name,
+		Current:    current,
+		Previous:   previous,
+	}
+}
+
+func TestCanAlterColumn(t *testing.T) {
+	tests := []struct {
+		name     string
+		pgType   pgType
+		previous schema.Column
+		current  schema.Column
+		want     bool
+	}{
+		{
+			name:     "int32 to int64",
+			pgType:   pgTypePostgreSQL,
+			previous: schema.Column{Name: "c", Type: arrow.PrimitiveTypes.Int32},
+			current:  schema.Column{Name: "c", Type: arrow.PrimitiveTypes.Int64},
+			want:     true,
+		},
+		{
+			name:     "int64 to int32",
+			pgType:   pgTypePostgreSQL,
+			previous: schema.Column{Name: "c", Type: arrow.PrimitiveTypes.Int64},
+			current:  schema.Column{Name: "c", Type: arrow.PrimitiveTypes.Int32},
+			want:     false,
+		},
+		{
+			name:     "text to jsonb",
+			pgType:   pgTypePostgreSQL,
+			previous: schema.Column{Name: "c", Type: arrow.BinaryTypes.String},
+			current:  schema.Column{Name: "c", Type: types.ExtensionTypes.JSON},
+			want:     true,
+		},
+		{
+			name:     "bool to text",
+			pgType:   pgTypePostgreSQL,
+			previous: schema.Column{Name: "c", Type: arrow.FixedWidthTypes.Boolean},
+			current:  schema.Column{Name: "c", Type: arrow.BinaryTypes.String},
+			want:     true,
+		},
+		{
+			name:     "text to uuid",
+			pgType:   pgTypePostgreSQL,
+			previous: schema.Column{Name: "c", Type: arrow.BinaryTypes.String},
+			current:  schema.Column{Name: "c", Type: types.ExtensionTypes.UUID},
+			want:     false,
+		},
+		{
+			name:     "add not null",
+			pgType:   pgTypePostgreSQL,
+			previous: schema.Column{Name: "c", Type: arrow.BinaryTypes.String},
+			current:  schema.Column{Name: "c", Type: arrow.BinaryTypes.String, NotNull: true},
+			want:     true,
+		},
+		{
+			name:     "remove not null in CockroachDB",
+			pgType:   pgTypeCockroachDB,
+			previous: schema.Column{Name: "c", Type: arrow.BinaryTypes.String, NotNull: true},
+			current:  schema.Column{Name: "c", Type: arrow.BinaryTypes.String},
+			want:     true,
+		},
+		{
+			name:     "int16 to int64 in CockroachDB",
+			pgType:   pgTypeCockroachDB,
+			previous: schema.Column{Name: "c", Type: arrow.PrimitiveTypes.Int16},
+			current:  schema.Column{Name: "c", Type: arrow.PrimitiveTypes.Int64},
+			want:     false,
+		},
+		{
+			name:     "remove not null in CrateDB",
+			pgType:   pgTypeCrateDB,
+			previous: schema.Column{Name: "c", Type: arrow.BinaryTypes.String, NotNull: true},
+			current:  schema.Column{Name: "c", Type: arrow.BinaryTypes.String},
+			want:     false,
+		},
+		{
+			name:     "add primary key",
+			pgType:   pgTypePostgreSQL,
+			previous: schema.Column{Name: "c", Type: arrow.BinaryTypes.String},
+			current:  schema.Column{Name: "c", Type: arrow.BinaryTypes.String, PrimaryKey: true, NotNull: true},
+			want:     false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Client{pgType: tc.pgType}
+			if got := c.canAlterColumn(updateChange(tc.previous, tc.current)); got != tc.want {
+				t.Errorf("canAlterColumn() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanAutoMigrateColumnUpdates(t *testing.T) {
+	c := &Client{pgType: pgTypePostgreSQL}
+	changes := []schema.TableColumnChange{
+		updateChange(schema.Column{Name: "a", Type: arrow.PrimitiveTypes.Int32}, schema.Column{Name: "a", Type: arrow.PrimitiveTypes.Int64}),
+		updateChange(schema.Column{Name: "b", Type: arrow.BinaryTypes.String, NotNull: true}, schema.Column{Name: "b", Type: arrow.BinaryTypes.String}),
+	}
+	if !c.canAutoMigrate(changes) {
+		t.Error("canAutoMigrate() = false, want true")
+	}
+	changes = append(changes, updateChange(schema.Column{Name: "c", Type: arrow.BinaryTypes.String}, schema.Column{Name: "c", Type: arrow.PrimitiveTypes.Int64}))
+	if c.canAutoMigrate(changes) {
+		t.Error("canAutoMigrate() = true, want false")
+	}
+}
+
+func TestAlterColumnSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous schema.Column
+		current  schema.Column
+		want     string
+	}{
+		{
+			name:     "type",
+			previous: schema.Column{Name: "c", Type: arrow.PrimitiveTypes.Int32},
+			current:  schema.Column{Name: "c", Type: arrow.PrimitiveTypes.Int64},
+			want:     `alter table "t" alter column "c" type bigint using "c"::bigint`,
+		},
+		{
+			name:     "type and not null",
+			previous: schema.Column{Name: "c", Type: arrow.BinaryTypes.String},
+			current:  schema.Column{Name: "c", Type: types.ExtensionTypes.JSON, NotNull: true},
+			want:     `alter table "t" alter column "c" type jsonb using "c"::jsonb, alter column "c" set not null`,
+		},
+		{
+			name:     "drop not null",
+			previous: schema.Column{Name: "c", Type: arrow.BinaryTypes.String, NotNull: true},
+			current:  schema.Column{Name: "c", Type: arrow.BinaryTypes.String},
+			want:     `alter table "t" alter column "c" drop not null`,
+		},
+	}
+	c := &Client{pgType: pgTypePostgreSQL}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := c.alterColumnSQL("t", updateChange(tc.previous, tc.current)); got != tc.want {
+				t.Errorf("alterColumnSQL() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -22,6 +22,8 @@ import (
 type pkConstraintDetails struct {
 	name    string
 	columns []string
+	// partitioned is true for the tables partitioned by `_cq_sync_time`
+	partitioned bool
 }
 
 type Client struct {
@@ -33,9 +35,17 @@ type Client struct {
 	batchSize           int
 	writer              *mixedbatchwriter.MixedBatchWriter
 
+	spec                 *spec.Spec
+	useCopy              bool
+	shadowTableMigration bool
+
 	pgTablesToPKConstraints   map[string]*pkConstraintDetails
 	pgTablesToPKConstraintsMu sync.RWMutex
 
+	// partitions holds the names of the partitions created by the write path, so that they're only created once
+	partitions   map[string]struct{}
+	partitionsMu sync.Mutex
+
 	plugin.UnimplementedSource
 }
 
@@ -68,6 +78,7 @@ func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, opts plug
 		return nil, err
 	}
 
+	c.spec = &s
 	c.batchSize = s.BatchSize
 	c.logger.Info().Str("pgx_log_level", s.PgxLogLevel.String()).Msg("Initializing postgresql destination")
 	pgxConfig, err := pgxpool.ParseConfig(s.ConnectionString)
@@ -101,6 +112,20 @@ func New(ctx context.Context, logger zerolog.Logger, specBytes []byte, opts plug
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database type: %w", err)
 	}
+	if s.WriteMethod == spec.WriteMethodCopy {
+		if c.pgType == pgTypePostgreSQL {
+			c.useCopy = true
+		} else {
+			c.logger.Warn().Msg("write_method copy is only supported by PostgreSQL, falling back to insert")
+		}
+	}
+	if s.ForcedMigrationStrategy == spec.ForcedMigrationStrategyShadowTable {
+		if c.pgType == pgTypePostgreSQL {
+			c.shadowTableMigration = true
+		} else {
+			c.logger.Warn().Msg("forced_migration_strategy shadow_table is only supported by PostgreSQL, falling back to drop")
+		}
+	}
 	c.writer, err = mixedbatchwriter.New(c,
 		mixedbatchwriter.WithLogger(c.logger),
 		mixedbatchwriter.WithBatchSize(s.BatchSize),
@@ -26,16 +26,26 @@ var safeMigrations = plugin.SafeMigrations{
 	AddColumnNotNull:       false,
 	RemoveColumn:           true,
 	RemoveColumnNotNull:    false,
+	ChangeColumn:           true,
 	RemoveUniqueConstraint: true,
 	MovePKToCQOnly:         true,
 }
 
 func TestPgPlugin(t *testing.T) {
+	testPgPlugin(t, spec.WriteMethodInsert)
+}
+
+func TestPgPluginCopy(t *testing.T) {
+	testPgPlugin(t, spec.WriteMethodCopy)
+}
+
+func testPgPlugin(t *testing.T, writeMethod string) {
 	ctx := context.Background()
 	p := plugin.NewPlugin("postgresql", "development", New)
 	s := &spec.Spec{
 		ConnectionString: getTestConnection(),
 		PgxLogLevel:      spec.LogLevel(tracelog.LogLevelTrace),
+		WriteMethod:      writeMethod,
 	}
 	b, err := json.Marshal(s)
 	if err != nil {
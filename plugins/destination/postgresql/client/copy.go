@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/jackc/pgx/v5"
+)
+
+const copyStagingTablePrefix = "_cq_copy_"
+
+// copyBatch writes the records with `COPY FROM STDIN`, one table at a time.
+func (c *Client) copyBatch(ctx context.Context, messages message.WriteInserts) error {
+	pgTables, err := c.pgTables(ctx)
+	if err != nil {
+		return err
+	}
+
+	var tableNames []string
+	tables := make(map[string]*schema.Table)
+	records := make(map[string][]arrow.Record)
+	for _, msg := range messages {
+		tableName := msg.GetTable().Name
+		if _, ok := pgTables[tableName]; !ok {
+			return fmt.Errorf("table %s not found", tableName)
+		}
+		if _, ok := tables[tableName]; !ok {
+			tableNames = append(tableNames, tableName)
+			tables[tableName] = c.normalizeTable(msg.GetTable())
+		}
+		records[tableName] = append(records[tableName], msg.Record)
+	}
+
+	for _, tableName := range tableNames {
+		table := tables[tableName]
+		if len(table.PrimaryKeysIndexes()) > 0 {
+			err = c.copyUpsert(ctx, table, records[tableName])
+		} else {
+			err = c.copyInsert(ctx, table, records[tableName])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyInsert copies the records directly into the table.
+func (c *Client) copyInsert(ctx context.Context, table *schema.Table, records []arrow.Record) error {
+	_, err := c.conn.CopyFrom(ctx, pgx.Identifier{table.Name}, table.Columns.Names(), c.newCopySource(records))
+	if err != nil {
+		return wrapPgErr("failed to copy into table "+table.Name, err)
+	}
+	return nil
+}
+
+// copyUpsert copies the records into a temporary table and upserts them into the table from there.
+// Both are done in a single transaction, at the end of which the temporary table is dropped.
+func (c *Client) copyUpsert(ctx context.Context, table *schema.Table, records []arrow.Record) error {
+	stagingTable := copyStagingTableName(table.Name)
+	return pgx.BeginFunc(ctx, c.conn, func(tx pgx.Tx) error {
+		sql := createCopyStagingTable(table.Name, stagingTable)
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return wrapPgErr("failed to create staging table "+stagingTable, err)
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{stagingTable}, table.Columns.Names(), c.newCopySource(records)); err != nil {
+			return wrapPgErr("failed to copy into staging table "+stagingTable, err)
+		}
+		if _, err := tx.Exec(ctx, upsertFromStagingTable(table, stagingTable)); err != nil {
+			return wrapPgErr("failed to upsert into table "+table.Name, err)
+		}
+		return nil
+	})
+}
+
+func copyStagingTableName(tableName string) string {
+	name := copyStagingTablePrefix + tableName
+	// Postgres truncates identifiers longer than 63 characters
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+func createCopyStagingTable(tableName, stagingTable string) string {
+	return "create temporary table " + pgx.Identifier{stagingTable}.Sanitize() +
+		" (like " + pgx.Identifier{tableName}.Sanitize() + " including defaults) on commit drop"
+}
+
+// upsertFromStagingTable returns the query upserting the staged rows into the table.
+// A batch may hold several rows with the same primary key, and a single `INSERT ... ON CONFLICT` statement
+// can't update the same row twice, so only the last staged row is kept for each primary key.
+func upsertFromStagingTable(table *schema.Table, stagingTable string) string {
+	columns := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		columns[i] = pgx.Identifier{col.Name}.Sanitize()
+	}
+	pks := table.PrimaryKeys()
+	for i, name := range pks {
+		pks[i] = pgx.Identifier{name}.Sanitize()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("insert into ")
+	sb.WriteString(pgx.Identifier{table.Name}.Sanitize())
+	sb.WriteString(" (")
+	sb.WriteString(strings.Join(columns, ","))
+	sb.WriteString(") select distinct on (")
+	sb.WriteString(strings.Join(pks, ","))
+	sb.WriteString(") ")
+	sb.WriteString(strings.Join(columns, ","))
+	sb.WriteString(" from ")
+	sb.WriteString(pgx.Identifier{stagingTable}.Sanitize())
+	// the rows of the temporary table are stored in the order they were copied in
+	sb.WriteString(" order by ")
+	sb.WriteString(strings.Join(pks, ","))
+	sb.WriteString(",ctid desc")
+	sb.WriteString(onConflictDoUpdate(table))
+	return sb.String()
+}
+
+// copySource streams the rows of the records to `COPY FROM STDIN`,
+// converting a single record at a time.
+type copySource struct {
+	c       *Client
+	records []arrow.Record
+	rows    [][]any
+	row     int
+}
+
+var _ pgx.CopyFromSource = (*copySource)(nil)
+
+func (c *Client) newCopySource(records []arrow.Record) *copySource {
+	return &copySource{c: c, records: records}
+}
+
+func (s *copySource) Next() bool {
+	s.row++
+	for s.row >= len(s.rows) {
+		if len(s.records) == 0 {
+			s.rows = nil
+			return false
+		}
+		s.rows = s.c.transformValues(s.records[0])
+		s.records = s.records[1:]
+		s.row = 0
+	}
+	return true
+}
+
+func (s *copySource) Values() ([]any, error) {
+	return s.rows[s.row], nil
+}
+
+func (*copySource) Err() error {
+	return nil
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestUpsertFromStagingTable(t *testing.T) {
+	table := &schema.Table{
+		Name:             "test_table",
+		PkConstraintName: "test_table_cqpk",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true},
+			{Name: "region", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+			{Name: "name", Type: arrow.BinaryTypes.String},
+		},
+	}
+	want := `insert into "test_table" ("id","region","name") select distinct on ("id","region") "id","region","name" from "_cq_copy_test_table"` +
+		` order by "id","region",ctid desc` +
+		` on conflict on constraint "test_table_cqpk" do update set "id"=excluded."id","region"=excluded."region","name"=excluded."name"`
+	if diff := cmp.Diff(want, upsertFromStagingTable(table, copyStagingTableName(table.Name))); diff != "" {
+		t.Errorf("upsertFromStagingTable() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCreateCopyStagingTable(t *testing.T) {
+	want := `create temporary table "_cq_copy_test_table" (like "test_table" including defaults) on commit drop`
+	if got := createCopyStagingTable("test_table", copyStagingTableName("test_table")); got != want {
+		t.Errorf("createCopyStagingTable() = %s, want %s", got, want)
+	}
+}
+
+func TestCopyStagingTableName(t *testing.T) {
+	long := "a_very_long_table_name_that_is_already_close_to_the_limit_of_63"
+	if got := copyStagingTableName(long); len(got) != 63 {
+		t.Errorf("copyStagingTableName() = %s, want 63 characters", got)
+	}
+}
+
+func TestCopySource(t *testing.T) {
+	sc := arrow.NewSchema([]arrow.Field{{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true}}, nil)
+	newRecord := func(values ...int64) arrow.Record {
+		bldr := array.NewRecordBuilder(memory.DefaultAllocator, sc)
+		defer bldr.Release()
+		bldr.Field(0).(*array.Int64Builder).AppendValues(values, nil)
+		return bldr.NewRecord()
+	}
+	records := []arrow.Record{newRecord(1, 2), newRecord(), newRecord(3)}
+
+	src := (&Client{}).newCopySource(records)
+	var got []any
+	for src.Next() {
+		values, err := src.Values()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, values...)
+	}
+	if err := src.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []any{
+		pgtype.Int8{Int64: 1, Valid: true},
+		pgtype.Int8{Int64: 2, Valid: true},
+		pgtype.Int8{Int64: 3, Valid: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("copySource mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudquery/cloudquery/plugins/destination/postgresql/client/spec"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/jackc/pgx/v5"
+)
+
+func indexName(tableName string, index spec.IndexOptions) string {
+	if len(index.Name) > 0 {
+		return index.Name
+	}
+	name := tableName + "_" + strings.Join(index.Columns, "_") + "_idx"
+	// Postgres truncates identifiers longer than 63 characters
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+func createIndexSQL(tableName string, index spec.IndexOptions) string {
+	columns := make([]string, len(index.Columns))
+	for i, column := range index.Columns {
+		columns[i] = pgx.Identifier{column}.Sanitize()
+	}
+	method := index.Method
+	if len(method) == 0 {
+		method = "btree"
+	}
+	return "create index if not exists " + pgx.Identifier{indexName(tableName, index)}.Sanitize() +
+		" on " + pgx.Identifier{tableName}.Sanitize() + " using " + method + " (" + strings.Join(columns, ",") + ")"
+}
+
+// createIndexes creates the indexes set in the table options, if they don't exist yet.
+func (c *Client) createIndexes(ctx context.Context, table *schema.Table) error {
+	for _, index := range c.tableOptions(table.Name).Indexes {
+		for _, column := range index.Columns {
+			if table.Columns.Get(column) == nil {
+				return fmt.Errorf("failed to create index %s on table %s: column %s not found", indexName(table.Name, index), table.Name, column)
+			}
+		}
+		sql := createIndexSQL(table.Name, index)
+		if _, err := c.conn.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("failed to create index %s on table %s: %w", indexName(table.Name, index), table.Name, err)
+		}
+	}
+	return nil
+}
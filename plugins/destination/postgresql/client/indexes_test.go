@@ -0,0 +1,33 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/cloudquery/cloudquery/plugins/destination/postgresql/client/spec"
+)
+
+func TestCreateIndexSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		index spec.IndexOptions
+		want  string
+	}{
+		{
+			name:  "default name and method",
+			index: spec.IndexOptions{Columns: []string{"account_id", "region"}},
+			want:  `create index if not exists "t_account_id_region_idx" on "t" using btree ("account_id","region")`,
+		},
+		{
+			name:  "gin",
+			index: spec.IndexOptions{Name: "t_tags", Columns: []string{"tags"}, Method: "gin"},
+			want:  `create index if not exists "t_tags" on "t" using gin ("tags")`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := createIndexSQL("t", tc.index); got != tc.want {
+				t.Errorf("createIndexSQL() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
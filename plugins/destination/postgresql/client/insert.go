@@ -36,6 +36,13 @@ func (c *Client) pgTables(ctx context.Context) (map[string]struct{}, error) {
 
 // InsertBatch inserts records into the destination table. It forms part of the writer.MixedBatchWriter interface.
 func (c *Client) InsertBatch(ctx context.Context, messages message.WriteInserts) error {
+	if err := c.createWritePartitions(ctx, messages); err != nil {
+		return err
+	}
+	if c.useCopy {
+		return c.copyBatch(ctx, messages)
+	}
+
 	pgTables, err := c.pgTables(ctx)
 	if err != nil {
 		return err
@@ -95,13 +102,17 @@ func (c *Client) flushBatch(ctx context.Context, batch *pgx.Batch) error {
 		return nil
 	}
 
+	return wrapPgErr("failed to execute batch", err)
+}
+
+func wrapPgErr(msg string, err error) error {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
-		return fmt.Errorf("failed to execute batch with pgerror: %s: %w", pgErrToStr(pgErr), err)
+		return fmt.Errorf("%s with pgerror: %s: %w", msg, pgErrToStr(pgErr), err)
 	}
 
 	// not recoverable error
-	return fmt.Errorf("failed to execute batch: %w", err)
+	return fmt.Errorf("%s: %w", msg, err)
 }
 
 func (*Client) insert(table *schema.Table) string {
@@ -135,8 +146,12 @@ func (c *Client) upsert(table *schema.Table) string {
 	if c.pgType == pgTypeCrateDB {
 		return c.upsertCrateDB(table)
 	}
+	return c.insert(table) + onConflictDoUpdate(table)
+}
+
+// onConflictDoUpdate returns the clause updating all the columns of the rows conflicting on the primary key constraint.
+func onConflictDoUpdate(table *schema.Table) string {
 	var sb strings.Builder
-	sb.WriteString(c.insert(table))
 	columns := table.Columns
 	columnsLen := len(columns)
 	constraintName := table.PkConstraintName
@@ -48,7 +48,8 @@ SELECT
 	CASE 
 		WHEN unique_constraint.conkey IS NOT NULL AND array_position(unique_constraint.conkey, pg_attribute.attnum) > 0 THEN COALESCE(unique_constraint.conname, '')
 		ELSE ''
-	END AS unique_constraint_name
+	END AS unique_constraint_name,
+	pg_class.relkind = 'p' AS is_partitioned
 FROM
 	pg_catalog.pg_attribute
 	INNER JOIN
@@ -79,8 +80,12 @@ func (c *Client) listTables(ctx context.Context) (schema.Tables, error) {
 	tableInfo := map[string]*pkConstraintDetails{}
 	var tables schema.Tables
 	var whereClause string
-	if c.pgType == pgTypeCockroachDB {
+	switch c.pgType {
+	case pgTypeCockroachDB:
 		whereClause = " AND information_schema.columns.is_hidden != 'YES'"
+	case pgTypePostgreSQL:
+		// the partitions of partitioned tables aren't listed as separate tables
+		whereClause = " AND NOT pg_class.relispartition"
 	}
 	q := fmt.Sprintf(selectTables, c.currentSchemaName, whereClause)
 	rows, err := c.conn.Query(ctx, q)
@@ -91,8 +96,8 @@ func (c *Client) listTables(ctx context.Context) (schema.Tables, error) {
 	for rows.Next() {
 		var ordinalPosition int
 		var tableName, columnName, columnType, pkName, uniqueName string
-		var isPrimaryKey, notNull, isUnique bool
-		if err := rows.Scan(&ordinalPosition, &tableName, &columnName, &columnType, &isPrimaryKey, &notNull, &pkName, &isUnique, &uniqueName); err != nil {
+		var isPrimaryKey, notNull, isUnique, isPartitioned bool
+		if err := rows.Scan(&ordinalPosition, &tableName, &columnName, &columnType, &isPrimaryKey, &notNull, &pkName, &isUnique, &uniqueName, &isPartitioned); err != nil {
 			return nil, err
 		}
 		if ordinalPosition == 1 {
@@ -106,7 +111,7 @@ func (c *Client) listTables(ctx context.Context) (schema.Tables, error) {
 		// We always want to record that we saw the table, even if it doesn't have a PK constraint.
 		entry, ok := tableInfo[tableName]
 		if !ok {
-			entry = new(pkConstraintDetails)
+			entry = &pkConstraintDetails{partitioned: isPartitioned}
 			tableInfo[tableName] = entry
 		}
 		if pkName != "" {
@@ -23,6 +23,12 @@ func (c *Client) MigrateTableBatch(ctx context.Context, messages message.WriteMi
 		return fmt.Errorf("failed listing postgres tables: %w", err)
 	}
 	tables = c.normalizeTables(tables)
+	for _, table := range tables {
+		if err := c.checkPartitioning(table); err != nil {
+			return err
+		}
+	}
+	c.resetWritePartitions()
 
 	safeTables := map[string]bool{}
 	for _, msg := range messages {
@@ -55,7 +61,15 @@ func (c *Client) MigrateTableBatch(ctx context.Context, messages message.WriteMi
 				if err := c.autoMigrateTable(ctx, table, changes); err != nil {
 					return err
 				}
+			} else if c.shadowTableMigration && canCopyRows(table, pgTable) {
+				c.logger.Info().Str("table", tableName).Msg("Table exists, force migration required, copying rows to a new table")
+				if err := c.shadowMigrateTable(ctx, table, pgTable); err != nil {
+					return err
+				}
 			} else {
+				if c.shadowTableMigration {
+					c.logger.Warn().Str("table", tableName).Msg("Rows can't be copied to the new table, as it has new NOT NULL columns or no columns in common with the existing table")
+				}
 				c.logger.Info().Str("table", tableName).Msg("Table exists, force migration required")
 				if err := c.dropTable(ctx, tableName); err != nil {
 					return err
@@ -65,6 +79,12 @@ func (c *Client) MigrateTableBatch(ctx context.Context, messages message.WriteMi
 				}
 			}
 		}
+		if err := c.migratePartitions(ctx, table); err != nil {
+			return err
+		}
+		if err := c.createIndexes(ctx, table); err != nil {
+			return err
+		}
 	}
 	conn, err := c.conn.Acquire(ctx)
 	if err != nil {
@@ -81,6 +101,7 @@ func (c *Client) normalizeTable(table *schema.Table) *schema.Table {
 	normalizedTable := schema.Table{
 		Name: table.Name,
 	}
+	partitioned := c.partitioned(table)
 	for _, col := range table.Columns {
 		if c.pgType == pgTypeCrateDB {
 			// CrateDB doesn't allow columns that start with an underscore,
@@ -97,6 +118,11 @@ func (c *Client) normalizeTable(table *schema.Table) *schema.Table {
 			col.Name = col.Name[:63]
 		}
 
+		if partitioned {
+			// The unique constraints of partitioned tables have to include the partitioning column, so they're skipped
+			col.Unique = false
+		}
+
 		if col.PrimaryKey {
 			col.NotNull = true
 		}
@@ -131,12 +157,20 @@ func (c *Client) autoMigrateTable(ctx context.Context, table *schema.Table, chan
 			if err != nil {
 				return err
 			}
+		case schema.TableColumnChangeTypeUpdate:
+			// the other updates are a part of the CQID migration
+			if c.canAlterColumn(change) {
+				err := c.alterColumn(ctx, tableName, change)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 	return nil
 }
 
-func (*Client) canAutoMigrate(changes []schema.TableColumnChange) bool {
+func (c *Client) canAutoMigrate(changes []schema.TableColumnChange) bool {
 	// The SDK can detect more granular changes than we can handle
 	// We know that when the `TableColumnChangeTypeMoveToCQOnly` is present there will be other changes that were found as well
 	// As long as the only change is to remove PK from columns and add it to _cq_id, we can skip handling the changes
@@ -186,6 +220,9 @@ func (*Client) canAutoMigrate(changes []schema.TableColumnChange) bool {
 				// We don't need to handle these changes as they are a part of the CQID migration
 				continue
 			}
+			if c.canAlterColumn(change) {
+				continue
+			}
 			return false
 		default:
 			return false
@@ -330,9 +367,32 @@ func (c *Client) addColumn(ctx context.Context, tableName string, column schema.
 }
 
 func (c *Client) createTableIfNotExist(ctx context.Context, table *schema.Table) error {
-	var sb strings.Builder
+	return c.createTable(ctx, c.conn, table)
+}
+
+// createTable creates the table with the given executor, so that it can be a part of a transaction.
+func (c *Client) createTable(ctx context.Context, conn executor, table *schema.Table) error {
 	tableName := table.Name
-	sanitizedTableName := pgx.Identifier{tableName}.Sanitize()
+	// recreated tables keep their partitioning
+	partitioned := c.partitioned(table)
+	c.pgTablesToPKConstraints[tableName] = &pkConstraintDetails{
+		name:        getPKName(table),
+		columns:     table.PrimaryKeys(),
+		partitioned: partitioned,
+	}
+
+	sql := c.createTableSQL(table)
+	_, err := conn.Exec(ctx, sql)
+	if err != nil {
+		c.logger.Error().Err(err).Str("table", tableName).Str("query", sql).Msg("Failed to create table")
+		return fmt.Errorf("failed to create table %s: %w"+sql, tableName, err)
+	}
+	return nil
+}
+
+func (c *Client) createTableSQL(table *schema.Table) string {
+	var sb strings.Builder
+	sanitizedTableName := pgx.Identifier{table.Name}.Sanitize()
 	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
 	sb.WriteString(sanitizedTableName)
 	sb.WriteString(" (")
@@ -359,11 +419,6 @@ func (c *Client) createTableIfNotExist(ctx context.Context, table *schema.Table)
 	}
 
 	pkConstraintName := getPKName(table)
-	c.pgTablesToPKConstraints[tableName] = &pkConstraintDetails{
-		name:    pkConstraintName,
-		columns: table.PrimaryKeys(),
-	}
-
 	if len(primaryKeys) > 0 {
 		// add composite PK constraint on primary key columns
 		sb.WriteString(", CONSTRAINT ")
@@ -373,12 +428,12 @@ func (c *Client) createTableIfNotExist(ctx context.Context, table *schema.Table)
 		sb.WriteString(")")
 	}
 	sb.WriteString(")")
-	_, err := c.conn.Exec(ctx, sb.String())
-	if err != nil {
-		c.logger.Error().Err(err).Str("table", tableName).Str("query", sb.String()).Msg("Failed to create table")
-		return fmt.Errorf("failed to create table %s: %w"+sb.String(), tableName, err)
+	if c.partitioned(table) {
+		sb.WriteString(" PARTITION BY RANGE (")
+		sb.WriteString(pgx.Identifier{schema.CqSyncTimeColumn.Name}.Sanitize())
+		sb.WriteString(")")
 	}
-	return nil
+	return sb.String()
 }
 
 func (c *Client) removeUniqueConstraint(ctx context.Context, table *schema.Table, change schema.TableColumnChange) error {
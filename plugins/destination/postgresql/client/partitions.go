@@ -0,0 +1,271 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/cloudquery/cloudquery/plugins/destination/postgresql/client/spec"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/jackc/pgx/v5"
+)
+
+const partitionBoundLayout = "2006-01-02 15:04:05"
+
+const (
+	selectPartitions = `select pg_class.relname, pg_catalog.pg_get_expr(pg_class.relpartbound, pg_class.oid)
+from pg_catalog.pg_inherits
+inner join pg_catalog.pg_class on pg_class.oid = pg_inherits.inhrelid
+where pg_inherits.inhparent = $1::regclass`
+)
+
+var partitionUpperBoundRegexp = regexp.MustCompile(`TO \('([^']+)'\)`)
+
+// tableOptions returns the options of the table, or empty options for the databases other than PostgreSQL.
+func (c *Client) tableOptions(tableName string) *spec.TableOptions {
+	if c.spec == nil || c.pgType != pgTypePostgreSQL {
+		return new(spec.TableOptions)
+	}
+	return c.spec.TableOptions(tableName)
+}
+
+// partitionedBySpec reports whether partition_interval is set for the table.
+func (c *Client) partitionedBySpec(table *schema.Table) bool {
+	return c.tableOptions(table.Name).Partitioned() && table.Columns.Get(schema.CqSyncTimeColumn.Name) != nil
+}
+
+// partitioned reports whether the table is partitioned by `_cq_sync_time`.
+// Existing tables keep the partitioning they were created with, so partition_interval only applies to the new tables.
+func (c *Client) partitioned(table *schema.Table) bool {
+	c.pgTablesToPKConstraintsMu.RLock()
+	entry := c.pgTablesToPKConstraints[table.Name]
+	c.pgTablesToPKConstraintsMu.RUnlock()
+	if entry != nil {
+		return entry.partitioned
+	}
+	return c.partitionedBySpec(table)
+}
+
+// checkPartitioning returns an error if partition_interval is set for a table with a primary key.
+// The primary key of a partitioned table has to include the partitioning column,
+// so upserts on the primary key would insert a new row on every sync instead of updating the existing one.
+func (c *Client) checkPartitioning(table *schema.Table) error {
+	if c.partitionedBySpec(table) && len(table.PrimaryKeysIndexes()) > 0 {
+		return fmt.Errorf("table %s has a primary key, so it can't be partitioned. partition_interval is only supported for the tables without primary keys, such as the tables synced in the append write mode", table.Name)
+	}
+	return nil
+}
+
+// partitionStart returns the start of the partition the time falls in.
+func partitionStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	switch interval {
+	case spec.PartitionIntervalWeek:
+		// weeks start on Monday
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case spec.PartitionIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case spec.PartitionIntervalYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// addIntervals returns the start of the partition n intervals after the partition starting at start.
+func addIntervals(start time.Time, interval string, n int) time.Time {
+	switch interval {
+	case spec.PartitionIntervalWeek:
+		return start.AddDate(0, 0, 7*n)
+	case spec.PartitionIntervalMonth:
+		return start.AddDate(0, n, 0)
+	case spec.PartitionIntervalYear:
+		return start.AddDate(n, 0, 0)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+func partitionName(tableName string, start time.Time, interval string) string {
+	var suffix string
+	switch interval {
+	case spec.PartitionIntervalMonth:
+		suffix = start.Format("_p200601")
+	case spec.PartitionIntervalYear:
+		suffix = start.Format("_p2006")
+	default:
+		suffix = start.Format("_p20060102")
+	}
+	// Postgres truncates identifiers longer than 63 characters, so the table name is truncated to keep the suffix
+	if len(tableName)+len(suffix) > 63 {
+		tableName = tableName[:63-len(suffix)]
+	}
+	return tableName + suffix
+}
+
+func createPartitionSQL(tableName string, start time.Time, interval string) string {
+	end := addIntervals(start, interval, 1)
+	return "create table if not exists " + pgx.Identifier{partitionName(tableName, start, interval)}.Sanitize() +
+		" partition of " + pgx.Identifier{tableName}.Sanitize() +
+		" for values from ('" + start.Format(partitionBoundLayout) + "') to ('" + end.Format(partitionBoundLayout) + "')"
+}
+
+// createPartitions creates the partitions covering the time range, if they don't exist yet.
+func (c *Client) createPartitions(ctx context.Context, conn executor, table *schema.Table, from, to time.Time) error {
+	interval := c.tableOptions(table.Name).PartitionInterval
+	last := partitionStart(to, interval)
+	for start := partitionStart(from, interval); !start.After(last); start = addIntervals(start, interval, 1) {
+		sql := createPartitionSQL(table.Name, start, interval)
+		if _, err := conn.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("failed to create partition %s of table %s: %w", partitionName(table.Name, start, interval), table.Name, err)
+		}
+	}
+	return nil
+}
+
+// parsePartitionUpperBound returns the end of the partition range from the partition bound expression,
+// for example `FOR VALUES FROM ('2024-01-01 00:00:00') TO ('2024-01-02 00:00:00')`.
+func parsePartitionUpperBound(expr string) (time.Time, bool) {
+	match := partitionUpperBoundRegexp.FindStringSubmatch(expr)
+	if match == nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse(partitionBoundLayout, match[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return end, true
+}
+
+// migratePartitions creates the partitions of the current and the future intervals, and drops the expired partitions.
+func (c *Client) migratePartitions(ctx context.Context, table *schema.Table) error {
+	if !c.partitionedBySpec(table) {
+		return nil
+	}
+	if !c.partitioned(table) {
+		c.logger.Warn().Str("table", table.Name).Msg("Table isn't partitioned, as it was created before partition_interval was set. Drop it to partition it")
+		return nil
+	}
+	sanitizedTableName := pgx.Identifier{table.Name}.Sanitize()
+
+	options := c.tableOptions(table.Name)
+	now := time.Now().UTC()
+	ahead := addIntervals(partitionStart(now, options.PartitionInterval), options.PartitionInterval, *options.PartitionsAhead)
+	if err := c.createPartitions(ctx, c.conn, table, now, ahead); err != nil {
+		return err
+	}
+
+	retention := options.PartitionRetention.Duration()
+	if retention <= 0 {
+		return nil
+	}
+	rows, err := c.conn.Query(ctx, selectPartitions, sanitizedTableName)
+	if err != nil {
+		return fmt.Errorf("failed to list partitions of table %s: %w", table.Name, err)
+	}
+	type partition struct {
+		name  string
+		bound string
+	}
+	partitions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (partition, error) {
+		var p partition
+		err := row.Scan(&p.name, &p.bound)
+		return p, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list partitions of table %s: %w", table.Name, err)
+	}
+	expiry := now.Add(-retention)
+	for _, p := range partitions {
+		end, ok := parsePartitionUpperBound(p.bound)
+		if !ok || end.After(expiry) {
+			continue
+		}
+		c.logger.Info().Str("table", table.Name).Str("partition", p.name).Time("end", end).Msg("Dropping expired partition")
+		if _, err := c.conn.Exec(ctx, "drop table "+pgx.Identifier{p.name}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to drop partition %s of table %s: %w", p.name, table.Name, err)
+		}
+	}
+	return nil
+}
+
+// syncTimePartitions returns the starts of the partitions the `_cq_sync_time` values of the record fall in.
+func syncTimePartitions(record arrow.Record, interval string) ([]time.Time, error) {
+	indices := record.Schema().FieldIndices(schema.CqSyncTimeColumn.Name)
+	if len(indices) == 0 {
+		return nil, nil
+	}
+	column, ok := record.Column(indices[0]).(*array.Timestamp)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s column type %s", schema.CqSyncTimeColumn.Name, record.Column(indices[0]).DataType())
+	}
+	toTime, err := column.DataType().(*arrow.TimestampType).GetToTimeFunc()
+	if err != nil {
+		return nil, err
+	}
+	var starts []time.Time
+	for i := 0; i < column.Len(); i++ {
+		if column.IsNull(i) {
+			continue
+		}
+		start := partitionStart(toTime(column.Value(i)), interval)
+		if !slices.ContainsFunc(starts, start.Equal) {
+			starts = append(starts, start)
+		}
+	}
+	return starts, nil
+}
+
+// createWritePartitions creates the partitions the rows are written to, if they don't exist yet.
+// The partitions created in advance when the tables are migrated run out if the tables aren't migrated for long enough,
+// for example when syncing with `--no-migrate`.
+func (c *Client) createWritePartitions(ctx context.Context, messages message.WriteInserts) error {
+	for _, msg := range messages {
+		table := msg.GetTable()
+		if !c.partitionedBySpec(table) || !c.partitioned(table) {
+			continue
+		}
+		interval := c.tableOptions(table.Name).PartitionInterval
+		starts, err := syncTimePartitions(msg.Record, interval)
+		if err != nil {
+			return fmt.Errorf("failed to get the partitions of table %s: %w", table.Name, err)
+		}
+		for _, start := range starts {
+			if err := c.createPartition(ctx, table, start, interval); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createPartition creates the partition starting at start, unless it was already created by this client.
+func (c *Client) createPartition(ctx context.Context, table *schema.Table, start time.Time, interval string) error {
+	name := partitionName(table.Name, start, interval)
+	c.partitionsMu.Lock()
+	defer c.partitionsMu.Unlock()
+	if _, ok := c.partitions[name]; ok {
+		return nil
+	}
+	if _, err := c.conn.Exec(ctx, createPartitionSQL(table.Name, start, interval)); err != nil {
+		return fmt.Errorf("failed to create partition %s of table %s: %w", name, table.Name, err)
+	}
+	if c.partitions == nil {
+		c.partitions = make(map[string]struct{})
+	}
+	c.partitions[name] = struct{}{}
+	return nil
+}
+
+// resetWritePartitions forgets the partitions created by the write path, as migrations may drop them.
+func (c *Client) resetWritePartitions() {
+	c.partitionsMu.Lock()
+	defer c.partitionsMu.Unlock()
+	c.partitions = nil
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/cloudquery/cloudquery/plugins/destination/postgresql/client/spec"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPartitionStart(t *testing.T) {
+	// Thursday
+	ts := time.Date(2024, 2, 15, 13, 14, 15, 0, time.UTC)
+	tests := []struct {
+		interval string
+		want     time.Time
+		next     time.Time
+		name     string
+	}{
+		{
+			interval: spec.PartitionIntervalDay,
+			want:     time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC),
+			name:     "t_p20240215",
+		},
+		{
+			interval: spec.PartitionIntervalWeek,
+			want:     time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2024, 2, 19, 0, 0, 0, 0, time.UTC),
+			name:     "t_p20240212",
+		},
+		{
+			interval: spec.PartitionIntervalMonth,
+			want:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			name:     "t_p202402",
+		},
+		{
+			interval: spec.PartitionIntervalYear,
+			want:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			next:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			name:     "t_p2024",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.interval, func(t *testing.T) {
+			start := partitionStart(ts, tc.interval)
+			if !start.Equal(tc.want) {
+				t.Errorf("partitionStart() = %v, want %v", start, tc.want)
+			}
+			if next := addIntervals(start, tc.interval, 1); !next.Equal(tc.next) {
+				t.Errorf("addIntervals() = %v, want %v", next, tc.next)
+			}
+			if name := partitionName("t", start, tc.interval); name != tc.name {
+				t.Errorf("partitionName() = %s, want %s", name, tc.name)
+			}
+		})
+	}
+}
+
+func TestPartitionStartSunday(t *testing.T) {
+	got := partitionStart(time.Date(2024, 2, 18, 23, 0, 0, 0, time.UTC), spec.PartitionIntervalWeek)
+	if want := time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("partitionStart() = %v, want %v", got, want)
+	}
+}
+
+func TestPartitionNameTruncated(t *testing.T) {
+	long := "a_very_long_table_name_that_is_already_close_to_the_limit_of_63"
+	got := partitionName(long, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), spec.PartitionIntervalDay)
+	if want := long[:53] + "_p20240215"; got != want {
+		t.Errorf("partitionName() = %s, want %s", got, want)
+	}
+}
+
+func TestCreatePartitionSQL(t *testing.T) {
+	got := createPartitionSQL("t", time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), spec.PartitionIntervalMonth)
+	want := `create table if not exists "t_p202412" partition of "t" for values from ('2024-12-01 00:00:00') to ('2025-01-01 00:00:00')`
+	if got != want {
+		t.Errorf("createPartitionSQL() = %s, want %s", got, want)
+	}
+}
+
+func TestParsePartitionUpperBound(t *testing.T) {
+	end, ok := parsePartitionUpperBound(`FOR VALUES FROM ('2024-01-01 00:00:00') TO ('2024-01-02 00:00:00')`)
+	if !ok {
+		t.Fatal("parsePartitionUpperBound() failed")
+	}
+	if want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("parsePartitionUpperBound() = %v, want %v", end, want)
+	}
+	if _, ok := parsePartitionUpperBound(`FOR VALUES FROM (MINVALUE) TO (MAXVALUE)`); ok {
+		t.Error("parsePartitionUpperBound() succeeded for MAXVALUE")
+	}
+}
+
+func TestPartitionedTable(t *testing.T) {
+	c := &Client{
+		pgType: pgTypePostgreSQL,
+		spec: &spec.Spec{Tables: map[string]*spec.TableOptions{
+			"t*": {PartitionInterval: spec.PartitionIntervalDay},
+		}},
+		pgTablesToPKConstraints: map[string]*pkConstraintDetails{},
+	}
+	table := &schema.Table{
+		Name: "t",
+		Columns: schema.ColumnList{
+			schema.CqSyncTimeColumn,
+			{Name: schema.CqIDColumn.Name, Type: types.ExtensionTypes.UUID, Unique: true, NotNull: true},
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		},
+	}
+
+	if err := c.checkPartitioning(table); err != nil {
+		t.Errorf("checkPartitioning() = %v", err)
+	}
+	normalized := c.normalizeTable(table)
+	for _, col := range normalized.Columns {
+		if col.Unique {
+			t.Errorf("column %s is unique", col.Name)
+		}
+	}
+
+	want := `CREATE TABLE IF NOT EXISTS "t" ("_cq_sync_time" timestamp without time zone,"_cq_id" uuid NOT NULL,"id" bigint) ` +
+		`PARTITION BY RANGE ("_cq_sync_time")`
+	if got := c.createTableSQL(normalized); got != want {
+		t.Errorf("createTableSQL() = %s, want %s", got, want)
+	}
+
+	c.pgType = pgTypeCockroachDB
+	if c.partitioned(table) {
+		t.Error("partitioned() = true for CockroachDB")
+	}
+}
+
+func TestPartitionedTableWithPrimaryKey(t *testing.T) {
+	c := &Client{
+		pgType: pgTypePostgreSQL,
+		spec: &spec.Spec{Tables: map[string]*spec.TableOptions{
+			"t": {PartitionInterval: spec.PartitionIntervalDay},
+		}},
+	}
+	table := &schema.Table{
+		Name: "t",
+		Columns: schema.ColumnList{
+			schema.CqSyncTimeColumn,
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true},
+		},
+	}
+	if err := c.checkPartitioning(table); err == nil {
+		t.Error("checkPartitioning() succeeded for a table with a primary key")
+	}
+}
+
+func TestPartitionedExistingTable(t *testing.T) {
+	c := &Client{
+		pgType: pgTypePostgreSQL,
+		spec: &spec.Spec{Tables: map[string]*spec.TableOptions{
+			"t*": {PartitionInterval: spec.PartitionIntervalDay},
+		}},
+		pgTablesToPKConstraints: map[string]*pkConstraintDetails{
+			"t_unpartitioned": {},
+			"u_partitioned":   {partitioned: true},
+		},
+	}
+	columns := schema.ColumnList{
+		schema.CqSyncTimeColumn,
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Unique: true},
+	}
+	for _, tc := range []struct {
+		table string
+		want  bool
+	}{
+		{table: "t_new", want: true},
+		{table: "t_unpartitioned", want: false},
+		{table: "u_partitioned", want: true},
+		{table: "u_new", want: false},
+	} {
+		t.Run(tc.table, func(t *testing.T) {
+			table := &schema.Table{Name: tc.table, Columns: columns}
+			if got := c.partitioned(table); got != tc.want {
+				t.Errorf("partitioned() = %v, want %v", got, tc.want)
+			}
+			// the unique constraints of the existing unpartitioned tables are kept
+			if got := c.normalizeTable(table).Columns.Get("id").Unique; got == tc.want {
+				t.Errorf("Unique = %v, want %v", got, !tc.want)
+			}
+		})
+	}
+}
+
+func TestSyncTimePartitions(t *testing.T) {
+	table := &schema.Table{
+		Name:    "t",
+		Columns: schema.ColumnList{schema.CqSyncTimeColumn},
+	}
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, table.ToArrowSchema())
+	defer bldr.Release()
+	syncTimes := bldr.Field(0).(*array.TimestampBuilder)
+	for _, ts := range []time.Time{
+		time.Date(2024, 2, 15, 13, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 15, 14, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		syncTimes.Append(arrow.Timestamp(ts.UnixMicro()))
+	}
+	syncTimes.AppendNull()
+	record := bldr.NewRecord()
+	defer record.Release()
+
+	got, err := syncTimePartitions(record, spec.PartitionIntervalDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("syncTimePartitions() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const shadowTablePrefix = "_cq_shadow_"
+
+const (
+	selectConstraints = `select conname from pg_catalog.pg_constraint where conrelid = $1::regclass and contype in ('p', 'u')`
+	selectIndexes     = `select indexrelid::regclass::text from pg_catalog.pg_index where indrelid = $1::regclass`
+)
+
+// executor is implemented by both the pool and the transactions.
+type executor interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// canCopyRows reports whether the rows of the existing table can be copied to the table with the new schema:
+// all the NOT NULL columns of the new schema have to be present in the existing table, and there has to be at least one common column.
+func canCopyRows(table *schema.Table, pgTable *schema.Table) bool {
+	common := 0
+	for _, col := range table.Columns {
+		switch {
+		case pgTable.Columns.Get(col.Name) != nil:
+			common++
+		case col.NotNull:
+			return false
+		}
+	}
+	return common > 0
+}
+
+// shadowMigrateTable recreates the table with the new schema, keeping the existing rows.
+// In a single transaction, the existing table is renamed to a shadow table, the table is created with the new schema,
+// the rows are copied from the shadow table, casting the values of the columns whose type changed, and the shadow table is dropped.
+// If any of the values can't be cast, the transaction is rolled back and the table is left as is.
+func (c *Client) shadowMigrateTable(ctx context.Context, table *schema.Table, pgTable *schema.Table) error {
+	tableName := table.Name
+	shadowTable := shadowTableName(tableName)
+	sanitizedShadowTable := pgx.Identifier{shadowTable}.Sanitize()
+
+	err := pgx.BeginFunc(ctx, c.conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "alter table "+pgx.Identifier{tableName}.Sanitize()+" rename to "+sanitizedShadowTable); err != nil {
+			return fmt.Errorf("failed to rename table to %s: %w", shadowTable, err)
+		}
+		// the names of the constraints, indexes & partitions are kept when renaming the table,
+		// so the constraints & indexes are dropped to be recreated, and the partitions are renamed
+		if err := dropConstraintsAndIndexes(ctx, tx, shadowTable); err != nil {
+			return err
+		}
+		if err := renamePartitions(ctx, tx, shadowTable); err != nil {
+			return err
+		}
+		if err := c.createTable(ctx, tx, table); err != nil {
+			return err
+		}
+		if c.partitioned(table) {
+			if err := c.createShadowRowsPartitions(ctx, tx, table, pgTable, shadowTable); err != nil {
+				return err
+			}
+		}
+		sql := c.copyRowsSQL(table, pgTable, shadowTable)
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return wrapPgErr("failed to copy rows from "+shadowTable, err)
+		}
+		if _, err := tx.Exec(ctx, "drop table "+sanitizedShadowTable); err != nil {
+			return fmt.Errorf("failed to drop table %s: %w", shadowTable, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+func shadowTableName(tableName string) string {
+	name := shadowTablePrefix + tableName
+	// Postgres truncates identifiers longer than 63 characters
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+func dropConstraintsAndIndexes(ctx context.Context, tx pgx.Tx, tableName string) error {
+	sanitizedTableName := pgx.Identifier{tableName}.Sanitize()
+	constraints, err := queryStrings(ctx, tx, selectConstraints, sanitizedTableName)
+	if err != nil {
+		return fmt.Errorf("failed to list constraints of table %s: %w", tableName, err)
+	}
+	for _, name := range constraints {
+		if _, err := tx.Exec(ctx, "alter table "+sanitizedTableName+" drop constraint "+pgx.Identifier{name}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to drop constraint %s of table %s: %w", name, tableName, err)
+		}
+	}
+
+	// the indexes backing the constraints are dropped together with them
+	indexes, err := queryStrings(ctx, tx, selectIndexes, sanitizedTableName)
+	if err != nil {
+		return fmt.Errorf("failed to list indexes of table %s: %w", tableName, err)
+	}
+	for _, name := range indexes {
+		// the names are already quoted by the regclass conversion
+		if _, err := tx.Exec(ctx, "drop index "+name); err != nil {
+			return fmt.Errorf("failed to drop index %s of table %s: %w", name, tableName, err)
+		}
+	}
+	return nil
+}
+
+func renamePartitions(ctx context.Context, tx pgx.Tx, tableName string) error {
+	rows, err := tx.Query(ctx, selectPartitions, pgx.Identifier{tableName}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("failed to list partitions of table %s: %w", tableName, err)
+	}
+	partitions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (string, error) {
+		var name, bound string
+		err := row.Scan(&name, &bound)
+		return name, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list partitions of table %s: %w", tableName, err)
+	}
+	for _, name := range partitions {
+		sql := "alter table " + pgx.Identifier{name}.Sanitize() + " rename to " + pgx.Identifier{shadowTableName(name)}.Sanitize()
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("failed to rename partition %s of table %s: %w", name, tableName, err)
+		}
+	}
+	return nil
+}
+
+// createShadowRowsPartitions creates the partitions for the rows copied from the shadow table.
+func (c *Client) createShadowRowsPartitions(ctx context.Context, tx pgx.Tx, table *schema.Table, pgTable *schema.Table, shadowTable string) error {
+	if pgTable.Columns.Get(schema.CqSyncTimeColumn.Name) == nil {
+		return nil
+	}
+	column := pgx.Identifier{schema.CqSyncTimeColumn.Name}.Sanitize()
+	var from, to *time.Time
+	sql := "select min(" + column + "), max(" + column + ") from " + pgx.Identifier{shadowTable}.Sanitize()
+	if err := tx.QueryRow(ctx, sql).Scan(&from, &to); err != nil {
+		return fmt.Errorf("failed to get the sync time range of table %s: %w", shadowTable, err)
+	}
+	if from == nil || to == nil {
+		return nil
+	}
+	return c.createPartitions(ctx, tx, table, *from, *to)
+}
+
+func queryStrings(ctx context.Context, tx pgx.Tx, sql string, args ...any) ([]string, error) {
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// copyRowsSQL returns the query copying the rows of the shadow table to the table with the new schema.
+// The values of the columns whose type changed are cast to the new type, and the columns missing from the shadow table are left empty.
+// If the primary key or the unique constraints changed, only the first of the conflicting rows is kept.
+func (c *Client) copyRowsSQL(table *schema.Table, pgTable *schema.Table, shadowTable string) string {
+	var columns, values []string
+	conflicts := false
+	for _, col := range table.Columns {
+		conflicts = conflicts || col.PrimaryKey || col.Unique
+		pgCol := pgTable.Columns.Get(col.Name)
+		if pgCol == nil {
+			continue
+		}
+		columnName := pgx.Identifier{col.Name}.Sanitize()
+		columns = append(columns, columnName)
+		if columnType := c.SchemaTypeToPg(col.Type); columnType != c.SchemaTypeToPg(pgCol.Type) {
+			values = append(values, columnName+"::"+columnType)
+		} else {
+			values = append(values, columnName)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("insert into ")
+	sb.WriteString(pgx.Identifier{table.Name}.Sanitize())
+	sb.WriteString(" (")
+	sb.WriteString(strings.Join(columns, ","))
+	sb.WriteString(") select ")
+	sb.WriteString(strings.Join(values, ","))
+	sb.WriteString(" from ")
+	sb.WriteString(pgx.Identifier{shadowTable}.Sanitize())
+	if conflicts {
+		sb.WriteString(" on conflict do nothing")
+	}
+	return sb.String()
+}
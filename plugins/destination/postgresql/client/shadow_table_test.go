@@ -0,0 +1,67 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+)
+
+func TestCopyRowsSQL(t *testing.T) {
+	pgTable := &schema.Table{
+		Name: "t",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true, NotNull: true},
+			{Name: "flag", Type: arrow.FixedWidthTypes.Boolean},
+			{Name: "removed", Type: arrow.BinaryTypes.String},
+		},
+	}
+	table := &schema.Table{
+		Name: "t",
+		Columns: schema.ColumnList{
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true, NotNull: true},
+			{Name: "flag", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "added", Type: arrow.BinaryTypes.String},
+		},
+	}
+	c := &Client{pgType: pgTypePostgreSQL}
+	want := `insert into "t" ("id","flag") select "id","flag"::bigint from "_cq_shadow_t" on conflict do nothing`
+	if got := c.copyRowsSQL(table, pgTable, shadowTableName(table.Name)); got != want {
+		t.Errorf("copyRowsSQL() = %s, want %s", got, want)
+	}
+}
+
+func TestCanCopyRows(t *testing.T) {
+	pgTable := &schema.Table{
+		Name:    "t",
+		Columns: schema.ColumnList{{Name: "id", Type: arrow.PrimitiveTypes.Int64}},
+	}
+	tests := []struct {
+		name    string
+		columns schema.ColumnList
+		want    bool
+	}{
+		{
+			name:    "nullable column added",
+			columns: schema.ColumnList{{Name: "id", Type: arrow.BinaryTypes.String}, {Name: "added", Type: arrow.BinaryTypes.String}},
+			want:    true,
+		},
+		{
+			name:    "not null column added",
+			columns: schema.ColumnList{{Name: "id", Type: arrow.BinaryTypes.String}, {Name: "added", Type: arrow.BinaryTypes.String, NotNull: true}},
+			want:    false,
+		},
+		{
+			name:    "no common columns",
+			columns: schema.ColumnList{{Name: "added", Type: arrow.BinaryTypes.String}},
+			want:    false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := canCopyRows(&schema.Table{Name: "t", Columns: tc.columns}, pgTable); got != tc.want {
+				t.Errorf("canCopyRows() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package spec
 import (
 	_ "embed"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/cloudquery/plugin-sdk/v4/configtype"
@@ -15,6 +16,16 @@ const (
 	defaultBatchTimeout   = 60 * time.Second
 )
 
+const (
+	WriteMethodInsert = "insert"
+	WriteMethodCopy   = "copy"
+)
+
+const (
+	ForcedMigrationStrategyDrop        = "drop"
+	ForcedMigrationStrategyShadowTable = "shadow_table"
+)
+
 type Spec struct {
 	// Connection string to connect to the database. This can be a URL or a DSN, for example:
 	//
@@ -35,6 +46,46 @@ type Spec struct {
 
 	// Maximum interval between batch writes.
 	BatchTimeout configtype.Duration `json:"batch_timeout,omitempty"`
+
+	// How the batches are written to the database:
+	//
+	// - `insert` sends an `INSERT` (or `INSERT ... ON CONFLICT DO UPDATE` for tables with primary keys) per row.
+	//
+	// - `copy` streams the rows with the binary `COPY FROM STDIN` protocol.
+	//   The rows of tables with primary keys are copied into a temporary table first,
+	//   and then upserted with `INSERT ... SELECT ... ON CONFLICT DO UPDATE`.
+	//
+	// `copy` is only supported by PostgreSQL. CockroachDB and CrateDB always use `insert`.
+	WriteMethod string `json:"write_method,omitempty" jsonschema:"enum=insert,enum=copy,default=insert"`
+
+	// How the tables are migrated with `migrate_mode: forced` when the changes can't be done in place:
+	//
+	// - `drop` drops the table and creates it with the new schema, losing the existing rows.
+	//
+	// - `shadow_table` renames the table, creates it with the new schema and copies the existing rows to it,
+	//   casting the values of the columns whose type changed, all in a single transaction.
+	//   If the primary key changed, only one of the rows with the same primary key is kept.
+	//   If the rows can't be copied (e.g., a new `NOT NULL` column was added), the table is dropped instead.
+	//
+	// `shadow_table` is only supported by PostgreSQL. CockroachDB and CrateDB always use `drop`.
+	ForcedMigrationStrategy string `json:"forced_migration_strategy,omitempty" jsonschema:"enum=drop,enum=shadow_table,default=drop"`
+
+	// Map of table names or glob patterns to the options overriding the way the matching tables are created, for example:
+	//
+	// ```yaml
+	// tables:
+	//   "aws_cloudtrail_*":
+	//     partition_interval: "day"
+	//     partition_retention: "2160h"
+	//     indexes:
+	//       - columns: ["event_name"]
+	//       - columns: ["resources"]
+	//         method: "gin"
+	// ```
+	//
+	// Exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+	// Partitioning and indexes are only supported by PostgreSQL.
+	Tables map[string]*TableOptions `json:"tables,omitempty"`
 }
 
 func (s *Spec) SetDefaults() {
@@ -47,12 +98,36 @@ func (s *Spec) SetDefaults() {
 	if s.BatchTimeout.Duration() <= 0 {
 		s.BatchTimeout = configtype.NewDuration(defaultBatchTimeout)
 	}
+	if len(s.WriteMethod) == 0 {
+		s.WriteMethod = WriteMethodInsert
+	}
+	if len(s.ForcedMigrationStrategy) == 0 {
+		s.ForcedMigrationStrategy = ForcedMigrationStrategyDrop
+	}
 }
 
 func (s *Spec) Validate() error {
 	if len(s.ConnectionString) == 0 {
 		return errors.New("`connection_string` is required")
 	}
+	switch s.WriteMethod {
+	case WriteMethodInsert, WriteMethodCopy:
+	default:
+		return fmt.Errorf("`write_method` must be one of %q or %q, got %q", WriteMethodInsert, WriteMethodCopy, s.WriteMethod)
+	}
+	switch s.ForcedMigrationStrategy {
+	case ForcedMigrationStrategyDrop, ForcedMigrationStrategyShadowTable:
+	default:
+		return fmt.Errorf("`forced_migration_strategy` must be one of %q or %q, got %q", ForcedMigrationStrategyDrop, ForcedMigrationStrategyShadowTable, s.ForcedMigrationStrategy)
+	}
+	for pattern, options := range s.Tables {
+		if options == nil {
+			continue
+		}
+		if err := options.Validate(); err != nil {
+			return fmt.Errorf("invalid options for tables %q: %w", pattern, err)
+		}
+	}
 	return nil
 }
 
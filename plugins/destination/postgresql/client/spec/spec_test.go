@@ -107,5 +107,36 @@ func TestSpec_JSONSchemaExtend(t *testing.T) {
 			Spec: `{"connection_string": "abc", "batch_timeout": null}`,
 			Err:  true,
 		},
+		{
+			Name: "insert write_method",
+			Spec: `{"connection_string": "abc", "write_method": "insert"}`,
+		},
+		{
+			Name: "copy write_method",
+			Spec: `{"connection_string": "abc", "write_method": "copy"}`,
+		},
+		{
+			Name: "unknown write_method",
+			Spec: `{"connection_string": "abc", "write_method": "merge"}`,
+			Err:  true,
+		},
+		{
+			Name: "null write_method",
+			Spec: `{"connection_string": "abc", "write_method": null}`,
+			Err:  true,
+		},
+		{
+			Name: "drop forced_migration_strategy",
+			Spec: `{"connection_string": "abc", "forced_migration_strategy": "drop"}`,
+		},
+		{
+			Name: "shadow_table forced_migration_strategy",
+			Spec: `{"connection_string": "abc", "forced_migration_strategy": "shadow_table"}`,
+		},
+		{
+			Name: "unknown forced_migration_strategy",
+			Spec: `{"connection_string": "abc", "forced_migration_strategy": "copy"}`,
+			Err:  true,
+		},
 	})
 }
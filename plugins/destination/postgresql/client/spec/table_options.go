@@ -0,0 +1,144 @@
+package spec
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudquery/plugin-sdk/v4/configtype"
+	"github.com/cloudquery/plugin-sdk/v4/glob"
+)
+
+const (
+	PartitionIntervalDay   = "day"
+	PartitionIntervalWeek  = "week"
+	PartitionIntervalMonth = "month"
+	PartitionIntervalYear  = "year"
+)
+
+const defaultPartitionsAhead = 2
+
+// IndexOptions defines an index created on the matching tables.
+type IndexOptions struct {
+	// Name of the index. Defaults to `<table>_<columns>_idx`.
+	Name string `json:"name,omitempty"`
+
+	// Columns to index, in order.
+	Columns []string `json:"columns" jsonschema:"required,minItems=1,minLength=1"`
+
+	// [Index method](https://www.postgresql.org/docs/current/indexes-types.html), for example `gin` for `jsonb` columns.
+	Method string `json:"method,omitempty" jsonschema:"enum=btree,enum=hash,enum=gist,enum=spgist,enum=gin,enum=brin,default=btree"`
+}
+
+func (o *IndexOptions) Validate() error {
+	if len(o.Columns) == 0 {
+		return fmt.Errorf("columns are required")
+	}
+	for _, column := range o.Columns {
+		if len(column) == 0 {
+			return fmt.Errorf("empty columns entry")
+		}
+	}
+	switch o.Method {
+	case "", "btree", "hash", "gist", "spgist", "gin", "brin":
+		return nil
+	default:
+		return fmt.Errorf("invalid method %q", o.Method)
+	}
+}
+
+// TableOptions allows to override the way the matching tables are created.
+type TableOptions struct {
+	// Partitions the matching tables by range of `_cq_sync_time`, with a partition per interval.
+	// If empty, the tables aren't partitioned.
+	//
+	// Only the tables without primary keys, such as the tables synced in `append` write mode, can be partitioned:
+	// the migration fails for the matching tables with primary keys.
+	// The unique constraints of partitioned tables aren't created, as they'd have to include the partitioning column.
+	//
+	// Only new tables are partitioned. The existing tables, including the ones recreated by forced migrations,
+	// keep the partitioning they were created with, so changing the partitioning of a table requires dropping it.
+	PartitionInterval string `json:"partition_interval,omitempty" jsonschema:"enum=day,enum=week,enum=month,enum=year"`
+
+	// Number of future partitions created in advance, in addition to the partition of the current interval.
+	// The partitions are created when the tables are migrated.
+	// The partitions missing when writing, for example when syncing with `--no-migrate`, are created before the rows are written.
+	PartitionsAhead *int `json:"partitions_ahead,omitempty" jsonschema:"minimum=0,default=2"`
+
+	// How long to keep the partitions for, for example `720h`.
+	// The partitions whose range ended longer ago are dropped when the tables are migrated.
+	// If not set, the partitions are kept.
+	PartitionRetention configtype.Duration `json:"partition_retention,omitempty"`
+
+	// Additional indexes created on the matching tables when the tables are migrated.
+	Indexes []IndexOptions `json:"indexes,omitempty"`
+}
+
+func (o *TableOptions) Validate() error {
+	switch o.PartitionInterval {
+	case "", PartitionIntervalDay, PartitionIntervalWeek, PartitionIntervalMonth, PartitionIntervalYear:
+	default:
+		return fmt.Errorf("invalid partition_interval %q", o.PartitionInterval)
+	}
+	if o.PartitionsAhead != nil && *o.PartitionsAhead < 0 {
+		return fmt.Errorf("partitions_ahead must not be negative")
+	}
+	if o.PartitionRetention.Duration() < 0 {
+		return fmt.Errorf("partition_retention must not be negative")
+	}
+	if len(o.PartitionInterval) == 0 && o.PartitionRetention.Duration() > 0 {
+		return fmt.Errorf("partition_retention requires partition_interval")
+	}
+	for i := range o.Indexes {
+		if err := o.Indexes[i].Validate(); err != nil {
+			return fmt.Errorf("indexes[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Partitioned reports whether the matching tables are partitioned.
+func (o *TableOptions) Partitioned() bool {
+	return len(o.PartitionInterval) > 0
+}
+
+func (s *Spec) tablePatterns() []string {
+	patterns := make([]string, 0, len(s.Tables))
+	for pattern := range s.Tables {
+		patterns = append(patterns, pattern)
+	}
+	// longer patterns are more specific, so they're matched first
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return patterns
+}
+
+// MatchTableOptions returns the `tables` entry matching the table, or nil if there's none.
+// Exact table names take precedence over glob patterns, and longer glob patterns take precedence over shorter ones.
+func (s *Spec) MatchTableOptions(table string) *TableOptions {
+	if options, ok := s.Tables[table]; ok {
+		return options
+	}
+	for _, pattern := range s.tablePatterns() {
+		if glob.Glob(pattern, table) {
+			return s.Tables[pattern]
+		}
+	}
+	return nil
+}
+
+// TableOptions returns the options the table is created with: the matching `tables` entry with the defaults applied.
+func (s *Spec) TableOptions(table string) *TableOptions {
+	var options TableOptions
+	if matched := s.MatchTableOptions(table); matched != nil {
+		options = *matched
+	}
+	if options.PartitionsAhead == nil {
+		partitionsAhead := defaultPartitionsAhead
+		options.PartitionsAhead = &partitionsAhead
+	}
+	return &options
+}
@@ -0,0 +1,107 @@
+package spec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudquery/codegen/jsonschema"
+	"github.com/cloudquery/plugin-sdk/v4/configtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableOptionsJSONSchema(t *testing.T) {
+	jsonschema.TestJSONSchema(t, JSONSchema, []jsonschema.TestCase{
+		{
+			Name: "empty tables",
+			Spec: `{"connection_string": "abc", "tables": {}}`,
+		},
+		{
+			Name: "partitioned table",
+			Spec: `{"connection_string": "abc", "tables": {"aws_*": {"partition_interval": "day", "partitions_ahead": 0, "partition_retention": "720h"}}}`,
+		},
+		{
+			Name: "bad partition_interval",
+			Spec: `{"connection_string": "abc", "tables": {"aws_*": {"partition_interval": "hour"}}}`,
+			Err:  true,
+		},
+		{
+			Name: "negative partitions_ahead",
+			Spec: `{"connection_string": "abc", "tables": {"aws_*": {"partition_interval": "day", "partitions_ahead": -1}}}`,
+			Err:  true,
+		},
+		{
+			Name: "indexes",
+			Spec: `{"connection_string": "abc", "tables": {"aws_*": {"indexes": [{"columns": ["account_id"]}, {"name": "tags_idx", "columns": ["tags"], "method": "gin"}]}}}`,
+		},
+		{
+			Name: "index without columns",
+			Spec: `{"connection_string": "abc", "tables": {"aws_*": {"indexes": [{"method": "gin"}]}}}`,
+			Err:  true,
+		},
+		{
+			Name: "index with empty column",
+			Spec: `{"connection_string": "abc", "tables": {"aws_*": {"indexes": [{"columns": [""]}]}}}`,
+			Err:  true,
+		},
+		{
+			Name: "bad index method",
+			Spec: `{"connection_string": "abc", "tables": {"aws_*": {"indexes": [{"columns": ["a"], "method": "bitmap"}]}}}`,
+			Err:  true,
+		},
+		{
+			Name: "unknown table option",
+			Spec: `{"connection_string": "abc", "tables": {"aws_*": {"engine": "heap"}}}`,
+			Err:  true,
+		},
+	})
+}
+
+func TestTableOptionsValidate(t *testing.T) {
+	negative := -1
+	for _, tc := range []struct {
+		name    string
+		options TableOptions
+		err     bool
+	}{
+		{name: "empty", options: TableOptions{}},
+		{name: "partitioned", options: TableOptions{PartitionInterval: PartitionIntervalMonth, PartitionRetention: configtype.NewDuration(time.Hour)}},
+		{name: "bad partition_interval", options: TableOptions{PartitionInterval: "hour"}, err: true},
+		{name: "negative partitions_ahead", options: TableOptions{PartitionInterval: PartitionIntervalDay, PartitionsAhead: &negative}, err: true},
+		{name: "retention without partitioning", options: TableOptions{PartitionRetention: configtype.NewDuration(time.Hour)}, err: true},
+		{name: "index without columns", options: TableOptions{Indexes: []IndexOptions{{Method: "gin"}}}, err: true},
+		{name: "bad index method", options: TableOptions{Indexes: []IndexOptions{{Columns: []string{"a"}, Method: "bitmap"}}}, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.options.Validate()
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSpecTableOptions(t *testing.T) {
+	zero := 0
+	s := Spec{Tables: map[string]*TableOptions{
+		"aws_*":            {PartitionInterval: PartitionIntervalMonth},
+		"aws_cloudtrail_*": {PartitionInterval: PartitionIntervalDay, PartitionsAhead: &zero},
+		"aws_s3_buckets":   {Indexes: []IndexOptions{{Columns: []string{"region"}}}},
+	}}
+
+	options := s.TableOptions("aws_cloudtrail_events")
+	require.Equal(t, PartitionIntervalDay, options.PartitionInterval)
+	require.Equal(t, 0, *options.PartitionsAhead)
+
+	options = s.TableOptions("aws_ec2_instances")
+	require.Equal(t, PartitionIntervalMonth, options.PartitionInterval)
+	require.Equal(t, defaultPartitionsAhead, *options.PartitionsAhead)
+
+	options = s.TableOptions("aws_s3_buckets")
+	require.False(t, options.Partitioned())
+	require.Len(t, options.Indexes, 1)
+
+	require.Nil(t, s.MatchTableOptions("gcp_projects"))
+	require.False(t, s.TableOptions("gcp_projects").Partitioned())
+}
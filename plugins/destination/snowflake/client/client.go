@@ -23,6 +23,10 @@ type Client struct {
 	writer *batchwriter.BatchWriter
 
 	setupWriteOnce *sync.Once
+	stageName      string
+	stageUploader  stageUploader
+	// stageCreated is set if the stage was created for the sync, so it has to be dropped when closing the client
+	stageCreated bool
 }
 
 func New(_ context.Context, logger zerolog.Logger, spec []byte, _ plugin.NewClientOptions) (plugin.Client, error) {
@@ -35,6 +39,9 @@ func New(_ context.Context, logger zerolog.Logger, spec []byte, _ plugin.NewClie
 		return nil, fmt.Errorf("failed to unmarshal snowflake spec: %w", err)
 	}
 	c.spec.SetDefaults()
+	if err := c.spec.Validate(); err != nil {
+		return nil, err
+	}
 	c.writer, err = batchwriter.New(c, batchwriter.WithLogger(c.logger), batchwriter.WithBatchSize(c.spec.BatchSize), batchwriter.WithBatchSizeBytes(c.spec.BatchSizeBytes))
 	if err != nil {
 		return nil, err
@@ -68,6 +75,10 @@ func (c *Client) Close(ctx context.Context) error {
 		return fmt.Errorf("failed to close writer: %w", err)
 	}
 
+	if err := c.dropStage(ctx); err != nil {
+		c.logger.Warn().Err(err).Msg("Failed to drop stage")
+	}
+
 	err := c.db.Close()
 	c.db = nil
 	return err
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/compress"
+	"github.com/apache/arrow/go/v16/parquet/pqarrow"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+	"github.com/goccy/go-json"
+)
+
+// maxDecimalPrecision is the maximum precision of the fixed-point numbers in Snowflake.
+const maxDecimalPrecision = 38
+
+// stagedAsIs reports whether the values of the type are written to the Parquet files as is.
+// The values of other types are written as strings, the same way they're written to the JSON files.
+func stagedAsIs(dt arrow.DataType) bool {
+	switch dt := dt.(type) {
+	case *arrow.BooleanType,
+		*arrow.Int8Type, *arrow.Int16Type, *arrow.Int32Type, *arrow.Int64Type,
+		*arrow.Uint8Type, *arrow.Uint16Type, *arrow.Uint32Type,
+		*arrow.Float32Type, *arrow.Float64Type,
+		*arrow.StringType, *arrow.LargeStringType,
+		*arrow.BinaryType, *arrow.LargeBinaryType,
+		*arrow.TimestampType, *arrow.Date32Type, *arrow.Date64Type,
+		*arrow.Decimal128Type,
+		*types.UUIDType:
+		return true
+	case *arrow.Decimal256Type:
+		return dt.Precision <= maxDecimalPrecision
+	case *arrow.ListType:
+		return nestedAsIs(dt.Elem())
+	case *arrow.StructType:
+		for _, field := range dt.Fields() {
+			if !nestedAsIs(field.Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// nestedAsIs reports whether the values of the type nested in lists and structs are written to the Parquet files as is.
+// Only the types loaded to the semi-structured columns with the same representation as in the JSON files qualify.
+func nestedAsIs(dt arrow.DataType) bool {
+	switch dt.(type) {
+	case *arrow.BooleanType,
+		*arrow.Int8Type, *arrow.Int16Type, *arrow.Int32Type, *arrow.Int64Type,
+		*arrow.Uint8Type, *arrow.Uint16Type, *arrow.Uint32Type,
+		*arrow.Float32Type, *arrow.Float64Type,
+		*arrow.StringType, *arrow.LargeStringType,
+		*arrow.ListType, *arrow.StructType:
+		return stagedAsIs(dt)
+	default:
+		return false
+	}
+}
+
+// isJSONType reports whether the type is stored in a semi-structured (`variant` or `array`) column,
+// in which case the string values are JSON to be parsed when loading.
+func (c *Client) isJSONType(dt arrow.DataType) bool {
+	switch c.SchemaTypeToSnowflake(dt) {
+	case "variant", "array":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) parquetSchema(table *schema.Table) *arrow.Schema {
+	fields := make([]arrow.Field, len(table.Columns))
+	for i, col := range table.Columns {
+		fields[i] = arrow.Field{Name: col.Name, Type: col.Type, Nullable: true}
+		if !stagedAsIs(col.Type) {
+			fields[i].Type = arrow.BinaryTypes.String
+		}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// writeParquet writes the records as a Parquet file.
+func (c *Client) writeParquet(w io.Writer, table *schema.Table, msgs message.WriteInserts) error {
+	sc := c.parquetSchema(table)
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	fw, err := pqarrow.NewFileWriter(sc, w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		rec, err := c.parquetRecord(sc, msg.Record)
+		if err != nil {
+			_ = fw.Close()
+			return err
+		}
+		err = fw.Write(rec)
+		rec.Release()
+		if err != nil {
+			_ = fw.Close()
+			return err
+		}
+	}
+	return fw.Close()
+}
+
+// parquetRecord converts the columns that aren't staged as is to strings.
+func (c *Client) parquetRecord(sc *arrow.Schema, rec arrow.Record) (arrow.Record, error) {
+	cols := make([]arrow.Array, rec.NumCols())
+	defer func() {
+		for _, col := range cols {
+			if col != nil {
+				col.Release()
+			}
+		}
+	}()
+	for i, col := range rec.Columns() {
+		if stagedAsIs(col.DataType()) {
+			col.Retain()
+			cols[i] = col
+			continue
+		}
+		converted, err := c.stringArray(col)
+		if err != nil {
+			return nil, err
+		}
+		cols[i] = converted
+	}
+	return array.NewRecord(sc, cols, rec.NumRows()), nil
+}
+
+func (c *Client) stringArray(col arrow.Array) (arrow.Array, error) {
+	bldr := array.NewStringBuilder(memory.DefaultAllocator)
+	defer bldr.Release()
+	asJSON := c.isJSONType(col.DataType())
+	for i := 0; i < col.Len(); i++ {
+		if col.IsNull(i) {
+			bldr.AppendNull()
+			continue
+		}
+		if _, ok := col.(*types.JSONArray); ok {
+			bldr.Append(col.ValueStr(i))
+			continue
+		}
+		b, err := json.Marshal(col.GetOneForMarshal(i))
+		if err != nil {
+			return nil, err
+		}
+		if !asJSON && len(b) > 0 && b[0] == '"' {
+			// plain string values are loaded as is, without the quotes
+			var s string
+			if err := json.Unmarshal(b, &s); err != nil {
+				return nil, err
+			}
+			bldr.Append(s)
+			continue
+		}
+		bldr.Append(string(b))
+	}
+	return bldr.NewArray(), nil
+}
+
+// parquetSelectList returns the expressions converting the values read from the Parquet file to the column types.
+func (c *Client) parquetSelectList(table *schema.Table) string {
+	exprs := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		value := `$1:"` + col.Name + `"`
+		if stagedAsIs(col.Type) {
+			exprs[i] = c.nativeSelectExpr(value, col.Type)
+			continue
+		}
+		switch typ := c.SchemaTypeToSnowflake(col.Type); typ {
+		case "variant":
+			exprs[i] = "parse_json(" + value + "::text)"
+		case "array":
+			exprs[i] = "parse_json(" + value + "::text)::array"
+		case "binary":
+			// binary values are staged base64-encoded
+			exprs[i] = "to_binary(" + value + "::text, 'BASE64')"
+		default:
+			exprs[i] = value + "::" + typ
+		}
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// nativeSelectExpr returns the expression converting the value of the type staged as is to the column type.
+func (c *Client) nativeSelectExpr(value string, dt arrow.DataType) string {
+	switch dt := dt.(type) {
+	case *types.UUIDType:
+		// UUIDs are staged as 16-byte binary values
+		return `regexp_replace(lower(hex_encode(` + value + `::binary)), '^(.{8})(.{4})(.{4})(.{4})(.{12})$', '\\1-\\2-\\3-\\4-\\5')`
+	case *arrow.Decimal128Type:
+		// keep the trailing zeros of the scale
+		return fmt.Sprintf("%s::number(%d, %d)::text", value, dt.Precision, dt.Scale)
+	case *arrow.Decimal256Type:
+		return fmt.Sprintf("%s::number(%d, %d)::text", value, dt.Precision, dt.Scale)
+	case *arrow.Date32Type, *arrow.Date64Type:
+		return value + "::date::text"
+	case *arrow.StructType:
+		return value
+	default:
+		return value + "::" + c.SchemaTypeToSnowflake(dt)
+	}
+}
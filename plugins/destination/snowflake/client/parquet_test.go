@@ -0,0 +1,136 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/decimal128"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/apache/arrow/go/v16/parquet/pqarrow"
+	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/cloudquery/plugin-sdk/v4/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var parquetTestTable = &schema.Table{
+	Name: "test_table",
+	Columns: schema.ColumnList{
+		{Name: "int", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "uint64", Type: arrow.PrimitiveTypes.Uint64},
+		{Name: "string", Type: arrow.BinaryTypes.String},
+		{Name: "binary", Type: arrow.BinaryTypes.Binary},
+		{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_us},
+		{Name: "uuid", Type: types.ExtensionTypes.UUID},
+		{Name: "json", Type: types.ExtensionTypes.JSON},
+		{Name: "list", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+		{Name: "struct", Type: arrow.StructOf(arrow.Field{Name: "foo", Type: arrow.BinaryTypes.String, Nullable: true})},
+		{Name: "decimal", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}},
+		{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+		{Name: "timestamps", Type: arrow.ListOf(arrow.FixedWidthTypes.Timestamp_us)},
+	},
+}
+
+func TestWriteParquet(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	id := uuid.MustParse("2c6a3c2b-6d38-4e2a-9f3c-5c7f5a7b2d11")
+
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, parquetTestTable.ToArrowSchema())
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).Append(-7)
+	bldr.Field(1).(*array.Uint64Builder).Append(18446744073709551615)
+	bldr.Field(2).(*array.StringBuilder).Append("foo")
+	bldr.Field(3).(*array.BinaryBuilder).Append([]byte("bar"))
+	bldr.Field(4).(*array.TimestampBuilder).Append(arrow.Timestamp(ts.UnixMicro()))
+	bldr.Field(5).(*types.UUIDBuilder).Append(id)
+	bldr.Field(6).(*types.JSONBuilder).Append("abc")
+	list := bldr.Field(7).(*array.ListBuilder)
+	list.Append(true)
+	list.ValueBuilder().(*array.StringBuilder).Append("a")
+	list.ValueBuilder().(*array.StringBuilder).AppendNull()
+	st := bldr.Field(8).(*array.StructBuilder)
+	st.Append(true)
+	st.FieldBuilder(0).(*array.StringBuilder).AppendNull()
+	bldr.Field(9).(*array.Decimal128Builder).Append(decimal128.FromI64(1230))
+	bldr.Field(10).(*array.Date32Builder).Append(arrow.Date32FromTime(ts))
+	timestamps := bldr.Field(11).(*array.ListBuilder)
+	timestamps.Append(true)
+	timestamps.ValueBuilder().(*array.TimestampBuilder).Append(arrow.Timestamp(ts.UnixMicro()))
+	// second row is all nulls
+	for i := range parquetTestTable.Columns {
+		bldr.Field(i).AppendNull()
+	}
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	cl := &Client{}
+	require.NoError(t, cl.writeParquet(&buf, parquetTestTable, message.WriteInserts{{Record: rec}}))
+
+	reader, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+	fr, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	require.NoError(t, err)
+	tbl, err := fr.ReadTable(context.Background())
+	require.NoError(t, err)
+	defer tbl.Release()
+
+	tr := array.NewTableReader(tbl, -1)
+	defer tr.Release()
+	require.True(t, tr.Next())
+	got := tr.Record()
+	require.EqualValues(t, 2, got.NumRows())
+
+	// the types supported by Snowflake are read back as written
+	for _, i := range []int{0, 2, 3, 4, 5, 7, 8, 9, 10} {
+		name := parquetTestTable.Columns[i].Name
+		want := rec.Column(i)
+		if ext, ok := want.(array.ExtensionArray); ok {
+			// UUIDs are staged as their storage type
+			want = ext.Storage()
+		}
+		require.True(t, arrow.TypeEqual(want.DataType(), got.Column(i).DataType()), "column %s: %s", name, got.Column(i).DataType())
+		require.True(t, array.Equal(want, got.Column(i)), "column %s: %s", name, got.Column(i))
+	}
+
+	// the rest is converted to strings
+	for i, want := range map[int]string{
+		1:  "18446744073709551615",
+		6:  `"abc"`,
+		11: `["2024-01-02 03:04:05.123456Z"]`,
+	} {
+		name := parquetTestTable.Columns[i].Name
+		require.Equal(t, arrow.STRING, got.Column(i).DataType().ID(), "column %s", name)
+		require.Equal(t, want, got.Column(i).ValueStr(0), "column %s", name)
+		require.True(t, got.Column(i).IsNull(1), "column %s", name)
+	}
+}
+
+func TestStagedAsIs(t *testing.T) {
+	for _, tc := range []struct {
+		dt   arrow.DataType
+		want bool
+	}{
+		{dt: arrow.PrimitiveTypes.Int64, want: true},
+		{dt: arrow.PrimitiveTypes.Uint64, want: false},
+		{dt: arrow.BinaryTypes.LargeBinary, want: true},
+		{dt: arrow.FixedWidthTypes.Date64, want: true},
+		{dt: types.ExtensionTypes.UUID, want: true},
+		{dt: types.ExtensionTypes.JSON, want: false},
+		{dt: &arrow.Decimal256Type{Precision: 38, Scale: 10}, want: true},
+		{dt: &arrow.Decimal256Type{Precision: 76, Scale: 10}, want: false},
+		{dt: arrow.ListOf(arrow.ListOf(arrow.PrimitiveTypes.Int32)), want: true},
+		{dt: arrow.ListOf(types.ExtensionTypes.UUID), want: false},
+		{dt: arrow.StructOf(arrow.Field{Name: "foo", Type: arrow.PrimitiveTypes.Uint64}), want: false},
+		{dt: arrow.LargeListOf(arrow.PrimitiveTypes.Int32), want: false},
+	} {
+		require.Equal(t, tc.want, stagedAsIs(tc.dt), tc.dt.String())
+	}
+}
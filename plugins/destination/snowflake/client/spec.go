@@ -8,6 +8,7 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 )
@@ -18,6 +19,13 @@ const (
 	defaultMigrateConcurrency = 1
 )
 
+type StageFileFormat string
+
+const (
+	StageFileFormatJSON    StageFileFormat = "json"
+	StageFileFormatParquet StageFileFormat = "parquet"
+)
+
 type Spec struct {
 	// Snowflake `connection_string`.
 	ConnectionString string `json:"connection_string" jsonschema:"required,minLength=1"`
@@ -38,8 +46,35 @@ type Spec struct {
 	// This can be useful if you have a lot of tables to migrate and want to speed up the process.
 	MigrateConcurrency int `json:"migrate_concurrency,omitempty" jsonschema:"minimum=1,default=1"`
 
-	// If set to true, intermediary files used to load data to the Snowflake stage are left in the temp directory. This can be useful for debugging purposes.
+	// If set to true, intermediary files used to load data to the Snowflake stage are left in the temp directory and the stage. This can be useful for debugging purposes.
 	LeaveStageFiles bool `json:"leave_stage_files,omitempty" jsonschema:"default=false"`
+
+	// Format of the files the batches are staged as before being loaded:
+	//
+	// - `json` writes the rows as newline-delimited JSON.
+	//
+	// - `parquet` writes the Arrow records as Parquet files, keeping the types of the values.
+	//   Values of the types without a Parquet counterpart supported by Snowflake (such as unsigned 64-bit integers) are staged as strings.
+	StageFileFormat StageFileFormat `json:"stage_file_format,omitempty" jsonschema:"enum=json,enum=parquet,default=json"`
+
+	// Name of an existing [named stage](https://docs.snowflake.com/en/user-guide/data-load-local-file-system-create-stage) to load the data through,
+	// for example `my_database.my_schema.my_stage`.
+	//
+	// Both internal and external (Amazon S3, Google Cloud Storage & Microsoft Azure) stages are supported.
+	// The files are uploaded to external stages with the default credentials of the cloud provider
+	// (the AWS default credential chain, Google Application Default Credentials or Azure `DefaultAzureCredential`).
+	//
+	// If not set, a stage is created for every sync and dropped at the end of it.
+	Stage string `json:"stage,omitempty"`
+
+	// The warehouse used for loading the data. Overrides the `warehouse` set in `connection_string`.
+	Warehouse string `json:"warehouse,omitempty"`
+
+	// The role used for loading the data. Overrides the `role` set in `connection_string`.
+	Role string `json:"role,omitempty"`
+
+	// The schema the tables are created in and loaded to. Overrides the schema set in `connection_string`.
+	Schema string `json:"schema,omitempty"`
 }
 
 //go:embed schema.json
@@ -56,6 +91,18 @@ func (s *Spec) SetDefaults() {
 	if s.MigrateConcurrency == 0 {
 		s.MigrateConcurrency = defaultMigrateConcurrency
 	}
+	if s.StageFileFormat == "" {
+		s.StageFileFormat = StageFileFormatJSON
+	}
+}
+
+func (s *Spec) Validate() error {
+	switch s.StageFileFormat {
+	case StageFileFormatJSON, StageFileFormatParquet:
+	default:
+		return fmt.Errorf("invalid stage_file_format %q, expected %q or %q", s.StageFileFormat, StageFileFormatJSON, StageFileFormatParquet)
+	}
+	return nil
 }
 
 func (s Spec) DSN() (string, error) {
@@ -76,6 +123,22 @@ func (s Spec) DSN() (string, error) {
 		}
 		cs += sep + "authenticator=snowflake_jwt&privateKey=" + base64.URLEncoding.EncodeToString(pk)
 	}
+
+	// the parameters set last take precedence, so these override the ones in the connection string
+	for _, param := range []struct{ name, value string }{
+		{"warehouse", s.Warehouse},
+		{"role", s.Role},
+		{"schema", s.Schema},
+	} {
+		if param.value == "" {
+			continue
+		}
+		sep := "?"
+		if strings.Contains(cs, "?") {
+			sep = "&"
+		}
+		cs += sep + param.name + "=" + url.QueryEscape(param.value)
+	}
 	return cs, nil
 }
 
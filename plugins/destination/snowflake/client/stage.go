@@ -0,0 +1,243 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+)
+
+const (
+	createStage      = `create stage %s`
+	dropStage        = `drop stage if exists %s`
+	describeStage    = `desc stage %s`
+	putFileIntoStage = `put 'file://%v' @%s auto_compress=%t`
+)
+
+// stageUploader uploads the local files to the stage.
+type stageUploader interface {
+	// upload uploads the local file and returns its path relative to the stage.
+	upload(ctx context.Context, localPath string) (string, error)
+}
+
+// setupStage prepares the stage the batches are loaded through: either the named stage set in `stage`,
+// or a stage created for the sync.
+func (c *Client) setupStage(ctx context.Context) error {
+	if c.spec.Stage == "" {
+		c.stageName = "cq_plugin_stage_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+		sql := fmt.Sprintf(createStage, c.stageName)
+		if _, err := c.db.ExecContext(ctx, sql); err != nil {
+			return fmt.Errorf("failed to create stage %s: %w", sql, err)
+		}
+		c.stageCreated = true
+		c.stageUploader = &internalStageUploader{db: c.db, stage: c.stageName, compress: c.spec.StageFileFormat == StageFileFormatJSON}
+		return nil
+	}
+
+	c.stageName = c.spec.Stage
+	stageURL, err := c.stageURL(ctx, c.stageName)
+	if err != nil {
+		return fmt.Errorf("failed to describe stage %s: %w", c.stageName, err)
+	}
+	if stageURL == "" {
+		c.stageUploader = &internalStageUploader{db: c.db, stage: c.stageName, compress: c.spec.StageFileFormat == StageFileFormatJSON}
+		return nil
+	}
+	c.logger.Info().Str("stage", c.stageName).Str("url", stageURL).Msg("Using external stage")
+	c.stageUploader, err = newExternalStageUploader(ctx, stageURL)
+	return err
+}
+
+// stageURL returns the URL of the external stage, or an empty string for internal stages.
+func (c *Client) stageURL(ctx context.Context, stage string) (string, error) {
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(describeStage, stage))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var parentProperty, property, propertyType, propertyValue, propertyDefault sql.NullString
+		if err := rows.Scan(&parentProperty, &property, &propertyType, &propertyValue, &propertyDefault); err != nil {
+			return "", err
+		}
+		if parentProperty.String == "STAGE_LOCATION" && property.String == "URL" {
+			return parseStageURL(propertyValue.String)
+		}
+	}
+	return "", rows.Err()
+}
+
+// parseStageURL parses the URL property of the stage description, which is a JSON array of URLs (e.g. `["s3://bucket/path/"]`).
+func parseStageURL(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	var urls []string
+	if err := json.Unmarshal([]byte(value), &urls); err != nil {
+		return "", fmt.Errorf("failed to parse stage URL %q: %w", value, err)
+	}
+	if len(urls) == 0 {
+		return "", nil
+	}
+	return urls[0], nil
+}
+
+// dropStage drops the stage created for the sync, unless `leave_stage_files` is set.
+func (c *Client) dropStage(ctx context.Context) error {
+	if !c.stageCreated || c.spec.LeaveStageFiles {
+		return nil
+	}
+	sql := fmt.Sprintf(dropStage, c.stageName)
+	if _, err := c.db.ExecContext(ctx, sql); err != nil {
+		return fmt.Errorf("failed to drop stage %s: %w", sql, err)
+	}
+	return nil
+}
+
+// internalStageUploader uploads the files to internal stages with PUT.
+type internalStageUploader struct {
+	db    *sql.DB
+	stage string
+	// compress is only used for JSON files, as Parquet files are already compressed
+	compress bool
+}
+
+func (u *internalStageUploader) upload(ctx context.Context, localPath string) (string, error) {
+	sql := fmt.Sprintf(putFileIntoStage, escapePath(localPath), u.stage, u.compress)
+	if _, err := u.db.ExecContext(ctx, sql); err != nil {
+		return "", fmt.Errorf("failed to put file into stage with %s: %w", sql, err)
+	}
+	name := filepath.Base(localPath)
+	if u.compress {
+		name += ".gz"
+	}
+	return name, nil
+}
+
+// newExternalStageUploader returns the uploader for the URL of the external stage:
+// `s3://bucket/path/`, `gcs://bucket/path/` or `azure://account.blob.core.windows.net/container/path/`.
+func newExternalStageUploader(ctx context.Context, stageURL string) (stageUploader, error) {
+	u, err := url.Parse(stageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stage URL %q: %w", stageURL, err)
+	}
+	prefix := strings.TrimPrefix(u.Path, "/")
+
+	switch u.Scheme {
+	case "s3", "s3gov", "s3china":
+		cfg, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		region, err := manager.GetBucketRegion(ctx, s3.NewFromConfig(cfg), u.Host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get region of bucket %s: %w", u.Host, err)
+		}
+		client := s3.NewFromConfig(cfg, func(o *s3.Options) { o.Region = region })
+		return &s3StageUploader{uploader: manager.NewUploader(client), bucket: u.Host, prefix: prefix}, nil
+
+	case "gcs":
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Google Cloud Storage client: %w", err)
+		}
+		return &gcsStageUploader{client: client, bucket: u.Host, prefix: prefix}, nil
+
+	case "azure":
+		container, blobPrefix, _ := strings.Cut(prefix, "/")
+		credential, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Azure credentials: %w", err)
+		}
+		client, err := azblob.NewClient("https://"+u.Host+"/", credential, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure Blob Storage client: %w", err)
+		}
+		return &azureStageUploader{client: client, container: container, prefix: blobPrefix}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported external stage URL %q", stageURL)
+	}
+}
+
+type s3StageUploader struct {
+	uploader *manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+func (u *s3StageUploader) upload(ctx context.Context, localPath string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	name := filepath.Base(localPath)
+	key := path.Join(u.prefix, name)
+	if _, err := u.uploader.Upload(ctx, &s3.PutObjectInput{Bucket: &u.bucket, Key: &key, Body: f}); err != nil {
+		return "", fmt.Errorf("failed to upload file to s3://%s/%s: %w", u.bucket, key, err)
+	}
+	return name, nil
+}
+
+type gcsStageUploader struct {
+	client *storage.Client
+	bucket string
+	prefix string
+}
+
+func (u *gcsStageUploader) upload(ctx context.Context, localPath string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	name := filepath.Base(localPath)
+	key := path.Join(u.prefix, name)
+	w := u.client.Bucket(u.bucket).Object(key).NewWriter(ctx)
+	if _, err := io.Copy(w, f); err != nil {
+		_ = w.Close()
+		return "", fmt.Errorf("failed to upload file to gcs://%s/%s: %w", u.bucket, key, err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to upload file to gcs://%s/%s: %w", u.bucket, key, err)
+	}
+	return name, nil
+}
+
+type azureStageUploader struct {
+	client    *azblob.Client
+	container string
+	prefix    string
+}
+
+func (u *azureStageUploader) upload(ctx context.Context, localPath string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	name := filepath.Base(localPath)
+	blob := path.Join(u.prefix, name)
+	if _, err := u.client.UploadFile(ctx, u.container, blob, f, nil); err != nil {
+		return "", fmt.Errorf("failed to upload file to %s/%s: %w", u.container, blob, err)
+	}
+	return name, nil
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStageURL(t *testing.T) {
+	cases := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "[]", want: ""},
+		{value: `["s3://bucket/path/"]`, want: "s3://bucket/path/"},
+		{value: `["azure://account.blob.core.windows.net/container/path/"]`, want: "azure://account.blob.core.windows.net/container/path/"},
+	}
+	for _, tc := range cases {
+		got, err := parseStageURL(tc.value)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, got)
+	}
+
+	_, err := parseStageURL("s3://bucket/path/")
+	require.Error(t, err)
+}
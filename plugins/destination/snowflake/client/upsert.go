@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/google/uuid"
+)
+
+const (
+	createStagingTable = `create temporary table %s like %s`
+	dropStagingTable   = `drop table if exists %s`
+)
+
+// upsertStageFile loads the stage file into a temporary staging table and merges it into the table on the primary key.
+// Both the load and the merge are done in a single transaction, so the batch is either applied in full or not at all.
+func (c *Client) upsertStageFile(ctx context.Context, table *schema.Table, stageFile string) error {
+	// temporary tables are only visible in the session they were created in, so we need a dedicated connection
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	stagingTable := stagingTableName(table.Name)
+	sql := fmt.Sprintf(createStagingTable, stagingTable, table.Name)
+	if _, err := conn.ExecContext(ctx, sql); err != nil {
+		return fmt.Errorf("failed to create staging table with %s: %w", sql, err)
+	}
+	defer func() {
+		// the staging table is dropped at the end of the session anyway, but the connection is reused by the pool
+		sql := fmt.Sprintf(dropStagingTable, stagingTable)
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), sql); err != nil {
+			c.logger.Warn().Err(err).Str("table", stagingTable).Msg("Failed to drop staging table")
+		}
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		// no-op if the transaction was committed
+		_ = tx.Rollback()
+	}()
+
+	sql = c.copyIntoTableSQL(stagingTable, table, stageFile)
+	if _, err := tx.ExecContext(ctx, sql); err != nil {
+		return fmt.Errorf("failed to copy file into staging table with last resource %s: %w", sql, err)
+	}
+
+	sql = mergeQuery(table, stagingTable)
+	if _, err := tx.ExecContext(ctx, sql); err != nil {
+		return fmt.Errorf("failed to merge staging table into table %s: %w", table.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction for table %s: %w", table.Name, err)
+	}
+	return nil
+}
+
+// stagingTableName returns a unique name for the staging table of the batch.
+func stagingTableName(table string) string {
+	return table + "_cq_staging_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+func quoteColumn(name string) string {
+	return `"` + strings.ToUpper(name) + `"`
+}
+
+// mergeQuery returns the statement merging the rows of the staging table into the table on the primary key.
+// With `pk_mode: cq-id-only` the primary key consists of `_cq_id` only.
+// The staging table rows are deduplicated first, as Snowflake rejects the merges with several source rows matching the same target row.
+func mergeQuery(table *schema.Table, stagingTable string) string {
+	pks := table.PrimaryKeys()
+	isPK := make(map[string]bool, len(pks))
+	quotedPKs := make([]string, len(pks))
+	conditions := make([]string, len(pks))
+	for i, pk := range pks {
+		isPK[pk] = true
+		quotedPKs[i] = quoteColumn(pk)
+		conditions[i] = "t." + quotedPKs[i] + " = s." + quotedPKs[i]
+	}
+
+	// ORDER BY is required by ROW_NUMBER, the latest synced row wins
+	orderBy := quotedPKs[0]
+	if table.Columns.Get(schema.CqSyncTimeColumn.Name) != nil {
+		orderBy = quoteColumn(schema.CqSyncTimeColumn.Name) + " desc"
+	}
+
+	columns := make([]string, len(table.Columns))
+	values := make([]string, len(table.Columns))
+	updates := make([]string, 0, len(table.Columns))
+	for i, col := range table.Columns {
+		columns[i] = quoteColumn(col.Name)
+		values[i] = "s." + columns[i]
+		if !isPK[col.Name] {
+			updates = append(updates, "t."+columns[i]+" = s."+columns[i])
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("merge into ")
+	sb.WriteString(table.Name)
+	sb.WriteString(" as t using (select * from ")
+	sb.WriteString(stagingTable)
+	sb.WriteString(" qualify row_number() over (partition by ")
+	sb.WriteString(strings.Join(quotedPKs, ", "))
+	sb.WriteString(" order by ")
+	sb.WriteString(orderBy)
+	sb.WriteString(") = 1) as s on ")
+	sb.WriteString(strings.Join(conditions, " and "))
+	if len(updates) > 0 {
+		sb.WriteString(" when matched then update set ")
+		sb.WriteString(strings.Join(updates, ", "))
+	}
+	sb.WriteString(" when not matched then insert (")
+	sb.WriteString(strings.Join(columns, ", "))
+	sb.WriteString(") values (")
+	sb.WriteString(strings.Join(values, ", "))
+	sb.WriteString(")")
+	return sb.String()
+}
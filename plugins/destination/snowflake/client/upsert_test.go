@@ -0,0 +1,60 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeQuery(t *testing.T) {
+	cases := []struct {
+		name  string
+		table *schema.Table
+		want  string
+	}{
+		{
+			name: "primary key",
+			table: &schema.Table{
+				Name: "test_table",
+				Columns: schema.ColumnList{
+					schema.CqSyncTimeColumn,
+					{Name: "id", Type: arrow.PrimitiveTypes.Int64, PrimaryKey: true},
+					{Name: "region", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+					{Name: "name", Type: arrow.BinaryTypes.String},
+				},
+			},
+			want: `merge into test_table as t using (select * from test_table_stg qualify row_number() over (partition by "ID", "REGION" order by "_CQ_SYNC_TIME" desc) = 1) as s` +
+				` on t."ID" = s."ID" and t."REGION" = s."REGION"` +
+				` when matched then update set t."_CQ_SYNC_TIME" = s."_CQ_SYNC_TIME", t."NAME" = s."NAME"` +
+				` when not matched then insert ("_CQ_SYNC_TIME", "ID", "REGION", "NAME") values (s."_CQ_SYNC_TIME", s."ID", s."REGION", s."NAME")`,
+		},
+		{
+			name: "primary key columns only",
+			table: &schema.Table{
+				Name: "test_table",
+				Columns: schema.ColumnList{
+					{Name: "_cq_id", Type: arrow.BinaryTypes.String, PrimaryKey: true},
+				},
+			},
+			want: `merge into test_table as t using (select * from test_table_stg qualify row_number() over (partition by "_CQ_ID" order by "_CQ_ID") = 1) as s` +
+				` on t."_CQ_ID" = s."_CQ_ID"` +
+				` when not matched then insert ("_CQ_ID") values (s."_CQ_ID")`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, mergeQuery(tc.table, "test_table_stg"))
+		})
+	}
+}
+
+func TestStagingTableName(t *testing.T) {
+	name := stagingTableName("test_table")
+	require.True(t, strings.HasPrefix(name, "test_table_cq_staging_"))
+	require.Len(t, strings.TrimPrefix(name, "test_table_cq_staging_"), 32)
+	require.NotEqual(t, name, stagingTableName("test_table"))
+}
@@ -3,19 +3,19 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/apache/arrow/go/v16/arrow/array"
 	"github.com/cloudquery/plugin-sdk/v4/message"
+	"github.com/cloudquery/plugin-sdk/v4/schema"
 	"github.com/goccy/go-json"
 )
 
 const (
-	createOrReplaceFileFormat = `create or replace file format cq_plugin_json_format type = 'JSON'`
-	createOrReplaceStage      = `create or replace stage cq_plugin_stage file_format = cq_plugin_json_format;`
-	putFileIntoStage          = `put 'file://%v' @cq_plugin_stage auto_compress=true`
-	copyIntoTable             = `copy into %s from '@cq_plugin_stage' files=('%s.gz') on_error = ABORT_STATEMENT file_format = (format_name = cq_plugin_json_format) match_by_column_name = case_insensitive`
+	copyIntoTableFromJSON    = `copy into %s from @%s files=('%s') on_error = ABORT_STATEMENT file_format = (type = 'JSON') match_by_column_name = case_insensitive purge = %t`
+	copyIntoTableFromParquet = `copy into %s (%s) from (select %s from @%s) files=('%s') on_error = ABORT_STATEMENT file_format = (type = 'PARQUET' use_logical_type = true binary_as_text = false) purge = %t`
 )
 
 func (c *Client) Write(ctx context.Context, msgs <-chan message.WriteMessage) error {
@@ -34,7 +34,8 @@ func (c *Client) WriteTableBatch(ctx context.Context, name string, msgs message.
 	}
 
 	tableName := name
-	f, err := os.CreateTemp(os.TempDir(), tableName+".json.*")
+	table := msgs[0].GetTable()
+	f, err := os.CreateTemp(os.TempDir(), tableName+"."+string(c.spec.StageFileFormat)+".*")
 	if err != nil {
 		return err
 	}
@@ -48,7 +49,38 @@ func (c *Client) WriteTableBatch(ctx context.Context, name string, msgs message.
 		}
 	}()
 
-	enc := json.NewEncoder(f)
+	if c.spec.StageFileFormat == StageFileFormatParquet {
+		err = c.writeParquet(f, table, msgs)
+	} else {
+		err = writeJSON(f, msgs)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write stage file %s: %w", f.Name(), err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file with last resource %s: %w", f.Name(), err)
+	}
+
+	stageFile, err := c.stageUploader.upload(ctx, f.Name())
+	if err != nil {
+		return err
+	}
+
+	if len(table.PrimaryKeys()) > 0 {
+		// `overwrite` & `overwrite-delete-stale` write modes
+		return c.upsertStageFile(ctx, table, stageFile)
+	}
+
+	sql := c.copyIntoTableSQL(tableName, table, stageFile)
+	if _, err := c.db.ExecContext(ctx, sql); err != nil {
+		return fmt.Errorf("failed to copy file into table with last resource %s: %w", sql, err)
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, msgs message.WriteInserts) error {
+	enc := json.NewEncoder(w)
 	enc.SetEscapeHTML(false)
 
 	for _, r := range msgs {
@@ -59,34 +91,28 @@ func (c *Client) WriteTableBatch(ctx context.Context, name string, msgs message.
 			}
 		}
 	}
+	return nil
+}
 
-	if err := f.Close(); err != nil {
-		return fmt.Errorf("failed to close temp file with last resource %s: %w", f.Name(), err)
-	}
-
-	sql := fmt.Sprintf(putFileIntoStage, escapePath(f.Name()))
-
-	if _, err := c.db.ExecContext(ctx, sql); err != nil {
-		return fmt.Errorf("failed to put file into stage with last resource %s: %w", sql, err)
+// copyIntoTableSQL returns the statement loading the stage file with the rows of the table into the target table.
+// The file is removed from the stage after being loaded unless `leave_stage_files` is set.
+func (c *Client) copyIntoTableSQL(target string, table *schema.Table, stageFile string) string {
+	purge := !c.spec.LeaveStageFiles
+	if c.spec.StageFileFormat == StageFileFormatJSON {
+		return fmt.Sprintf(copyIntoTableFromJSON, target, c.stageName, stageFile, purge)
 	}
 
-	sql = fmt.Sprintf(copyIntoTable, tableName, escapePath(filepath.Base(f.Name())))
-	if _, err := c.db.ExecContext(ctx, sql); err != nil {
-		return fmt.Errorf("failed to copy file into table with last resource %s: %w", sql, err)
+	columns := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		columns[i] = quoteColumn(col.Name)
 	}
-	return err
+	return fmt.Sprintf(copyIntoTableFromParquet, target, strings.Join(columns, ", "), c.parquetSelectList(table), c.stageName, stageFile, purge)
 }
 
 func (c *Client) setupWrite(ctx context.Context) error {
 	var setupErr error
 	c.setupWriteOnce.Do(func() {
-		if _, err := c.db.ExecContext(ctx, createOrReplaceFileFormat); err != nil {
-			setupErr = fmt.Errorf("failed to create file format %s: %w", createOrReplaceFileFormat, err)
-			return
-		}
-		if _, err := c.db.ExecContext(ctx, createOrReplaceStage); err != nil {
-			setupErr = fmt.Errorf("failed to create stage %s: %w", createOrReplaceStage, err)
-		}
+		setupErr = c.setupStage(ctx)
 	})
 	return setupErr
 }
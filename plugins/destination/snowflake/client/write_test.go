@@ -0,0 +1,26 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyIntoTableSQL(t *testing.T) {
+	cl := &Client{spec: Spec{StageFileFormat: StageFileFormatParquet}, stageName: "my_stage"}
+	require.Equal(t,
+		`copy into test_table ("INT", "UINT64", "STRING", "BINARY", "TIMESTAMP", "UUID", "JSON", "LIST", "STRUCT", "DECIMAL", "DATE", "TIMESTAMPS")`+
+			` from (select $1:"int"::number, $1:"uint64"::number, $1:"string"::text, $1:"binary"::binary, $1:"timestamp"::timestamp_tz,`+
+			` regexp_replace(lower(hex_encode($1:"uuid"::binary)), '^(.{8})(.{4})(.{4})(.{4})(.{12})$', '\\1-\\2-\\3-\\4-\\5'),`+
+			` parse_json($1:"json"::text), $1:"list"::array, $1:"struct", $1:"decimal"::number(10, 2)::text, $1:"date"::date::text,`+
+			` parse_json($1:"timestamps"::text)::array from @my_stage)`+
+			` files=('test_table.parquet.123') on_error = ABORT_STATEMENT file_format = (type = 'PARQUET' use_logical_type = true binary_as_text = false) purge = true`,
+		cl.copyIntoTableSQL("test_table", parquetTestTable, "test_table.parquet.123"),
+	)
+
+	cl = &Client{spec: Spec{StageFileFormat: StageFileFormatJSON, LeaveStageFiles: true}, stageName: "my_stage"}
+	require.Equal(t,
+		`copy into test_table from @my_stage files=('test_table.json.123.gz') on_error = ABORT_STATEMENT file_format = (type = 'JSON') match_by_column_name = case_insensitive purge = false`,
+		cl.copyIntoTableSQL("test_table", parquetTestTable, "test_table.json.123.gz"),
+	)
+}